@@ -0,0 +1,145 @@
+// Package apierrors define o corpo padrão das respostas de erro da API HTTP: código estável,
+// mensagem no idioma do cliente (Accept-Language), detalhes por campo e ID da requisição.
+package apierrors
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FieldError descreve um problema em um campo da requisição (ex: {"field": "value", "code": "gt"}).
+// Code é a regra violada (regra da tag binding, "type", "syntax" ou "schema").
+type FieldError struct {
+	Field   string `json:"field,omitempty"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+
+	messageKey string // Mensagem do catálogo de campos, traduzida na resposta (quando Message está vazia)
+	param      string
+}
+
+// Body é o corpo das respostas de erro. "error" continua sendo a mensagem (agora traduzida), como
+// antes dos códigos, para não quebrar clientes existentes.
+type Body struct {
+	Message   string       `json:"error"`
+	Code      Code         `json:"code"`
+	Details   []FieldError `json:"details,omitempty"`
+	RequestID string       `json:"requestId,omitempty"`
+}
+
+// ItemError é o erro de um item de uma requisição em lote (ex: POST /expenses/batch e POST /sync),
+// com o código, a mensagem traduzida e os detalhes por campo, como em Body. Vazio nos itens sem erro.
+type ItemError struct {
+	Message string       `json:"error,omitempty"`
+	Code    Code         `json:"code,omitempty"`
+	Details []FieldError `json:"details,omitempty"`
+}
+
+// Error é um erro que pode ser exibido ao cliente. Error() retorna a mensagem em inglês (usada
+// nos logs, na API gRPC e no GraphQL); nas respostas HTTP a mensagem segue o Accept-Language.
+type Error struct {
+	Status  int
+	Code    Code
+	Args    []interface{}
+	Details []FieldError
+}
+
+// New cria um Error com os argumentos da mensagem do código.
+func New(status int, code Code, args ...interface{}) *Error {
+	return &Error{Status: status, Code: code, Args: args}
+}
+
+// Invalid cria um erro 400 de corpo inválido com os detalhes por campo do erro de bind ou validação.
+func Invalid(err error) *Error {
+	return &Error{Status: http.StatusBadRequest, Code: InvalidRequestBody, Details: FieldErrors(err)}
+}
+
+func (e *Error) Error() string {
+	message := Message(English, e.Code, e.Args...)
+	if len(e.Details) == 0 {
+		return message
+	}
+	details := make([]string, 0, len(e.Details))
+	for _, detail := range localizeDetails(English, e.Details) {
+		details = append(details, strings.TrimSpace(detail.Field+" "+detail.Message))
+	}
+	return message + ": " + strings.Join(details, "; ")
+}
+
+// Extensions expõe o código e os detalhes por campo (em inglês) no campo "extensions" dos erros
+// GraphQL, como no corpo das respostas HTTP.
+func (e *Error) Extensions() map[string]interface{} {
+	extensions := map[string]interface{}{"code": e.Code}
+	if details := localizeDetails(English, e.Details); len(details) > 0 {
+		extensions["details"] = details
+	}
+	return extensions
+}
+
+// NewBody monta o corpo de erro no idioma da requisição e envia o cabeçalho Content-Language.
+// Usado diretamente quando a resposta precisa de campos extras (ex: currentVersion no 412).
+func NewBody(c *gin.Context, err *Error) Body {
+	language := Language(c.GetHeader("Accept-Language"))
+	c.Header("Content-Language", language)
+	return Body{
+		Message:   Message(language, err.Code, err.Args...),
+		Code:      err.Code,
+		Details:   localizeDetails(language, err.Details),
+		RequestID: c.GetString("requestID"),
+	}
+}
+
+// NewItemError monta o erro de um item de lote no idioma da requisição. Sem erro, retorna um ItemError vazio.
+func NewItemError(c *gin.Context, err *Error) ItemError {
+	if err == nil {
+		return ItemError{}
+	}
+	language := Language(c.GetHeader("Accept-Language"))
+	c.Header("Content-Language", language)
+	return ItemError{
+		Message: Message(language, err.Code, err.Args...),
+		Code:    err.Code,
+		Details: localizeDetails(language, err.Details),
+	}
+}
+
+// RespondError responde com o status e o corpo de erro de err.
+func RespondError(c *gin.Context, err *Error) {
+	c.JSON(err.Status, NewBody(c, err))
+}
+
+// Respond responde com o status e o erro do código informado.
+func Respond(c *gin.Context, status int, code Code, args ...interface{}) {
+	RespondError(c, New(status, code, args...))
+}
+
+// Abort responde com o erro do código informado e interrompe os próximos handlers (para middlewares).
+func Abort(c *gin.Context, status int, code Code, args ...interface{}) {
+	Respond(c, status, code, args...)
+	c.Abort()
+}
+
+// AbortWithError responde com o erro, que deve ser um *Error (outros erros são respondidos como
+// INTERNAL_ERROR, sem o texto), e interrompe os próximos handlers.
+func AbortWithError(c *gin.Context, err error) {
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		apiErr = New(http.StatusInternalServerError, InternalError)
+	}
+	RespondError(c, apiErr)
+	c.Abort()
+}
+
+// RespondInvalid responde 400 com os detalhes por campo de um erro de bind ou validação.
+func RespondInvalid(c *gin.Context, err error) {
+	RespondError(c, Invalid(err))
+}
+
+// RespondInternal responde 500 com a mensagem genérica. O motivo deve ser registrado no log
+// por quem chama; ele nunca é enviado ao cliente.
+func RespondInternal(c *gin.Context) {
+	Respond(c, http.StatusInternalServerError, InternalError)
+}
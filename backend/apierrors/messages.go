@@ -0,0 +1,403 @@
+package apierrors
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Idiomas das mensagens de erro, escolhidos pelo cabeçalho Accept-Language.
+const (
+	English    = "en"
+	Portuguese = "pt-BR"
+)
+
+// Code é o código estável de um erro da API. Clientes devem decidir pelo código, não pela
+// mensagem, que muda conforme o idioma.
+type Code string
+
+const (
+	// Requisição
+	InvalidRequest     Code = "INVALID_REQUEST"
+	InvalidRequestBody Code = "INVALID_REQUEST_BODY"
+	InvalidParameter   Code = "INVALID_PARAMETER"
+	InvalidDate        Code = "INVALID_DATE"
+	InvalidMonth       Code = "INVALID_MONTH"
+	InvalidMonthRange  Code = "INVALID_MONTH_RANGE"
+	InvalidDateRange   Code = "INVALID_DATE_RANGE"
+	InvalidLimit       Code = "INVALID_LIMIT"
+	InvalidSort        Code = "INVALID_SORT"
+	InvalidCursor      Code = "INVALID_CURSOR"
+	TooManyItems       Code = "TOO_MANY_ITEMS"
+	RouteNotFound      Code = "ROUTE_NOT_FOUND"
+
+	// Autenticação e autorização
+	Unauthenticated       Code = "UNAUTHENTICATED"
+	InvalidAuthHeader     Code = "INVALID_AUTHORIZATION_HEADER"
+	InvalidToken          Code = "INVALID_TOKEN"
+	TokenExpired          Code = "TOKEN_EXPIRED"
+	InvalidEmail          Code = "INVALID_EMAIL"
+	InvalidAuthCodeFormat Code = "INVALID_AUTH_CODE_FORMAT"
+	AuthCodeNotFound      Code = "AUTH_CODE_NOT_FOUND"
+	AuthCodeExpired       Code = "AUTH_CODE_EXPIRED"
+	AuthCodeInvalid       Code = "AUTH_CODE_INVALID"
+	ForbiddenRole         Code = "FORBIDDEN_HOUSEHOLD_ROLE"
+
+	// Registros não encontrados
+	UserNotFound         Code = "USER_NOT_FOUND"
+	IncomeNotFound       Code = "INCOME_NOT_FOUND"
+	ExpenseNotFound      Code = "EXPENSE_NOT_FOUND"
+	ExpenseNotInTrash    Code = "EXPENSE_NOT_IN_TRASH"
+	FixedExpenseNotFound Code = "FIXED_EXPENSE_NOT_FOUND"
+	HouseholdNotFound    Code = "HOUSEHOLD_NOT_FOUND"
+	MemberNotFound       Code = "MEMBER_NOT_FOUND"
+	InvitationNotFound   Code = "INVITATION_NOT_FOUND"
+	WebhookNotFound      Code = "WEBHOOK_NOT_FOUND"
+	DeliveryNotFound     Code = "WEBHOOK_DELIVERY_NOT_FOUND"
+	GoalNotFound         Code = "GOAL_NOT_FOUND"
+	RecurringNotFound    Code = "RECURRING_TRANSACTION_NOT_FOUND"
+
+	// Regras de negócio
+	InvalidSplits           Code = "INVALID_SPLITS"
+	HouseholdChangeDenied   Code = "HOUSEHOLD_CHANGE_NOT_SUPPORTED"
+	AlreadyHouseholdMember  Code = "ALREADY_HOUSEHOLD_MEMBER"
+	LastHouseholdOwner      Code = "LAST_HOUSEHOLD_OWNER"
+	InvitationExpired       Code = "INVITATION_EXPIRED"
+	UnknownOnboardingStep   Code = "UNKNOWN_ONBOARDING_STEP"
+	OnboardingStepRequired  Code = "ONBOARDING_STEP_REQUIRED"
+	OnboardingAtFirstStep   Code = "ONBOARDING_AT_FIRST_STEP"
+	UnknownHouseholdProfile Code = "UNKNOWN_HOUSEHOLD_PROFILE"
+	VersionConflict         Code = "VERSION_CONFLICT"
+	InvalidWebhookURL       Code = "INVALID_WEBHOOK_URL"
+	InvalidThresholds       Code = "INVALID_HEALTH_THRESHOLDS"
+	SavingsTargetRequired   Code = "SAVINGS_TARGET_REQUIRED"
+	GoalDeadlinePassed      Code = "GOAL_DEADLINE_PASSED"
+	DuplicateBatchItem      Code = "DUPLICATE_BATCH_ITEM"
+	CategoryNameTaken       Code = "CATEGORY_NAME_TAKEN"
+	IncomeNotDeletable      Code = "INCOME_NOT_DELETABLE"
+	InsufficientGoalFunds   Code = "INSUFFICIENT_GOAL_FUNDS"
+	RecurringEndBeforeStart Code = "RECURRING_END_BEFORE_START"
+
+	// Sincronização offline (resultados de cada alteração em POST /sync)
+	SyncDataRequired    Code = "SYNC_DATA_REQUIRED"
+	BaseVersionRequired Code = "BASE_VERSION_REQUIRED"
+	SyncRecordNotFound  Code = "SYNC_RECORD_NOT_FOUND"
+	SyncConflict        Code = "SYNC_CONFLICT"
+
+	// Idempotência
+	IdempotencyKeyTooLong    Code = "IDEMPOTENCY_KEY_TOO_LONG"
+	IdempotencyKeyReused     Code = "IDEMPOTENCY_KEY_REUSED"
+	IdempotencyKeyInProgress Code = "IDEMPOTENCY_KEY_IN_PROGRESS"
+
+	// Erros do servidor (o motivo fica apenas no log)
+	InternalError     Code = "INTERNAL_ERROR"
+	ContractViolation Code = "CONTRACT_VIOLATION"
+)
+
+// messages são os textos de cada código por idioma, no formato do fmt.Sprintf.
+var messages = map[Code]map[string]string{
+	InvalidRequest: {
+		English:    "Invalid request",
+		Portuguese: "Requisição inválida",
+	},
+	InvalidRequestBody: {
+		English:    "Invalid request body",
+		Portuguese: "Corpo da requisição inválido",
+	},
+	InvalidParameter: {
+		English:    "Invalid '%s' parameter",
+		Portuguese: "Parâmetro '%s' inválido",
+	},
+	InvalidDate: {
+		English:    "Invalid '%s' date format. Use YYYY-MM-DD.",
+		Portuguese: "Data '%s' em formato inválido. Use AAAA-MM-DD.",
+	},
+	InvalidMonth: {
+		English:    "Invalid '%s' month format. Use YYYY-MM.",
+		Portuguese: "Mês '%s' em formato inválido. Use AAAA-MM.",
+	},
+	InvalidMonthRange: {
+		English:    "Invalid period. 'from' must not be after 'to' and the period must have at most %d months.",
+		Portuguese: "Período inválido. 'from' não pode ser depois de 'to' e o período deve ter no máximo %d meses.",
+	},
+	InvalidDateRange: {
+		English:    "Invalid period. 'from' must not be after 'to' and the period must have at most %d days.",
+		Portuguese: "Período inválido. 'from' não pode ser depois de 'to' e o período deve ter no máximo %d dias.",
+	},
+	InvalidLimit: {
+		English:    "Invalid 'limit' value. Use a number between 1 and %d.",
+		Portuguese: "Valor de 'limit' inválido. Use um número entre 1 e %d.",
+	},
+	InvalidSort: {
+		English:    "Invalid 'sort' value. Use date, -date, value or -value.",
+		Portuguese: "Valor de 'sort' inválido. Use date, -date, value ou -value.",
+	},
+	InvalidCursor: {
+		English:    "Invalid '%s' cursor",
+		Portuguese: "Cursor '%s' inválido",
+	},
+	TooManyItems: {
+		English:    "Too many items. Maximum is %d.",
+		Portuguese: "Itens demais. O máximo é %d.",
+	},
+	RouteNotFound: {
+		English:    "Route not found",
+		Portuguese: "Rota não encontrada",
+	},
+	Unauthenticated: {
+		English:    "Authentication required",
+		Portuguese: "Autenticação necessária",
+	},
+	InvalidAuthHeader: {
+		English:    "Authorization header format must be Bearer {token}",
+		Portuguese: "O cabeçalho Authorization deve estar no formato Bearer {token}",
+	},
+	InvalidToken: {
+		English:    "Invalid token",
+		Portuguese: "Token inválido",
+	},
+	TokenExpired: {
+		English:    "Token is expired or not valid yet",
+		Portuguese: "Token expirado ou ainda não válido",
+	},
+	InvalidEmail: {
+		English:    "Invalid email format",
+		Portuguese: "Formato de e-mail inválido",
+	},
+	InvalidAuthCodeFormat: {
+		English:    "Invalid code format",
+		Portuguese: "Formato de código inválido",
+	},
+	AuthCodeNotFound: {
+		English:    "Invalid email or code. Code not found.",
+		Portuguese: "E-mail ou código inválido. Código não encontrado.",
+	},
+	AuthCodeExpired: {
+		English:    "Authentication code expired.",
+		Portuguese: "Código de autenticação expirado.",
+	},
+	AuthCodeInvalid: {
+		English:    "Invalid authentication code.",
+		Portuguese: "Código de autenticação inválido.",
+	},
+	ForbiddenRole: {
+		English:    "Your household role does not allow this action",
+		Portuguese: "Seu papel no domicílio não permite esta ação",
+	},
+	UserNotFound: {
+		English:    "User not found",
+		Portuguese: "Usuário não encontrado",
+	},
+	IncomeNotFound: {
+		English:    "Income data not found for user. Please complete onboarding.",
+		Portuguese: "Renda do usuário não encontrada. Conclua o onboarding.",
+	},
+	ExpenseNotFound: {
+		English:    "Expense not found",
+		Portuguese: "Despesa não encontrada",
+	},
+	ExpenseNotInTrash: {
+		English:    "Expense not found in trash",
+		Portuguese: "Despesa não encontrada na lixeira",
+	},
+	FixedExpenseNotFound: {
+		English:    "Fixed expense not found",
+		Portuguese: "Despesa fixa não encontrada",
+	},
+	HouseholdNotFound: {
+		English:    "Household not found or you are not a member",
+		Portuguese: "Domicílio não encontrado ou você não é membro dele",
+	},
+	MemberNotFound: {
+		English:    "Member not found",
+		Portuguese: "Membro não encontrado",
+	},
+	InvitationNotFound: {
+		English:    "Invitation not found",
+		Portuguese: "Convite não encontrado",
+	},
+	WebhookNotFound: {
+		English:    "Webhook not found",
+		Portuguese: "Webhook não encontrado",
+	},
+	DeliveryNotFound: {
+		English:    "Webhook delivery not found",
+		Portuguese: "Entrega de webhook não encontrada",
+	},
+	GoalNotFound: {
+		English:    "Goal not found",
+		Portuguese: "Meta não encontrada",
+	},
+	RecurringNotFound: {
+		English:    "Recurring transaction not found",
+		Portuguese: "Transação recorrente não encontrada",
+	},
+	InvalidSplits: {
+		English:    "Split values sum to %.2f but expense value is %.2f",
+		Portuguese: "As divisões somam %.2f, mas o valor da despesa é %.2f",
+	},
+	HouseholdChangeDenied: {
+		English:    "Moving an expense to another household is not supported",
+		Portuguese: "Não é possível mover uma despesa para outro domicílio",
+	},
+	AlreadyHouseholdMember: {
+		English:    "User is already a member of this household",
+		Portuguese: "O usuário já é membro deste domicílio",
+	},
+	LastHouseholdOwner: {
+		English:    "Household must keep at least one owner",
+		Portuguese: "O domicílio precisa manter ao menos um dono",
+	},
+	InvitationExpired: {
+		English:    "Invitation expired",
+		Portuguese: "Convite expirado",
+	},
+	UnknownOnboardingStep: {
+		English:    "Unknown onboarding step",
+		Portuguese: "Etapa do onboarding desconhecida",
+	},
+	OnboardingStepRequired: {
+		English:    "This onboarding step is required and cannot be skipped",
+		Portuguese: "Esta etapa do onboarding é obrigatória e não pode ser pulada",
+	},
+	OnboardingAtFirstStep: {
+		English:    "Already at the first onboarding step",
+		Portuguese: "Você já está na primeira etapa do onboarding",
+	},
+	UnknownHouseholdProfile: {
+		English:    "Unknown household profile. Use solo, casal, familia or compartilhado.",
+		Portuguese: "Perfil de domicílio desconhecido. Use solo, casal, familia ou compartilhado.",
+	},
+	VersionConflict: {
+		English:    "The resource was modified by another request. Fetch the latest version and try again.",
+		Portuguese: "O recurso foi alterado por outra requisição. Busque a versão mais recente e tente novamente.",
+	},
+	InvalidWebhookURL: {
+		English:    "Invalid webhook URL. Use an absolute http or https URL.",
+		Portuguese: "URL de webhook inválida. Use uma URL absoluta http ou https.",
+	},
+	InvalidThresholds: {
+		English:    "The yellow threshold must be lower than the green threshold.",
+		Portuguese: "O limite do amarelo deve ser menor que o limite do verde.",
+	},
+	SavingsTargetRequired: {
+		English:    "Health measured against a savings target requires a savings target greater than zero.",
+		Portuguese: "A saúde medida pela meta de economia exige uma meta de economia maior que zero.",
+	},
+	GoalDeadlinePassed: {
+		English:    "The goal deadline must not be in the past.",
+		Portuguese: "O prazo da meta não pode estar no passado.",
+	},
+	InsufficientGoalFunds: {
+		English:    "Cannot withdraw %.2f: the goal has %.2f saved.",
+		Portuguese: "Não é possível retirar %.2f: a meta tem %.2f guardados.",
+	},
+	RecurringEndBeforeStart: {
+		English:    "'endDate' cannot be before 'startDate'",
+		Portuguese: "'endDate' não pode ser anterior a 'startDate'",
+	},
+	DuplicateBatchItem: {
+		English:    "Duplicate of item %d",
+		Portuguese: "Duplicata do item %d",
+	},
+	CategoryNameTaken: {
+		English:    "A category with this name already exists",
+		Portuguese: "Já existe uma categoria com este nome",
+	},
+	IncomeNotDeletable: {
+		English:    "Income cannot be deleted",
+		Portuguese: "A renda não pode ser removida",
+	},
+	SyncDataRequired: {
+		English:    "Missing 'data'",
+		Portuguese: "Campo 'data' ausente",
+	},
+	BaseVersionRequired: {
+		English:    "'baseVersion' is required to change or delete a record",
+		Portuguese: "'baseVersion' é obrigatório para alterar ou remover um registro",
+	},
+	SyncRecordNotFound: {
+		English:    "Record not found or you do not have permission to change it",
+		Portuguese: "Registro não encontrado ou você não tem permissão para alterá-lo",
+	},
+	SyncConflict: {
+		English:    "The record was modified on the server. Apply the current version and retry.",
+		Portuguese: "O registro foi alterado no servidor. Aplique a versão atual e tente novamente.",
+	},
+	IdempotencyKeyTooLong: {
+		English:    "Idempotency-Key must be at most %d characters",
+		Portuguese: "Idempotency-Key deve ter no máximo %d caracteres",
+	},
+	IdempotencyKeyReused: {
+		English:    "Idempotency-Key was already used with a different request",
+		Portuguese: "Idempotency-Key já foi usada em outra requisição",
+	},
+	IdempotencyKeyInProgress: {
+		English:    "A request with this Idempotency-Key is still being processed",
+		Portuguese: "Uma requisição com esta Idempotency-Key ainda está sendo processada",
+	},
+	InternalError: {
+		English:    "An unexpected error occurred. Please try again later.",
+		Portuguese: "Ocorreu um erro inesperado. Tente novamente mais tarde.",
+	},
+	ContractViolation: {
+		English:    "Response does not match the OpenAPI specification",
+		Portuguese: "A resposta não corresponde à especificação OpenAPI",
+	},
+}
+
+// Codes retorna todos os códigos de erro conhecidos (usado na especificação OpenAPI).
+func Codes() []Code {
+	codes := make([]Code, 0, len(messages))
+	for code := range messages {
+		codes = append(codes, code)
+	}
+	return codes
+}
+
+// Message retorna a mensagem do código no idioma informado (inglês se não houver tradução).
+func Message(language string, code Code, args ...interface{}) string {
+	texts, known := messages[code]
+	if !known {
+		texts = messages[InternalError]
+		args = nil
+	}
+	text, translated := texts[language]
+	if !translated {
+		text = texts[English]
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// Language escolhe o idioma das mensagens a partir de um cabeçalho Accept-Language
+// (ex: "pt-BR,pt;q=0.9,en;q=0.8"): o idioma suportado de maior peso. Sem cabeçalho ou sem
+// idioma suportado, as mensagens ficam em inglês.
+func Language(acceptLanguage string) string {
+	language, bestWeight := English, 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		weight := 1.0
+		if value, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			weight = parsed
+		}
+		if weight <= bestWeight {
+			continue
+		}
+
+		primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		switch primary {
+		case "pt":
+			language, bestWeight = Portuguese, weight
+		case "en":
+			language, bestWeight = English, weight
+		}
+	}
+	return language
+}
@@ -0,0 +1,139 @@
+package apierrors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// fieldMessages são os textos dos problemas por campo, por regra e idioma. As regras de tamanho
+// (min, max, len) têm textos próprios para textos e listas (sufixo "_len").
+var fieldMessages = map[string]map[string]string{
+	"required":         {English: "is required", Portuguese: "é obrigatório"},
+	"gt":               {English: "must be greater than %s", Portuguese: "deve ser maior que %s"},
+	"gte":              {English: "must be greater than or equal to %s", Portuguese: "deve ser maior ou igual a %s"},
+	"lt":               {English: "must be less than %s", Portuguese: "deve ser menor que %s"},
+	"lte":              {English: "must be less than or equal to %s", Portuguese: "deve ser menor ou igual a %s"},
+	"min":              {English: "must be at least %s", Portuguese: "deve ser no mínimo %s"},
+	"max":              {English: "must be at most %s", Portuguese: "deve ser no máximo %s"},
+	"min_len":          {English: "must have at least %s items or characters", Portuguese: "deve ter ao menos %s itens ou caracteres"},
+	"max_len":          {English: "must have at most %s items or characters", Portuguese: "deve ter no máximo %s itens ou caracteres"},
+	"len":              {English: "must be %s", Portuguese: "deve ser %s"},
+	"len_len":          {English: "must have exactly %s items or characters", Portuguese: "deve ter exatamente %s itens ou caracteres"},
+	"required_without": {English: "is required when %s is not informed", Portuguese: "é obrigatório quando %s não é informado"},
+	"oneof":            {English: "must be one of: %s", Portuguese: "deve ser um destes valores: %s"},
+	"email":            {English: "must be a valid email", Portuguese: "deve ser um e-mail válido"},
+	"type":             {English: "must be of type %s", Portuguese: "deve ser do tipo %s"},
+	"syntax":           {English: "Malformed or empty JSON", Portuguese: "JSON malformado ou vazio"},
+	"unknown":          {English: "is invalid", Portuguese: "é inválido"},
+}
+
+// jsonTypeNames traduz os tipos Go nos nomes de tipos JSON usados nas mensagens.
+var jsonTypeNames = map[reflect.Kind]string{
+	reflect.Bool: "boolean", reflect.String: "string", reflect.Slice: "array", reflect.Array: "array",
+	reflect.Map: "object", reflect.Struct: "object",
+	reflect.Int: "integer", reflect.Int8: "integer", reflect.Int16: "integer", reflect.Int32: "integer", reflect.Int64: "integer",
+	reflect.Uint: "integer", reflect.Uint8: "integer", reflect.Uint16: "integer", reflect.Uint32: "integer", reflect.Uint64: "integer",
+	reflect.Float32: "number", reflect.Float64: "number",
+}
+
+// UseJSONFieldNames faz o validador do Gin usar os nomes JSON dos campos (tag json) nos erros,
+// para que os detalhes apontem os campos como o cliente os envia.
+func UseJSONFieldNames() {
+	validate, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+}
+
+// FieldErrors converte um erro de bind ou validação nos problemas por campo. Erros de outro tipo
+// não geram detalhes, pois seu texto pode ser interno.
+func FieldErrors(err error) []FieldError {
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &validationErrs):
+		details := make([]FieldError, 0, len(validationErrs))
+		for _, fieldErr := range validationErrs {
+			details = append(details, validationFieldError(fieldErr))
+		}
+		return details
+	case errors.As(err, &typeErr):
+		typeName := jsonTypeNames[typeErr.Type.Kind()]
+		if typeName == "" {
+			typeName = typeErr.Type.String()
+		}
+		return []FieldError{{Field: typeErr.Field, Code: "type", messageKey: "type", param: typeName}}
+	case errors.As(err, &syntaxErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return []FieldError{{Code: "syntax", messageKey: "syntax"}}
+	}
+	return nil
+}
+
+// validationFieldError converte uma regra violada da tag binding. O caminho do campo não inclui
+// a struct raiz (ex: "splits[0].value").
+func validationFieldError(fieldErr validator.FieldError) FieldError {
+	field := fieldErr.Namespace()
+	if _, path, found := strings.Cut(field, "."); found {
+		field = path
+	}
+	key := fieldErr.Tag()
+	switch fieldErr.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		if key == "min" || key == "max" || key == "len" {
+			key += "_len"
+		}
+	}
+	if _, known := fieldMessages[key]; !known {
+		key = "unknown"
+	}
+	param := fieldErr.Param()
+	if key == "required_without" && param != "" {
+		// O parâmetro é o nome Go do outro campo; os nomes JSON do projeto são o mesmo nome em camelCase
+		param = strings.ToLower(param[:1]) + param[1:]
+	}
+	return FieldError{Field: field, Code: fieldErr.Tag(), messageKey: key, param: param}
+}
+
+// localizeDetails preenche as mensagens dos detalhes no idioma informado.
+func localizeDetails(language string, details []FieldError) []FieldError {
+	if len(details) == 0 {
+		return nil
+	}
+	localized := make([]FieldError, len(details))
+	for i, detail := range details {
+		if detail.Message == "" {
+			texts := fieldMessages[detail.messageKey]
+			if texts == nil {
+				texts = fieldMessages["unknown"]
+			}
+			text, translated := texts[language]
+			if !translated {
+				text = texts[English]
+			}
+			if strings.Contains(text, "%s") {
+				text = fmt.Sprintf(text, detail.param)
+			}
+			detail.Message = text
+		}
+		localized[i] = detail
+	}
+	return localized
+}
@@ -0,0 +1,85 @@
+package database
+
+import (
+	"fmt"
+	"personal-finance-app/backend/models"
+
+	"gorm.io/gorm"
+)
+
+// syncedTables mapeia as tabelas sincronizadas com os clientes para o nome da entidade no feed.
+var syncedTables = map[string]string{
+	"incomes":           models.SyncEntityIncome,
+	"fixed_expenses":    models.SyncEntityFixedExpense,
+	"variable_expenses": models.SyncEntityVariableExpense,
+	"categories":        models.SyncEntityCategory,
+}
+
+// recordChangeFunction grava uma entrada em change_logs para cada linha inserida, alterada ou
+// removida. Uma linha com deleted_at preenchido (lixeira) é registrada como remoção.
+// household_id é lido via jsonb porque nem todas as tabelas possuem a coluna.
+//
+// A alteração também é publicada no canal ChangesChannel (NOTIFY, entregue apenas após o commit),
+// para que todas as instâncias do backend saibam quais saldos mudaram e quais despesas foram criadas.
+const recordChangeFunction = `
+CREATE OR REPLACE FUNCTION record_change() RETURNS trigger AS $$
+DECLARE
+	rec RECORD;
+	op TEXT := 'upsert';
+BEGIN
+	IF TG_OP = 'DELETE' THEN
+		rec := OLD;
+		op := 'delete';
+	ELSE
+		rec := NEW;
+		IF to_jsonb(rec)->>'deleted_at' IS NOT NULL THEN
+			op := 'delete';
+		END IF;
+	END IF;
+
+	INSERT INTO change_logs (tx_id, user_id, household_id, entity, entity_id, operation, created_at)
+	VALUES (txid_current(), rec.user_id, (to_jsonb(rec)->>'household_id')::bigint, TG_ARGV[0], rec.id, op, now());
+	PERFORM pg_notify('` + ChangesChannel + `', json_build_object(
+		'entity', TG_ARGV[0],
+		'entityId', rec.id,
+		'created', TG_OP = 'INSERT',
+		'userId', rec.user_id,
+		'householdId', (to_jsonb(rec)->>'household_id')::bigint
+	)::text);
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql`
+
+// setupChangeTracking instala os triggers que alimentam o feed de alterações (models.ChangeLog).
+// Na primeira execução, registra os dados já existentes como "upsert" para que a primeira
+// sincronização completa dos clientes os receba.
+func setupChangeTracking() error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		var logged int64
+		if err := tx.Model(&models.ChangeLog{}).Count(&logged).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(recordChangeFunction).Error; err != nil {
+			return err
+		}
+		for table, entity := range syncedTables {
+			if logged == 0 {
+				backfill := fmt.Sprintf(`INSERT INTO change_logs (tx_id, user_id, household_id, entity, entity_id, operation, created_at)
+					SELECT txid_current(), user_id, (to_jsonb(t)->>'household_id')::bigint, '%s', id, 'upsert', now()
+					FROM %s t WHERE to_jsonb(t)->>'deleted_at' IS NULL ORDER BY id`, entity, table)
+				if err := tx.Exec(backfill).Error; err != nil {
+					return err
+				}
+			}
+			if err := tx.Exec(fmt.Sprintf("DROP TRIGGER IF EXISTS record_change ON %s", table)).Error; err != nil {
+				return err
+			}
+			trigger := fmt.Sprintf("CREATE TRIGGER record_change AFTER INSERT OR UPDATE OR DELETE ON %s FOR EACH ROW EXECUTE FUNCTION record_change('%s')", table, entity)
+			if err := tx.Exec(trigger).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -12,18 +12,21 @@ import (
 
 var DB *gorm.DB
 
-// ConnectDB inicializa a conexão com o banco de dados PostgreSQL
-func ConnectDB() {
-	var err error
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Shanghai",
+// dsn monta a string de conexão com o PostgreSQL a partir das variáveis de ambiente DB_*
+func dsn() string {
+	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Shanghai",
 		os.Getenv("DB_HOST"),
 		os.Getenv("DB_USER"),
 		os.Getenv("DB_PASSWORD"),
 		os.Getenv("DB_NAME"),
 		os.Getenv("DB_PORT"),
 	)
+}
 
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+// ConnectDB inicializa a conexão com o banco de dados PostgreSQL
+func ConnectDB() {
+	var err error
+	DB, err = gorm.Open(postgres.Open(dsn()), &gorm.Config{})
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
@@ -37,9 +40,29 @@ func ConnectDB() {
 		&models.Income{},
 		&models.FixedExpense{},
 		&models.VariableExpense{}, // Adiciona VariableExpense à migração
+		&models.ExpenseSplit{},
+		&models.Household{},
+		&models.HouseholdMember{},
+		&models.HouseholdInvitation{},
+		&models.Category{},
+		&models.Budget{},
+		&models.OnboardingState{},
+		&models.IdempotencyKey{},
+		&models.ChangeLog{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
+		&models.BalanceWatch{},
+		&models.BalanceSettings{},
+		&models.Goal{},
+		&models.GoalContribution{},
+		&models.RecurringTransaction{},
 	)
 	if err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
 	fmt.Println("Database migrated")
+
+	if err := setupChangeTracking(); err != nil {
+		log.Fatalf("Failed to set up change tracking: %v", err)
+	}
 }
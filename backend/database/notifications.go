@@ -0,0 +1,74 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+)
+
+// ChangesChannel é o canal do LISTEN/NOTIFY em que os triggers de change_logs publicam as alterações.
+const ChangesChannel = "finance_changes"
+
+// listenRetryInterval é a espera antes de reabrir a conexão de escuta após uma falha.
+const listenRetryInterval = 5 * time.Second
+
+// Change é uma alteração publicada em ChangesChannel: a entidade (models.SyncEntity*,
+// models.ChangeEntityGoal ou models.ChangeEntityRecurring) e o dono da linha. Nas alterações
+// publicadas pelos triggers de change_logs, também a linha e se ela acabou de ser inserida.
+type Change struct {
+	Entity      string `json:"entity"`
+	EntityID    uint   `json:"entityId,omitempty"`
+	Created     bool   `json:"created,omitempty"`
+	UserID      uint   `json:"userId"`
+	HouseholdID *uint  `json:"householdId"`
+}
+
+// NotifyChange publica uma alteração em ChangesChannel pela conexão de db, para entidades sem os
+// triggers de change_logs. Dentro de uma transação, a notificação só é entregue após o commit.
+func NotifyChange(db *gorm.DB, change Change) error {
+	payload, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+	return db.Exec("SELECT pg_notify(?, ?)", ChangesChannel, string(payload)).Error
+}
+
+// ListenChanges escuta ChangesChannel em uma conexão dedicada (fora do pool do GORM) e chama handle
+// para cada alteração confirmada no banco, por qualquer instância do backend. Não retorna: se a
+// conexão cair, é reaberta. Alterações feitas enquanto a conexão está fechada são perdidas.
+func ListenChanges(handle func(Change)) {
+	for {
+		if err := listenChanges(context.Background(), handle); err != nil {
+			log.Printf("Error listening to %s notifications: %v", ChangesChannel, err)
+		}
+		time.Sleep(listenRetryInterval)
+	}
+}
+
+func listenChanges(ctx context.Context, handle func(Change)) error {
+	conn, err := pgx.Connect(ctx, dsn())
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+ChangesChannel); err != nil {
+		return err
+	}
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		var change Change
+		if err := json.Unmarshal([]byte(notification.Payload), &change); err != nil {
+			log.Printf("Invalid %s notification %q: %v", ChangesChannel, notification.Payload, err)
+			continue
+		}
+		handle(change)
+	}
+}
@@ -0,0 +1,245 @@
+// Package finance reúne as regras de cálculo do saldo, da saúde financeira e da projeção do mês.
+// As funções são puras: recebem os dados já carregados do banco e um relógio (Clock), sem acessar
+// o banco nem time.Now(), para que possam ser testadas isoladamente.
+package finance
+
+import (
+	"personal-finance-app/backend/models"
+	"time"
+)
+
+// Estados da saúde financeira
+const (
+	HealthGreen  = "verde"
+	HealthYellow = "amarelo"
+	HealthRed    = "vermelho"
+)
+
+// ProjectionStartDay é o primeiro dia do mês com projeção: antes dele, há poucos dias de gastos
+// para calcular uma média confiável.
+const ProjectionStartDay = 8
+
+// Thresholds são os limites da saúde financeira, em percentual da renda que sobra após as despesas.
+type Thresholds struct {
+	Green  float64 // Acima deste percentual: verde
+	Yellow float64 // A partir deste percentual (e até Green): amarelo; abaixo: vermelho
+}
+
+// DefaultThresholds são os limites de quem não configurou os seus (ver Rules).
+var DefaultThresholds = Thresholds{Green: 60, Yellow: 25}
+
+// Clock retorna o momento atual. Em produção é time.Now; nos testes, um horário fixo.
+type Clock func() time.Time
+
+// FixedClock retorna um relógio parado no momento informado.
+func FixedClock(now time.Time) Clock {
+	return func() time.Time { return now }
+}
+
+type BalanceResponse struct {
+	CurrentBalance           float64            `json:"currentBalance"`
+	TotalIncome              float64            `json:"totalIncome"`
+	TotalFixedExpenses       float64            `json:"totalFixedExpenses"`
+	TotalVariableExpenses    float64            `json:"totalVariableExpensesMonth"`
+	TotalRecurringIncome     float64            `json:"totalRecurringIncome"`   // Ocorrências no mês das entradas recorrentes
+	TotalRecurringExpenses   float64            `json:"totalRecurringExpenses"` // Ocorrências no mês das saídas recorrentes
+	GoalContributions        float64            `json:"goalContributions"`      // Aportes em metas no mês até o momento, menos retiradas, já descontados do saldo
+	Projection               *Projection        `json:"projection,omitempty"`
+	FinancialHealthStatus    string             `json:"financialHealthStatus"` // "verde", "amarelo", "vermelho"
+	HealthPercentage         float64            `json:"healthPercentage"`
+	DaysInMonthForProjection int                `json:"daysInMonthForProjection,omitempty"` // Para debug/info
+	DayOfMonthForProjection  int                `json:"dayOfMonthForProjection,omitempty"`  // Para debug/info
+	CategoryTotals           map[string]float64 `json:"categoryTotals"`                     // Despesas variáveis do mês por categoria (considerando divisões)
+	Budgets                  []BudgetStatus     `json:"budgets"`                            // Uso dos orçamentos mensais por categoria
+	HealthRules              HealthRules        `json:"healthRules"`                        // Regras usadas em FinancialHealthStatus e nos alertas
+}
+
+// BudgetStatus compara o limite mensal de uma categoria com o gasto no mês
+type BudgetStatus struct {
+	Category    string  `json:"category"`
+	Limit       float64 `json:"limit"`
+	Spent       float64 `json:"spent"`
+	Remaining   float64 `json:"remaining"`
+	PercentUsed float64 `json:"percentUsed"`
+}
+
+type Projection struct {
+	EndOfMonthBalance         float64               `json:"endOfMonthBalance"`
+	ProjectedVariableExpenses float64               `json:"projectedVariableExpenses"`
+	ProjectedTotalExpenses    float64               `json:"projectedTotalExpenses"`
+	YellowAlertDay            string                `json:"yellowAlertDay,omitempty"` // Data "YYYY-MM-DD" ou dia do mês
+	RedAlertDay               string                `json:"redAlertDay,omitempty"`    // Data "YYYY-MM-DD" ou dia do mês
+	GMDVariableExpenses       float64               `json:"gmdVariableExpenses"`      // Gasto Médio Diário de Despesas Variáveis
+	Forecast                  *ForecastInfo         `json:"forecast,omitempty"`       // Modelo de previsão usado e seus dados
+	MonteCarlo                *MonteCarloProjection `json:"monteCarlo,omitempty"`     // Projeção probabilística, se pedida
+}
+
+// MonthRange retorna o primeiro e o último dia (à meia-noite) do mês de t, no fuso de t.
+func MonthRange(t time.Time) (time.Time, time.Time) {
+	start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	return start, start.AddDate(0, 1, -1)
+}
+
+// HealthPercentage é o saldo como percentual da base (a renda ou a meta de economia). Sem base, é 0.
+func HealthPercentage(balance, base float64) float64 {
+	if base <= 0 {
+		return 0
+	}
+	return (balance / base) * 100
+}
+
+// HealthStatus classifica o percentual da renda que sobra em verde, amarelo ou vermelho.
+func HealthStatus(healthPercentage float64, thresholds Thresholds) string {
+	switch {
+	case healthPercentage > thresholds.Green:
+		return HealthGreen
+	case healthPercentage >= thresholds.Yellow:
+		return HealthYellow
+	}
+	return HealthRed
+}
+
+// ComputeBalance calcula o saldo do mês corrente (segundo o relógio), a saúde financeira (segundo
+// as regras) e, a partir do dia ProjectionStartDay, a projeção do fim do mês pela média diária do
+// mês (MonthAverage). Entram apenas as despesas fixas ativas e as despesas variáveis do início do
+// mês até o momento atual. CategoryTotals e Budgets ficam a cargo de quem chama.
+func ComputeBalance(incomes []models.Income, fixedExpenses []models.FixedExpense, variableExpenses []models.VariableExpense, rules Rules, clock Clock) BalanceResponse {
+	return computeBalance(incomes, fixedExpenses, nil, variableExpenses, nil, SpendingHistory{}, MonthAverage{}, rules, clock())
+}
+
+// computeBalance calcula o saldo do mês de now até now, com a projeção do forecaster (se ele
+// tiver dados suficientes). Como a renda e as despesas fixas, as transações recorrentes entram
+// com todas as ocorrências do mês; os aportes em metas, como as despesas variáveis, até now.
+func computeBalance(incomes []models.Income, fixedExpenses []models.FixedExpense, recurring []models.RecurringTransaction, variableExpenses []models.VariableExpense, goalContributions []models.GoalContribution, history SpendingHistory, forecaster Forecaster, rules Rules, now time.Time) BalanceResponse {
+	income := TotalIncome(incomes)
+	startOfMonth, _ := MonthRange(now)
+	totalFixed := activeFixedTotal(fixedExpenses)
+	recurringIncome, recurringExpenses := recurringTotals(recurring, startOfMonth)
+	spent := variablesBetween(variableExpenses, startOfMonth, now)
+	totalVariable := variableTotal(variableExpenses, startOfMonth, now)
+	contributions := contributionsBetween(goalContributions, startOfMonth, now)
+
+	currentBalance := income + recurringIncome - totalFixed - recurringExpenses - totalVariable - contributions
+	healthPercentage := rules.Percentage(currentBalance, income)
+	response := BalanceResponse{
+		CurrentBalance:         currentBalance,
+		TotalIncome:            income,
+		TotalFixedExpenses:     totalFixed,
+		TotalVariableExpenses:  totalVariable,
+		TotalRecurringIncome:   recurringIncome,
+		TotalRecurringExpenses: recurringExpenses,
+		GoalContributions:      contributions,
+		FinancialHealthStatus:  rules.Status(healthPercentage),
+		HealthPercentage:       healthPercentage,
+		HealthRules:            rules.Definitions(),
+	}
+
+	forecast, ok := forecaster.Forecast(ForecastInput{Month: startOfMonth, ElapsedDays: now.Day(), Spent: spent, History: history})
+	if ok {
+		_, endOfMonth := MonthRange(now)
+		response.DaysInMonthForProjection = endOfMonth.Day()
+		response.DayOfMonthForProjection = now.Day()
+		response.Projection = projectFrom(incomes, fixedExpenses, recurring, spent, goalContributions, forecast, now.Day(), now, rules)
+	}
+	return response
+}
+
+// variablesBetween retorna as despesas variáveis com data entre from e to (inclusive).
+func variablesBetween(variableExpenses []models.VariableExpense, from, to time.Time) []models.VariableExpense {
+	between := make([]models.VariableExpense, 0, len(variableExpenses))
+	for _, expense := range variableExpenses {
+		if !expense.Date.Before(from) && !expense.Date.After(to) {
+			between = append(between, expense)
+		}
+	}
+	return between
+}
+
+// Project projeta o fim do mês repetindo, nos dias restantes, o gasto médio diário das despesas
+// variáveis até o dia atual (now). Também estima o primeiro dia em que o saldo projetado deixa de
+// ser verde e fica vermelho, segundo as regras, com a renda e as despesas no dia 1.
+func Project(income, totalFixed, totalVariable float64, now time.Time, rules Rules) *Projection {
+	gmd := 0.0
+	if totalVariable > 0 {
+		gmd = totalVariable / float64(now.Day())
+	}
+	startOfMonth, _ := MonthRange(now)
+	in := ForecastInput{Month: startOfMonth, ElapsedDays: now.Day()}
+	forecast := flatForecast(in, gmd, ForecastInfo{Model: ForecastMonthAverage, Source: "month", SampleDays: now.Day()})
+	return projectFrom(
+		[]models.Income{{MonthlyIncome: income}},
+		[]models.FixedExpense{{Value: totalFixed, Active: true}},
+		nil,
+		[]models.VariableExpense{{Value: totalVariable, Date: startOfMonth}},
+		nil, forecast, now.Day(), now, rules)
+}
+
+// projectFrom projeta o mês de month a partir do dia elapsedDays (0 para um mês que ainda não
+// começou), com as despesas variáveis registradas no mês (variableExpenses), os aportes em metas
+// e o gasto previsto em cada dia restante. O saldo projetado e os alertas vêm do fluxo de caixa
+// diário (monthCashFlow): rendas e despesas fixas entram no dia do pagamento e no vencimento; as
+// transações recorrentes, em cada ocorrência.
+func projectFrom(incomes []models.Income, fixedExpenses []models.FixedExpense, recurring []models.RecurringTransaction, variableExpenses []models.VariableExpense, goalContributions []models.GoalContribution, forecast SpendingForecast, elapsedDays int, month time.Time, rules Rules) *Projection {
+	start, _ := MonthRange(month)
+	totalFixed := activeFixedTotal(fixedExpenses)
+	_, recurringExpenses := recurringTotals(recurring, start)
+	totalVariable := variableTotal(variableExpenses, start, endOfMonth(start))
+
+	projectedVariable := totalVariable
+	for _, spending := range forecast.Daily {
+		projectedVariable += spending
+	}
+	flow := monthCashFlow(incomes, fixedExpenses, recurring, variableExpenses, goalContributions, forecast.Daily, elapsedDays, start, rules)
+	projection := &Projection{
+		EndOfMonthBalance:         flow[len(flow)-1].Balance,
+		ProjectedVariableExpenses: projectedVariable,
+		ProjectedTotalExpenses:    projectedVariable + totalFixed + recurringExpenses,
+		GMDVariableExpenses:       forecast.Rate,
+	}
+	if forecast.Info.Model != "" {
+		info := forecast.Info
+		projection.Forecast = &info
+	}
+	// Os alertas seguem as mesmas faixas de FinancialHealthStatus. Sem movimento nos dias
+	// restantes, o saldo não muda e não há alertas.
+	remaining := flow[elapsedDays:]
+	moves := false
+	for _, day := range remaining {
+		moves = moves || day.Income != 0 || day.FixedExpenses != 0 || day.VariableExpenses != 0 ||
+			day.RecurringIncome != 0 || day.RecurringExpenses != 0 || day.GoalContributions != 0
+	}
+	if !moves {
+		return projection
+	}
+	for _, day := range remaining {
+		if projection.YellowAlertDay == "" && day.FinancialHealthStatus != HealthGreen {
+			projection.YellowAlertDay = day.Date
+		}
+		if projection.RedAlertDay == "" && day.FinancialHealthStatus == HealthRed {
+			projection.RedAlertDay = day.Date
+		}
+		if projection.YellowAlertDay != "" && projection.RedAlertDay != "" {
+			break
+		}
+	}
+	return projection
+}
+
+// BudgetUsage calcula o uso de cada orçamento a partir dos totais por categoria.
+func BudgetUsage(budgets []models.Budget, totals map[string]float64) []BudgetStatus {
+	statuses := make([]BudgetStatus, 0, len(budgets))
+	for _, budget := range budgets {
+		spent := totals[budget.Category]
+		status := BudgetStatus{
+			Category:  budget.Category,
+			Limit:     budget.Amount,
+			Spent:     spent,
+			Remaining: budget.Amount - spent,
+		}
+		if budget.Amount > 0 {
+			status.PercentUsed = (spent / budget.Amount) * 100
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
@@ -0,0 +1,301 @@
+package finance
+
+import (
+	"math"
+	"personal-finance-app/backend/models"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day, hour int) time.Time {
+	return time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func variable(value float64, at time.Time) models.VariableExpense {
+	return models.VariableExpense{Value: value, Date: at}
+}
+
+// salary retorna uma renda mensal paga no dia payDay (0: sem dia informado).
+func salary(value float64, payDay int) []models.Income {
+	return []models.Income{{MonthlyIncome: value, PayDay: payDay}}
+}
+
+func fixed(value float64, active bool) models.FixedExpense {
+	return models.FixedExpense{Value: value, Active: active}
+}
+
+func TestMonthRange(t *testing.T) {
+	tests := []struct {
+		name       string
+		at         time.Time
+		start, end time.Time
+	}{
+		{"meio do mês", date(2023, time.June, 15, 13), date(2023, time.June, 1, 0), date(2023, time.June, 30, 0)},
+		{"primeiro dia", date(2023, time.March, 1, 0), date(2023, time.March, 1, 0), date(2023, time.March, 31, 0)},
+		{"último dia", date(2023, time.April, 30, 23), date(2023, time.April, 1, 0), date(2023, time.April, 30, 0)},
+		{"dezembro (virada do ano)", date(2023, time.December, 31, 23), date(2023, time.December, 1, 0), date(2023, time.December, 31, 0)},
+		{"fevereiro em ano bissexto", date(2024, time.February, 10, 0), date(2024, time.February, 1, 0), date(2024, time.February, 29, 0)},
+		{"fevereiro em ano comum", date(2023, time.February, 10, 0), date(2023, time.February, 1, 0), date(2023, time.February, 28, 0)},
+		{"fevereiro em 2000 (bissexto, divisível por 400)", date(2000, time.February, 10, 0), date(2000, time.February, 1, 0), date(2000, time.February, 29, 0)},
+		{"fevereiro em 2100 (comum, divisível por 100)", date(2100, time.February, 10, 0), date(2100, time.February, 1, 0), date(2100, time.February, 28, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := MonthRange(tt.at)
+			if !start.Equal(tt.start) || !end.Equal(tt.end) {
+				t.Errorf("MonthRange(%v) = %v, %v; want %v, %v", tt.at, start, end, tt.start, tt.end)
+			}
+		})
+	}
+}
+
+func TestMonthRangeKeepsLocation(t *testing.T) {
+	saoPaulo := time.FixedZone("BRT", -3*60*60)
+	start, end := MonthRange(time.Date(2023, time.May, 31, 22, 0, 0, 0, saoPaulo))
+	if start.Location() != saoPaulo || end.Location() != saoPaulo {
+		t.Errorf("MonthRange changed the location: %v, %v", start.Location(), end.Location())
+	}
+	if start.Month() != time.May || end.Day() != 31 {
+		t.Errorf("MonthRange = %v, %v; want May 1 and May 31 in BRT", start, end)
+	}
+}
+
+func TestHealthPercentage(t *testing.T) {
+	tests := []struct {
+		name            string
+		balance, income float64
+		want            float64
+	}{
+		{"metade da renda", 500, 1000, 50},
+		{"renda inteira", 1000, 1000, 100},
+		{"saldo negativo", -250, 1000, -25},
+		{"renda zero", 500, 0, 0},
+		{"renda e saldo zero", 0, 0, 0},
+		{"renda negativa", 500, -1000, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HealthPercentage(tt.balance, tt.income); !almostEqual(got, tt.want) {
+				t.Errorf("HealthPercentage(%v, %v) = %v; want %v", tt.balance, tt.income, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHealthStatus(t *testing.T) {
+	tests := []struct {
+		percentage float64
+		want       string
+	}{
+		{100, HealthGreen},
+		{60.01, HealthGreen},
+		{60, HealthYellow}, // "Acima de 60%": 60% ainda é amarelo
+		{42, HealthYellow},
+		{25, HealthYellow},
+		{24.99, HealthRed},
+		{0, HealthRed},
+		{-10, HealthRed},
+	}
+	for _, tt := range tests {
+		if got := HealthStatus(tt.percentage, DefaultThresholds); got != tt.want {
+			t.Errorf("HealthStatus(%v) = %q; want %q", tt.percentage, got, tt.want)
+		}
+	}
+}
+
+func TestComputeBalance(t *testing.T) {
+	tests := []struct {
+		name         string
+		now          time.Time
+		income       float64
+		fixed        []models.FixedExpense
+		variable     []models.VariableExpense
+		wantBalance  float64
+		wantVariable float64
+		wantFixed    float64
+		wantHealth   float64
+		wantStatus   string
+		wantProject  bool
+		wantDays     int
+	}{
+		{
+			name: "sem despesas", now: date(2023, time.June, 15, 12), income: 1000,
+			wantBalance: 1000, wantHealth: 100, wantStatus: HealthGreen, wantProject: true, wantDays: 30,
+		},
+		{
+			name: "despesas fixas inativas não entram", now: date(2023, time.June, 3, 12), income: 1000,
+			fixed:       []models.FixedExpense{fixed(300, true), fixed(200, false)},
+			wantBalance: 700, wantFixed: 300, wantHealth: 70, wantStatus: HealthGreen,
+		},
+		{
+			name: "dia 7 ainda sem projeção", now: date(2023, time.June, 7, 23), income: 1000,
+			fixed:       []models.FixedExpense{fixed(400, true)},
+			variable:    []models.VariableExpense{variable(100, date(2023, time.June, 2, 10))},
+			wantBalance: 500, wantFixed: 400, wantVariable: 100, wantHealth: 50, wantStatus: HealthYellow,
+		},
+		{
+			name: "dia 8 com projeção", now: date(2023, time.June, 8, 0), income: 1000,
+			fixed:       []models.FixedExpense{fixed(400, true)},
+			variable:    []models.VariableExpense{variable(100, date(2023, time.June, 2, 10))},
+			wantBalance: 500, wantFixed: 400, wantVariable: 100, wantHealth: 50, wantStatus: HealthYellow,
+			wantProject: true, wantDays: 30,
+		},
+		{
+			name: "limites do mês: fora do mês e depois de agora não entram", now: date(2023, time.March, 10, 12), income: 1000,
+			variable: []models.VariableExpense{
+				variable(50, date(2023, time.February, 28, 23)), // Mês anterior
+				variable(100, date(2023, time.March, 1, 0)),     // Início do mês (incluída)
+				variable(30, date(2023, time.March, 10, 12)),    // Exatamente agora (incluída)
+				variable(70, date(2023, time.March, 10, 13)),    // Depois de agora
+				variable(90, date(2023, time.April, 1, 0)),      // Próximo mês
+			},
+			wantBalance: 870, wantVariable: 130, wantHealth: 87, wantStatus: HealthGreen, wantProject: true, wantDays: 31,
+		},
+		{
+			name: "29 de fevereiro em ano bissexto", now: date(2024, time.February, 29, 18), income: 2000,
+			variable:    []models.VariableExpense{variable(290, date(2024, time.February, 29, 9))},
+			wantBalance: 1710, wantVariable: 290, wantHealth: 85.5, wantStatus: HealthGreen, wantProject: true, wantDays: 29,
+		},
+		{
+			name: "fevereiro em ano comum", now: date(2023, time.February, 28, 18), income: 2000,
+			variable:    []models.VariableExpense{variable(280, date(2023, time.February, 1, 9))},
+			wantBalance: 1720, wantVariable: 280, wantHealth: 86, wantStatus: HealthGreen, wantProject: true, wantDays: 28,
+		},
+		{
+			name: "virada do ano", now: date(2023, time.December, 31, 23), income: 1000,
+			variable: []models.VariableExpense{
+				variable(100, date(2023, time.December, 31, 22)),
+				variable(500, date(2022, time.December, 31, 22)), // Mesmo mês, ano anterior
+			},
+			wantBalance: 900, wantVariable: 100, wantHealth: 90, wantStatus: HealthGreen, wantProject: true, wantDays: 31,
+		},
+		{
+			name: "renda zero", now: date(2023, time.June, 5, 12), income: 0,
+			variable:    []models.VariableExpense{variable(100, date(2023, time.June, 2, 10))},
+			wantBalance: -100, wantVariable: 100, wantHealth: 0, wantStatus: HealthRed,
+		},
+		{
+			name: "saldo negativo", now: date(2023, time.June, 5, 12), income: 1000,
+			fixed:       []models.FixedExpense{fixed(900, true)},
+			variable:    []models.VariableExpense{variable(300, date(2023, time.June, 2, 10))},
+			wantBalance: -200, wantFixed: 900, wantVariable: 300, wantHealth: -20, wantStatus: HealthRed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ComputeBalance(salary(tt.income, 0), tt.fixed, tt.variable, DefaultRules, FixedClock(tt.now))
+			if !almostEqual(got.CurrentBalance, tt.wantBalance) {
+				t.Errorf("CurrentBalance = %v; want %v", got.CurrentBalance, tt.wantBalance)
+			}
+			if !almostEqual(got.TotalIncome, tt.income) || !almostEqual(got.TotalFixedExpenses, tt.wantFixed) || !almostEqual(got.TotalVariableExpenses, tt.wantVariable) {
+				t.Errorf("totals = %v/%v/%v; want %v/%v/%v", got.TotalIncome, got.TotalFixedExpenses, got.TotalVariableExpenses, tt.income, tt.wantFixed, tt.wantVariable)
+			}
+			if !almostEqual(got.HealthPercentage, tt.wantHealth) || got.FinancialHealthStatus != tt.wantStatus {
+				t.Errorf("health = %v %q; want %v %q", got.HealthPercentage, got.FinancialHealthStatus, tt.wantHealth, tt.wantStatus)
+			}
+			if (got.Projection != nil) != tt.wantProject {
+				t.Fatalf("Projection = %+v; want projection: %v", got.Projection, tt.wantProject)
+			}
+			if got.DaysInMonthForProjection != tt.wantDays {
+				t.Errorf("DaysInMonthForProjection = %d; want %d", got.DaysInMonthForProjection, tt.wantDays)
+			}
+			if tt.wantProject && got.DayOfMonthForProjection != tt.now.Day() {
+				t.Errorf("DayOfMonthForProjection = %d; want %d", got.DayOfMonthForProjection, tt.now.Day())
+			}
+		})
+	}
+}
+
+func TestProject(t *testing.T) {
+	tests := []struct {
+		name                          string
+		income, fixed, variable       float64
+		now                           time.Time
+		wantGMD, wantEndOfMonth       float64
+		wantYellowAlert, wantRedAlert string
+	}{
+		{
+			// GMD 40; saldo de 600 (60%) cai 4% ao dia: 56% no dia 11, 24% no dia 19
+			name: "alertas amarelo e vermelho", income: 1000, variable: 400, now: date(2023, time.May, 10, 12),
+			wantGMD: 40, wantEndOfMonth: 1000 - 40*31,
+			wantYellowAlert: "2023-05-11", wantRedAlert: "2023-05-19",
+		},
+		{
+			name: "sem gastos variáveis não há alertas", income: 1000, fixed: 800, now: date(2023, time.May, 10, 12),
+			wantGMD: 0, wantEndOfMonth: 200,
+		},
+		{
+			// GMD 10; saldo de 890 (89%) cai 1% ao dia e termina o mês em 68%
+			name: "gasto baixo sem alerta no mês", income: 1000, variable: 100, now: date(2023, time.May, 10, 12),
+			wantGMD: 10, wantEndOfMonth: 690,
+		},
+		{
+			// GMD 20; saldo de 400 (40%) já é amarelo: o alerta amarelo é o dia seguinte
+			name: "já amarelo", income: 1000, fixed: 400, variable: 200, now: date(2023, time.May, 10, 12),
+			wantGMD: 20, wantEndOfMonth: 1000 - 400 - 20*31,
+			wantYellowAlert: "2023-05-11", wantRedAlert: "2023-05-18",
+		},
+		{
+			name: "último dia do mês não tem dias restantes", income: 1000, variable: 3100, now: date(2023, time.May, 31, 12),
+			wantGMD: 100, wantEndOfMonth: -2100,
+		},
+		{
+			name: "bissexto: simulação até 29 de fevereiro", income: 1000, variable: 560, now: date(2024, time.February, 28, 12),
+			wantGMD: 20, wantEndOfMonth: 1000 - 20*29,
+			wantYellowAlert: "2024-02-29",
+		},
+		{
+			name: "renda zero: alertas no dia seguinte", income: 0, variable: 80, now: date(2023, time.May, 8, 12),
+			wantGMD: 10, wantEndOfMonth: -310,
+			wantYellowAlert: "2023-05-09", wantRedAlert: "2023-05-09",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Project(tt.income, tt.fixed, tt.variable, tt.now, DefaultRules)
+			if !almostEqual(got.GMDVariableExpenses, tt.wantGMD) {
+				t.Errorf("GMDVariableExpenses = %v; want %v", got.GMDVariableExpenses, tt.wantGMD)
+			}
+			if !almostEqual(got.EndOfMonthBalance, tt.wantEndOfMonth) {
+				t.Errorf("EndOfMonthBalance = %v; want %v", got.EndOfMonthBalance, tt.wantEndOfMonth)
+			}
+			if !almostEqual(got.ProjectedTotalExpenses, got.ProjectedVariableExpenses+tt.fixed) {
+				t.Errorf("ProjectedTotalExpenses = %v; want variable %v + fixed %v", got.ProjectedTotalExpenses, got.ProjectedVariableExpenses, tt.fixed)
+			}
+			if got.YellowAlertDay != tt.wantYellowAlert || got.RedAlertDay != tt.wantRedAlert {
+				t.Errorf("alerts = %q/%q; want %q/%q", got.YellowAlertDay, got.RedAlertDay, tt.wantYellowAlert, tt.wantRedAlert)
+			}
+		})
+	}
+}
+
+func TestBudgetUsage(t *testing.T) {
+	budgets := []models.Budget{
+		{Category: "mercado", Amount: 500},
+		{Category: "lazer", Amount: 200},
+		{Category: "sem limite", Amount: 0},
+	}
+	totals := map[string]float64{"mercado": 125, "lazer": 250, "sem limite": 40, "outros": 99}
+
+	want := []BudgetStatus{
+		{Category: "mercado", Limit: 500, Spent: 125, Remaining: 375, PercentUsed: 25},
+		{Category: "lazer", Limit: 200, Spent: 250, Remaining: -50, PercentUsed: 125},
+		{Category: "sem limite", Limit: 0, Spent: 40, Remaining: -40, PercentUsed: 0},
+	}
+	got := BudgetUsage(budgets, totals)
+	if len(got) != len(want) {
+		t.Fatalf("BudgetUsage returned %d statuses; want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("BudgetUsage[%d] = %+v; want %+v", i, got[i], want[i])
+		}
+	}
+	if got := BudgetUsage(nil, totals); got == nil || len(got) != 0 {
+		t.Errorf("BudgetUsage(nil) = %#v; want an empty slice", got)
+	}
+}
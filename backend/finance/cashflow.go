@@ -0,0 +1,135 @@
+package finance
+
+import (
+	"personal-finance-app/backend/models"
+	"time"
+)
+
+// CashFlowDay é o movimento e o saldo de um dia no fluxo de caixa.
+type CashFlowDay struct {
+	Date                  string  `json:"date"` // "YYYY-MM-DD"
+	Income                float64 `json:"income"`
+	FixedExpenses         float64 `json:"fixedExpenses"`
+	RecurringIncome       float64 `json:"recurringIncome"`   // Entradas recorrentes (ver models.RecurringTransaction)
+	RecurringExpenses     float64 `json:"recurringExpenses"` // Saídas recorrentes
+	VariableExpenses      float64 `json:"variableExpenses"`  // Registradas e, em dias futuros, também as previstas
+	GoalContributions     float64 `json:"goalContributions"` // Aportes em metas, menos retiradas
+	Projected             bool    `json:"projected"`         // Dia futuro: inclui o gasto previsto
+	Balance               float64 `json:"balance"`           // Saldo do mês ao fim do dia (cada mês começa em zero)
+	CumulativeBalance     float64 `json:"cumulativeBalance"` // Saldo acumulado desde o início do mês de from
+	FinancialHealthStatus string  `json:"financialHealthStatus"`
+}
+
+// TotalIncome soma as rendas mensais.
+func TotalIncome(incomes []models.Income) float64 {
+	total := 0.0
+	for _, income := range incomes {
+		total += income.MonthlyIncome
+	}
+	return total
+}
+
+// paymentDay é o dia do mês em que cai um pagamento com vencimento day: sem vencimento (0), no dia
+// 1; num dia que o mês não tem (31 em abril), no último dia.
+func paymentDay(day int, lastDay time.Time) int {
+	if day < 1 {
+		return 1
+	}
+	if day > lastDay.Day() {
+		return lastDay.Day()
+	}
+	return day
+}
+
+// monthCashFlow monta o fluxo diário do mês de month: as rendas no dia do pagamento, as despesas
+// fixas ativas no vencimento, as transações recorrentes nas suas ocorrências, as despesas variáveis
+// na sua data, os aportes em metas (menos as retiradas) no dia em que foram feitos e, nos dias
+// depois de elapsedDays, o gasto previsto (forecast[i] no dia elapsedDays+1+i). O saldo começa em
+// zero no início do mês e a saúde financeira de cada dia é medida sobre a renda do mês, segundo as
+// regras.
+func monthCashFlow(incomes []models.Income, fixedExpenses []models.FixedExpense, recurring []models.RecurringTransaction, variableExpenses []models.VariableExpense, goalContributions []models.GoalContribution, forecast []float64, elapsedDays int, month time.Time, rules Rules) []CashFlowDay {
+	start, lastDay := MonthRange(month)
+	income := TotalIncome(incomes)
+
+	days := make([]CashFlowDay, lastDay.Day())
+	for _, payment := range incomes {
+		days[paymentDay(payment.PayDay, lastDay)-1].Income += payment.MonthlyIncome
+	}
+	for _, expense := range fixedExpenses {
+		if expense.Active {
+			days[paymentDay(expense.DueDay, lastDay)-1].FixedExpenses += expense.Value
+		}
+	}
+	for _, transaction := range recurring {
+		for _, day := range recurringDays(transaction, start) {
+			if transaction.Kind == models.RecurringKindIncome {
+				days[day-1].RecurringIncome += transaction.Amount
+			} else {
+				days[day-1].RecurringExpenses += transaction.Amount
+			}
+		}
+	}
+	for _, expense := range variableExpenses {
+		if date := expense.Date.In(start.Location()); sameMonth(date, start) {
+			days[date.Day()-1].VariableExpenses += expense.Value
+		}
+	}
+	for _, contribution := range goalContributions {
+		if date := contribution.CreatedAt.In(start.Location()); sameMonth(date, start) {
+			days[date.Day()-1].GoalContributions += contribution.Amount
+		}
+	}
+	for i, spending := range forecast {
+		if d := elapsedDays + i; d < len(days) {
+			days[d].VariableExpenses += spending
+		}
+	}
+
+	balance := 0.0
+	for i := range days {
+		day := &days[i]
+		day.Date = start.AddDate(0, 0, i).Format("2006-01-02")
+		day.Projected = i >= elapsedDays
+		balance += day.Income + day.RecurringIncome - day.FixedExpenses - day.RecurringExpenses - day.VariableExpenses - day.GoalContributions
+		day.Balance = balance
+		day.CumulativeBalance = balance
+		day.FinancialHealthStatus = rules.Status(rules.Percentage(balance, income))
+	}
+	return days
+}
+
+// CashFlow projeta o saldo dia a dia de from a to (inclusive), a partir do momento atual do relógio:
+// até o dia atual, com as despesas variáveis registradas; depois dele, também com o gasto previsto
+// pelo forecaster sobre o histórico (ver ComputeMonthBalance). Rendas e despesas fixas são os valores
+// atuais e caem, todo mês, no dia do pagamento e no vencimento; as transações recorrentes, em cada
+// ocorrência; os aportes em metas, no dia em que foram feitos. O saldo de cada mês começa em zero;
+// CumulativeBalance acumula os meses desde o início do mês de from.
+func CashFlow(incomes []models.Income, fixedExpenses []models.FixedExpense, recurring []models.RecurringTransaction, variableExpenses []models.VariableExpense, goalContributions []models.GoalContribution, from, to time.Time, history SpendingHistory, forecaster Forecaster, rules Rules, clock Clock) []CashFlowDay {
+	now := clock()
+	first, _ := MonthRange(from.In(now.Location()))
+	last, _ := MonthRange(to.In(now.Location()))
+	fromDate, toDate := from.Format("2006-01-02"), to.Format("2006-01-02")
+
+	flow := []CashFlowDay{}
+	carried := 0.0
+	for month := first; !month.After(last); month = month.AddDate(0, 1, 0) {
+		_, lastDay := MonthRange(month)
+		elapsedDays, forecast := lastDay.Day(), []float64(nil)
+		if in, ok := monthInput(variableExpenses, month, history, now); ok { // Mês corrente ou futuro
+			elapsedDays = in.ElapsedDays
+			if prediction, ok := forecaster.Forecast(in); ok {
+				forecast = prediction.Daily
+			}
+		}
+
+		days := monthCashFlow(incomes, fixedExpenses, recurring, variableExpenses, goalContributions, forecast, elapsedDays, month, rules)
+		for _, day := range days {
+			if day.Date >= fromDate && day.Date <= toDate {
+				day.CumulativeBalance += carried
+				flow = append(flow, day)
+			}
+		}
+		carried += days[len(days)-1].Balance
+	}
+	return flow
+}
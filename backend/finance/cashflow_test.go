@@ -0,0 +1,126 @@
+package finance
+
+import (
+	"personal-finance-app/backend/models"
+	"testing"
+	"time"
+)
+
+func due(value float64, dueDay int) models.FixedExpense {
+	return models.FixedExpense{Value: value, DueDay: dueDay, Active: true}
+}
+
+func TestPaymentDay(t *testing.T) {
+	tests := []struct {
+		name    string
+		day     int
+		lastDay time.Time
+		want    int
+	}{
+		{"sem dia informado", 0, date(2023, time.April, 30, 0), 1},
+		{"dia do mês", 15, date(2023, time.April, 30, 0), 15},
+		{"dia 31 em abril", 31, date(2023, time.April, 30, 0), 30},
+		{"dia 30 em fevereiro", 30, date(2023, time.February, 28, 0), 28},
+		{"dia 29 em fevereiro bissexto", 29, date(2024, time.February, 29, 0), 29},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := paymentDay(tt.day, tt.lastDay); got != tt.want {
+				t.Errorf("paymentDay(%d) = %d; want %d", tt.day, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCashFlow(t *testing.T) {
+	now := date(2023, time.June, 10, 12)
+	fixedExpenses := []models.FixedExpense{
+		due(1000, 10),
+		due(100, 31), // Em junho, no dia 30
+		{Value: 500, DueDay: 1, Active: false},
+	}
+	expenses := []models.VariableExpense{
+		variable(200, date(2023, time.May, 15, 9)),
+		variable(100, date(2023, time.June, 2, 9)),
+		variable(50, date(2023, time.June, 10, 9)),
+	}
+	got := CashFlow(salary(3000, 5), fixedExpenses, nil, expenses, nil, date(2023, time.May, 30, 0), date(2023, time.July, 2, 0), SpendingHistory{}, MonthAverage{}, DefaultRules, FixedClock(now))
+
+	if len(got) != 34 {
+		t.Fatalf("CashFlow returned %d days; want 34 (30 May to 2 July)", len(got))
+	}
+	want := []struct {
+		index                   int
+		date                    string
+		income, fixed, variable float64
+		balance, cumulative     float64
+		projected               bool
+		status                  string
+	}{
+		// Maio: 3000 no dia 5, 1000 no dia 10, 200 no dia 15 e 100 no dia 31
+		{0, "2023-05-30", 0, 0, 0, 1800, 1800, false, HealthYellow}, // 60% da renda
+		{1, "2023-05-31", 0, 100, 0, 1700, 1700, false, HealthYellow},
+		// Junho começa em zero; o salário só cai no dia 5
+		{3, "2023-06-02", 0, 0, 100, -100, 1600, false, HealthRed},
+		{6, "2023-06-05", 3000, 0, 0, 2900, 4600, false, HealthGreen},
+		{11, "2023-06-10", 0, 1000, 50, 1850, 3550, false, HealthGreen},
+		// Depois de hoje, a média diária do mês: 150 / 10 dias
+		{12, "2023-06-11", 0, 0, 15, 1835, 3535, true, HealthGreen},
+		{31, "2023-06-30", 0, 100, 15, 1450, 3150, true, HealthYellow},
+		// Julho ainda sem histórico para prever os gastos: só a renda e as despesas fixas
+		{32, "2023-07-01", 0, 0, 0, 0, 3150, true, HealthRed},
+		{33, "2023-07-02", 0, 0, 0, 0, 3150, true, HealthRed},
+	}
+	for _, w := range want {
+		day := got[w.index]
+		if day.Date != w.date || !almostEqual(day.Income, w.income) || !almostEqual(day.FixedExpenses, w.fixed) || !almostEqual(day.VariableExpenses, w.variable) {
+			t.Errorf("CashFlow[%d] = %+v; want %s with income %v, fixed %v, variable %v", w.index, day, w.date, w.income, w.fixed, w.variable)
+		}
+		if !almostEqual(day.Balance, w.balance) || !almostEqual(day.CumulativeBalance, w.cumulative) || day.Projected != w.projected || day.FinancialHealthStatus != w.status {
+			t.Errorf("CashFlow[%d] = %+v; want balance %v, cumulative %v, projected %v, %s", w.index, day, w.balance, w.cumulative, w.projected, w.status)
+		}
+	}
+
+	if empty := CashFlow(salary(3000, 5), nil, nil, nil, nil, date(2023, time.July, 2, 0), date(2023, time.July, 1, 0), SpendingHistory{}, MonthAverage{}, DefaultRules, FixedClock(now)); len(empty) != 0 {
+		t.Errorf("CashFlow with to before from = %+v; want no days", empty)
+	}
+}
+
+func TestAlertsFromCashFlow(t *testing.T) {
+	// Dia 10 com 100 de despesas variáveis: média de 10 por dia até o fim do mês
+	now := date(2023, time.June, 10, 12)
+	expenses := []models.VariableExpense{variable(100, date(2023, time.June, 2, 9))}
+	tests := []struct {
+		name       string
+		incomes    []models.Income
+		fixed      []models.FixedExpense
+		wantYellow string
+		wantRed    string
+	}{
+		{
+			// 1900 no dia 10 e 1700 (85%) no fim do mês
+			name: "renda e despesas no dia 1", incomes: salary(2000, 0),
+		},
+		{
+			// Sem o salário, o saldo fica negativo até o dia 15
+			name: "salário no dia 15", incomes: salary(2000, 15),
+			wantYellow: "2023-06-11", wantRed: "2023-06-11",
+		},
+		{
+			// 1810 no dia 19; o aluguel leva o saldo a 800 (40%) no dia 20
+			name: "aluguel no dia 20", incomes: salary(2000, 0), fixed: []models.FixedExpense{due(1000, 20)},
+			wantYellow: "2023-06-20",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ComputeBalance(tt.incomes, tt.fixed, expenses, DefaultRules, FixedClock(now))
+			if got.Projection == nil {
+				t.Fatal("Projection = nil")
+			}
+			if got.Projection.YellowAlertDay != tt.wantYellow || got.Projection.RedAlertDay != tt.wantRed {
+				t.Errorf("alerts = %q/%q; want %q/%q", got.Projection.YellowAlertDay, got.Projection.RedAlertDay, tt.wantYellow, tt.wantRed)
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package finance
+
+import (
+	"personal-finance-app/backend/models"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Modelos de previsão do gasto variável, escolhidos pelo usuário
+const (
+	ForecastMonthAverage    = "month_average"       // Média diária do mês; no início do mês, a dos meses anteriores
+	ForecastWeightedAverage = "weighted_average"    // Média móvel ponderada: os dias mais recentes pesam mais
+	ForecastWeekday         = "weekday_seasonality" // Média de cada dia da semana
+)
+
+// ForecastModels são os modelos aceitos em NewForecaster.
+var ForecastModels = []string{ForecastMonthAverage, ForecastWeightedAverage, ForecastWeekday}
+
+// Parâmetros dos modelos de previsão
+const (
+	weightedWindowDays = 28 // Dias observados na média móvel ponderada
+	outlierMinSample   = 10 // Mínimo de despesas para identificar as atípicas
+	outlierFence       = 3  // Atípica: acima do 3º quartil + outlierFence * intervalo interquartil
+)
+
+// SpendingHistory são as despesas variáveis observadas antes do mês projetado: os últimos meses
+// completos, a partir do dia em que o usuário começou a registrar despesas, se for posterior.
+type SpendingHistory struct {
+	Expenses []models.VariableExpense
+	From, To time.Time // Do dia de From ao início do mês de To (exclusivo)
+}
+
+// days retorna o primeiro dia e a quantidade de dias do histórico (0 se From não for anterior ao mês de To).
+func (h SpendingHistory) days() (time.Time, int) {
+	if h.From.IsZero() || h.To.IsZero() {
+		return time.Time{}, 0
+	}
+	start := time.Date(h.From.Year(), h.From.Month(), h.From.Day(), 0, 0, 0, 0, h.From.Location())
+	end, _ := MonthRange(h.To)
+	days := int(end.Sub(start).Hours()/24 + 0.5) // Arredondado: o horário de verão altera a duração de um dia
+	if days < 0 {
+		return start, 0
+	}
+	return start, days
+}
+
+// ForecastInput são os dados disponíveis para prever o gasto dos dias restantes do mês.
+type ForecastInput struct {
+	Month       time.Time                // Primeiro dia do mês projetado
+	ElapsedDays int                      // Dias do mês já realizados (0 para um mês futuro)
+	Spent       []models.VariableExpense // Despesas variáveis realizadas no mês
+	History     SpendingHistory
+}
+
+// ForecastInfo descreve o modelo usado na projeção e os dados que ele considerou.
+type ForecastInfo struct {
+	Model           string             `json:"model"`                     // "month_average", "weighted_average" ou "weekday_seasonality"
+	Source          string             `json:"source,omitempty"`          // month_average: "month" (dias do mês) ou "history" (meses anteriores)
+	SampleDays      int                `json:"sampleDays"`                // Dias observados no cálculo
+	WindowDays      int                `json:"windowDays,omitempty"`      // weighted_average: tamanho da janela
+	WeekdayAverages map[string]float64 `json:"weekdayAverages,omitempty"` // weekday_seasonality: gasto médio por dia da semana ("monday"...)
+	TrimOutliers    bool               `json:"trimOutliers"`
+	OutlierLimit    float64            `json:"outlierLimit,omitempty"`    // Despesas acima deste valor foram desconsideradas
+	OutliersRemoved int                `json:"outliersRemoved,omitempty"` // Quantidade de despesas desconsideradas
+	OutlierAmount   float64            `json:"outlierAmount,omitempty"`   // Soma das despesas desconsideradas
+}
+
+// SpendingForecast é o gasto variável previsto para os dias restantes do mês.
+type SpendingForecast struct {
+	Daily []float64 // Gasto previsto em cada dia restante, do dia ElapsedDays+1 ao último dia do mês
+	Rate  float64   // Gasto médio diário previsto
+	Info  ForecastInfo
+}
+
+// Forecaster prevê o gasto variável dos dias restantes do mês. ok é false quando não há dados
+// suficientes para uma previsão confiável.
+type Forecaster interface {
+	Forecast(in ForecastInput) (forecast SpendingForecast, ok bool)
+}
+
+// NewForecaster retorna o modelo de previsão pelo nome (vazio: ForecastMonthAverage), opcionalmente
+// desconsiderando as despesas atípicas (compras grandes e pontuais). ok é false para um modelo desconhecido.
+func NewForecaster(model string, trimOutliers bool) (Forecaster, bool) {
+	var forecaster Forecaster
+	switch model {
+	case "", ForecastMonthAverage:
+		forecaster = MonthAverage{}
+	case ForecastWeightedAverage:
+		forecaster = WeightedAverage{}
+	case ForecastWeekday:
+		forecaster = WeekdaySeasonality{}
+	default:
+		return nil, false
+	}
+	if trimOutliers {
+		forecaster = TrimOutliers{Forecaster: forecaster}
+	}
+	return forecaster, true
+}
+
+// daySpending é o gasto variável de um dia.
+type daySpending struct {
+	Day   time.Time
+	Total float64
+}
+
+// dailySpending soma as despesas de cada um dos days dias a partir de from (meia-noite).
+func dailySpending(expenses []models.VariableExpense, from time.Time, days int) []daySpending {
+	totals := make(map[string]float64)
+	for _, expense := range expenses {
+		totals[expense.Date.In(from.Location()).Format("2006-01-02")] += expense.Value
+	}
+	series := make([]daySpending, 0, days)
+	for i := 0; i < days; i++ {
+		day := from.AddDate(0, 0, i)
+		series = append(series, daySpending{Day: day, Total: totals[day.Format("2006-01-02")]})
+	}
+	return series
+}
+
+// observedDays retorna o gasto de cada dia observado: os dias do histórico e os dias já
+// realizados do mês, em ordem.
+func (in ForecastInput) observedDays() []daySpending {
+	historyStart, historyDays := in.History.days()
+	observed := dailySpending(in.History.Expenses, historyStart, historyDays)
+	return append(observed, dailySpending(in.Spent, in.Month, in.ElapsedDays)...)
+}
+
+// remainingDays retorna os dias do mês ainda não realizados.
+func (in ForecastInput) remainingDays() []time.Time {
+	_, lastDay := MonthRange(in.Month)
+	days := []time.Time{}
+	for d := in.ElapsedDays + 1; d <= lastDay.Day(); d++ {
+		days = append(days, time.Date(lastDay.Year(), lastDay.Month(), d, 0, 0, 0, 0, lastDay.Location()))
+	}
+	return days
+}
+
+// flatForecast repete rate em todos os dias restantes.
+func flatForecast(in ForecastInput, rate float64, info ForecastInfo) SpendingForecast {
+	daily := make([]float64, len(in.remainingDays()))
+	for i := range daily {
+		daily[i] = rate
+	}
+	return SpendingForecast{Daily: daily, Rate: rate, Info: info}
+}
+
+// MonthAverage repete a média diária do mês a partir do dia ProjectionStartDay. Antes dele (e em
+// meses futuros), usa a média diária dos dias do histórico, se houver algum.
+type MonthAverage struct{}
+
+func (MonthAverage) Forecast(in ForecastInput) (SpendingForecast, bool) {
+	if in.ElapsedDays >= ProjectionStartDay {
+		spent := 0.0
+		for _, expense := range in.Spent {
+			spent += expense.Value
+		}
+		info := ForecastInfo{Model: ForecastMonthAverage, Source: "month", SampleDays: in.ElapsedDays}
+		return flatForecast(in, spent/float64(in.ElapsedDays), info), true
+	}
+
+	historyStart, historyDays := in.History.days()
+	if historyDays == 0 {
+		return SpendingForecast{}, false
+	}
+	spent := 0.0
+	for _, day := range dailySpending(in.History.Expenses, historyStart, historyDays) {
+		spent += day.Total
+	}
+	info := ForecastInfo{Model: ForecastMonthAverage, Source: "history", SampleDays: historyDays}
+	return flatForecast(in, spent/float64(historyDays), info), true
+}
+
+// WeightedAverage repete a média ponderada dos últimos weightedWindowDays dias observados: o dia
+// mais antigo tem peso 1 e cada dia seguinte, um a mais.
+type WeightedAverage struct{}
+
+func (WeightedAverage) Forecast(in ForecastInput) (SpendingForecast, bool) {
+	observed := in.observedDays()
+	if len(observed) < ProjectionStartDay {
+		return SpendingForecast{}, false
+	}
+	if len(observed) > weightedWindowDays {
+		observed = observed[len(observed)-weightedWindowDays:]
+	}
+
+	weighted, weights := 0.0, 0.0
+	for i, day := range observed {
+		weight := float64(i + 1)
+		weighted += weight * day.Total
+		weights += weight
+	}
+	info := ForecastInfo{Model: ForecastWeightedAverage, SampleDays: len(observed), WindowDays: weightedWindowDays}
+	return flatForecast(in, weighted/weights, info), true
+}
+
+// WeekdaySeasonality prevê cada dia restante com o gasto médio do seu dia da semana nos dias observados.
+type WeekdaySeasonality struct{}
+
+func (WeekdaySeasonality) Forecast(in ForecastInput) (SpendingForecast, bool) {
+	observed := in.observedDays()
+	if len(observed) < ProjectionStartDay { // Ao menos uma semana inteira
+		return SpendingForecast{}, false
+	}
+
+	var totals [7]float64
+	var counts [7]int
+	overall := 0.0
+	for _, day := range observed {
+		totals[day.Day.Weekday()] += day.Total
+		counts[day.Day.Weekday()]++
+		overall += day.Total
+	}
+	overall /= float64(len(observed))
+
+	info := ForecastInfo{Model: ForecastWeekday, SampleDays: len(observed), WeekdayAverages: make(map[string]float64, 7)}
+	var averages [7]float64
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		averages[weekday] = overall
+		if counts[weekday] > 0 {
+			averages[weekday] = totals[weekday] / float64(counts[weekday])
+		}
+		info.WeekdayAverages[strings.ToLower(weekday.String())] = averages[weekday]
+	}
+
+	forecast := SpendingForecast{Rate: overall, Info: info}
+	sum := 0.0
+	for _, day := range in.remainingDays() {
+		forecast.Daily = append(forecast.Daily, averages[day.Weekday()])
+		sum += averages[day.Weekday()]
+	}
+	if len(forecast.Daily) > 0 {
+		forecast.Rate = sum / float64(len(forecast.Daily))
+	}
+	return forecast, true
+}
+
+// TrimOutliers desconsidera as despesas atípicas (acima do 3º quartil + outlierFence vezes o
+// intervalo interquartil das despesas observadas) antes de aplicar o modelo. As despesas atípicas
+// continuam no saldo realizado; apenas não se repetem na projeção.
+type TrimOutliers struct {
+	Forecaster Forecaster
+}
+
+func (t TrimOutliers) Forecast(in ForecastInput) (SpendingForecast, bool) {
+	values := make([]float64, 0, len(in.History.Expenses)+len(in.Spent))
+	for _, expense := range in.History.Expenses {
+		values = append(values, expense.Value)
+	}
+	for _, expense := range in.Spent {
+		values = append(values, expense.Value)
+	}
+
+	limit, removed, amount := 0.0, 0, 0.0
+	if len(values) >= outlierMinSample {
+		sort.Float64s(values)
+		q1, q3 := Percentile(values, 25), Percentile(values, 75)
+		limit = q3 + outlierFence*(q3-q1)
+
+		keep := func(expenses []models.VariableExpense) []models.VariableExpense {
+			kept := make([]models.VariableExpense, 0, len(expenses))
+			for _, expense := range expenses {
+				if expense.Value > limit {
+					removed++
+					amount += expense.Value
+					continue
+				}
+				kept = append(kept, expense)
+			}
+			return kept
+		}
+		in.Spent = keep(in.Spent)
+		in.History.Expenses = keep(in.History.Expenses)
+	}
+
+	forecast, ok := t.Forecaster.Forecast(in)
+	forecast.Info.TrimOutliers = true
+	forecast.Info.OutlierLimit = limit
+	forecast.Info.OutliersRemoved = removed
+	forecast.Info.OutlierAmount = amount
+	return forecast, ok
+}
+
+// Percentile retorna o percentil p (0 a 100) de valores já ordenados, com interpolação linear.
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(rank)
+	if lower >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[lower] + (rank-float64(lower))*(sorted[lower+1]-sorted[lower])
+}
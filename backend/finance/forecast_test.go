@@ -0,0 +1,214 @@
+package finance
+
+import (
+	"personal-finance-app/backend/models"
+	"testing"
+	"time"
+)
+
+// repeat retorna count despesas de value, uma por dia a partir de from.
+func repeat(value float64, from time.Time, count int) []models.VariableExpense {
+	expenses := make([]models.VariableExpense, 0, count)
+	for i := 0; i < count; i++ {
+		expenses = append(expenses, variable(value, from.AddDate(0, 0, i)))
+	}
+	return expenses
+}
+
+func TestNewForecaster(t *testing.T) {
+	for _, model := range append([]string{""}, ForecastModels...) {
+		if _, ok := NewForecaster(model, false); !ok {
+			t.Errorf("NewForecaster(%q) is not ok", model)
+		}
+	}
+	if _, ok := NewForecaster("linear_regression", false); ok {
+		t.Error("NewForecaster accepted an unknown model")
+	}
+	if forecaster, _ := NewForecaster(ForecastWeekday, true); forecaster != (TrimOutliers{Forecaster: WeekdaySeasonality{}}) {
+		t.Errorf("NewForecaster with trimOutliers = %#v; want the model wrapped in TrimOutliers", forecaster)
+	}
+}
+
+func TestMonthAverage(t *testing.T) {
+	june := date(2023, time.June, 1, 0)
+	may := SpendingHistory{Expenses: []models.VariableExpense{variable(310, date(2023, time.May, 20, 9))}, From: date(2023, time.May, 1, 0), To: june}
+	tests := []struct {
+		name       string
+		in         ForecastInput
+		wantOK     bool
+		wantRate   float64
+		wantSource string
+		wantDays   int
+	}{
+		{
+			name: "média do mês a partir do dia 8", in: ForecastInput{Month: june, ElapsedDays: 10, Spent: repeat(20, june, 10), History: may},
+			wantOK: true, wantRate: 20, wantSource: "month", wantDays: 20,
+		},
+		{
+			name: "início do mês usa os meses anteriores", in: ForecastInput{Month: june, ElapsedDays: 3, Spent: repeat(50, june, 3), History: may},
+			wantOK: true, wantRate: 10, wantSource: "history", wantDays: 27,
+		},
+		{
+			name: "início do mês sem histórico", in: ForecastInput{Month: june, ElapsedDays: 3, Spent: repeat(50, june, 3)},
+		},
+		{
+			name: "mês futuro", in: ForecastInput{Month: date(2023, time.July, 1, 0), History: may},
+			wantOK: true, wantRate: 10, wantSource: "history", wantDays: 31,
+		},
+		{
+			// Usuário que começou a registrar em 20 de maio: só os 12 dias registrados
+			name: "histórico desde a primeira despesa", in: ForecastInput{Month: june, ElapsedDays: 2, Spent: repeat(200, june, 2),
+				History: SpendingHistory{Expenses: repeat(200, date(2023, time.May, 20, 9), 12), From: date(2023, time.May, 20, 9), To: june}},
+			wantOK: true, wantRate: 200, wantSource: "history", wantDays: 28,
+		},
+		{
+			name: "histórico começando no mês projetado", in: ForecastInput{Month: june, ElapsedDays: 2, Spent: repeat(200, june, 2),
+				History: SpendingHistory{From: june, To: june}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := MonthAverage{}.Forecast(tt.in)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v; want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if !almostEqual(got.Rate, tt.wantRate) || got.Info.Source != tt.wantSource || len(got.Daily) != tt.wantDays {
+				t.Errorf("forecast = rate %v, source %q, %d days; want %v, %q, %d", got.Rate, got.Info.Source, len(got.Daily), tt.wantRate, tt.wantSource, tt.wantDays)
+			}
+			for _, spending := range got.Daily {
+				if !almostEqual(spending, tt.wantRate) {
+					t.Fatalf("Daily = %v; want %v every day", got.Daily, tt.wantRate)
+				}
+			}
+		})
+	}
+}
+
+func TestWeightedAverage(t *testing.T) {
+	june := date(2023, time.June, 1, 0)
+	tests := []struct {
+		name           string
+		in             ForecastInput
+		wantOK         bool
+		wantRate       float64
+		wantSampleDays int
+	}{
+		{
+			// Pesos 1 a 8: 36 no dia 8 (peso 8) dá 288 / 36
+			name: "dias recentes pesam mais", in: ForecastInput{Month: june, ElapsedDays: 8, Spent: []models.VariableExpense{variable(36, date(2023, time.June, 8, 9))}},
+			wantOK: true, wantRate: 8, wantSampleDays: 8,
+		},
+		{
+			// 31 dias de maio e 2 de junho: a janela começa em 6 de maio. Pesos 1 a 28 somam 406
+			name: "janela de 28 dias", in: ForecastInput{
+				Month: june, ElapsedDays: 2,
+				Spent: []models.VariableExpense{variable(406, date(2023, time.June, 2, 9))},
+				History: SpendingHistory{
+					Expenses: []models.VariableExpense{variable(1000, date(2023, time.May, 1, 9))}, // Fora da janela
+					From:     date(2023, time.May, 1, 0), To: june,
+				},
+			},
+			wantOK: true, wantRate: 28, wantSampleDays: 28,
+		},
+		{
+			name: "menos de 8 dias observados", in: ForecastInput{Month: june, ElapsedDays: 7, Spent: repeat(10, june, 7)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := WeightedAverage{}.Forecast(tt.in)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v; want %v", ok, tt.wantOK)
+			}
+			if ok && (!almostEqual(got.Rate, tt.wantRate) || got.Info.SampleDays != tt.wantSampleDays) {
+				t.Errorf("forecast = rate %v over %d days; want %v over %d", got.Rate, got.Info.SampleDays, tt.wantRate, tt.wantSampleDays)
+			}
+		})
+	}
+}
+
+func TestWeekdaySeasonality(t *testing.T) {
+	// 1º de junho de 2023 é uma quinta-feira: duas semanas observadas, com 100 aos sábados
+	june := date(2023, time.June, 1, 0)
+	in := ForecastInput{
+		Month: june, ElapsedDays: 14,
+		Spent: []models.VariableExpense{variable(100, date(2023, time.June, 3, 9)), variable(100, date(2023, time.June, 10, 9))},
+	}
+	got, ok := WeekdaySeasonality{}.Forecast(in)
+	if !ok {
+		t.Fatal("ok = false")
+	}
+	if !almostEqual(got.Info.WeekdayAverages["saturday"], 100) || !almostEqual(got.Info.WeekdayAverages["monday"], 0) || len(got.Info.WeekdayAverages) != 7 {
+		t.Errorf("WeekdayAverages = %v; want 100 on saturday, 0 on the other days", got.Info.WeekdayAverages)
+	}
+	// Restam 16 dias (15 a 30), com dois sábados (17 e 24)
+	if len(got.Daily) != 16 || !almostEqual(got.Daily[2], 100) || !almostEqual(got.Daily[9], 100) || !almostEqual(got.Daily[0], 0) {
+		t.Errorf("Daily = %v; want 100 on the 17th and the 24th", got.Daily)
+	}
+	if !almostEqual(got.Rate, 200.0/16) {
+		t.Errorf("Rate = %v; want %v", got.Rate, 200.0/16)
+	}
+
+	if _, ok := (WeekdaySeasonality{}).Forecast(ForecastInput{Month: june, ElapsedDays: 5}); ok {
+		t.Error("ok with less than a week observed")
+	}
+}
+
+func TestTrimOutliers(t *testing.T) {
+	june := date(2023, time.June, 1, 0)
+	now := date(2023, time.June, 10, 12)
+	purchase := variable(1000, date(2023, time.June, 5, 15)) // Compra grande e pontual
+	tests := []struct {
+		name        string
+		spent       []models.VariableExpense
+		wantRate    float64
+		wantRemoved int
+		wantLimit   float64
+		wantAmount  float64
+	}{
+		{"compra atípica desconsiderada", append(repeat(10, june, 10), purchase), 10, 1, 10, 1000},
+		{"poucas despesas para identificar as atípicas", append(repeat(10, june, 5), purchase), 105, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := TrimOutliers{Forecaster: MonthAverage{}}.Forecast(ForecastInput{Month: june, ElapsedDays: 10, Spent: tt.spent})
+			if !ok {
+				t.Fatal("ok = false")
+			}
+			info := got.Info
+			if !almostEqual(got.Rate, tt.wantRate) || info.OutliersRemoved != tt.wantRemoved || !almostEqual(info.OutlierLimit, tt.wantLimit) || !almostEqual(info.OutlierAmount, tt.wantAmount) {
+				t.Errorf("forecast = rate %v, info %+v; want rate %v, %d removed (%v) above %v", got.Rate, info, tt.wantRate, tt.wantRemoved, tt.wantAmount, tt.wantLimit)
+			}
+			if !info.TrimOutliers || info.Model != ForecastMonthAverage {
+				t.Errorf("Info = %+v; want the month average model with outliers trimmed", info)
+			}
+
+			// A compra continua no saldo realizado; apenas não se repete na projeção
+			balance := ComputeMonthBalance(salary(5000, 0), nil, nil, tt.spent, nil, now, SpendingHistory{}, TrimOutliers{Forecaster: MonthAverage{}}, DefaultRules, FixedClock(now))
+			spent := variableTotal(tt.spent, june, now)
+			if !almostEqual(balance.TotalVariableExpenses, spent) || !almostEqual(balance.Projection.ProjectedVariableExpenses, spent+tt.wantRate*20) {
+				t.Errorf("balance = variable %v, projected %v; want %v, %v", balance.TotalVariableExpenses, balance.Projection.ProjectedVariableExpenses, spent, spent+tt.wantRate*20)
+			}
+		})
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []float64{1, 2, 3, 4}
+	tests := []struct {
+		p, want float64
+	}{
+		{0, 1}, {25, 1.75}, {50, 2.5}, {100, 4},
+	}
+	for _, tt := range tests {
+		if got := Percentile(sorted, tt.p); !almostEqual(got, tt.want) {
+			t.Errorf("Percentile(%v) = %v; want %v", tt.p, got, tt.want)
+		}
+	}
+	if got := Percentile(nil, 50); got != 0 {
+		t.Errorf("Percentile(nil) = %v; want 0", got)
+	}
+}
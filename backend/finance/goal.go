@@ -0,0 +1,90 @@
+package finance
+
+import (
+	"math"
+	"personal-finance-app/backend/models"
+	"time"
+)
+
+// Situações de uma meta de economia
+const (
+	GoalCompleted = "completed" // O valor-alvo foi atingido
+	GoalOnTrack   = "on_track"  // O valor guardado acompanha o ritmo necessário para o prazo
+	GoalBehind    = "behind"    // Abaixo do ritmo necessário, ainda dentro do prazo
+	GoalOverdue   = "overdue"   // O prazo passou sem atingir o valor-alvo
+)
+
+// GoalProgress é o andamento de uma meta de economia em um momento.
+type GoalProgress struct {
+	Saved                       float64 `json:"saved"`                       // Aportes menos retiradas
+	Remaining                   float64 `json:"remaining"`                   // Quanto falta para o valor-alvo (0 se atingido)
+	ProgressPercentage          float64 `json:"progressPercentage"`          // Saved como percentual do valor-alvo, até 100
+	ExpectedSaved               float64 `json:"expectedSaved"`               // Quanto deveria estar guardado hoje, num ritmo constante da criação ao prazo
+	MonthsLeft                  int     `json:"monthsLeft"`                  // Meses até o prazo, contando o corrente (0 se vencido)
+	RequiredMonthlyContribution float64 `json:"requiredMonthlyContribution"` // Aporte por mês para atingir o valor no prazo
+	Status                      string  `json:"status"`                      // "completed", "on_track", "behind" ou "overdue"
+	OnTrack                     bool    `json:"onTrack"`                     // Status "completed" ou "on_track"
+}
+
+// GoalSaved soma os aportes de uma meta, descontadas as retiradas.
+func GoalSaved(contributions []models.GoalContribution) float64 {
+	saved := 0.0
+	for _, contribution := range contributions {
+		saved += contribution.Amount
+	}
+	return saved
+}
+
+// ComputeGoalProgress calcula o andamento da meta no momento atual do relógio. A meta está no ritmo
+// quando o valor guardado alcança o esperado num ritmo constante entre a criação e o prazo (o dia
+// inteiro do prazo). O aporte mensal necessário divide o que falta pelos meses até o prazo, contando
+// o corrente; com o prazo vencido, é todo o valor que falta.
+func ComputeGoalProgress(goal models.Goal, contributions []models.GoalContribution, clock Clock) GoalProgress {
+	now := clock()
+	saved := GoalSaved(contributions)
+	deadline := goal.Deadline.AddDate(0, 0, 1) // Fim do dia do prazo
+	progress := GoalProgress{
+		Saved:     saved,
+		Remaining: math.Max(goal.TargetAmount-saved, 0),
+	}
+	if goal.TargetAmount > 0 {
+		progress.ProgressPercentage = math.Min(saved/goal.TargetAmount*100, 100)
+	}
+
+	elapsed := 1.0
+	if total := deadline.Sub(goal.CreatedAt); total > 0 {
+		elapsed = math.Min(math.Max(float64(now.Sub(goal.CreatedAt))/float64(total), 0), 1)
+	}
+	progress.ExpectedSaved = goal.TargetAmount * elapsed
+
+	if now.Before(deadline) {
+		progress.MonthsLeft = MonthsBetween(now, goal.Deadline)
+	}
+	switch {
+	case progress.Remaining == 0:
+		progress.Status = GoalCompleted
+	case progress.MonthsLeft == 0:
+		progress.Status = GoalOverdue
+		progress.RequiredMonthlyContribution = progress.Remaining
+	default:
+		progress.RequiredMonthlyContribution = progress.Remaining / float64(progress.MonthsLeft)
+		progress.Status = GoalBehind
+		if saved >= progress.ExpectedSaved {
+			progress.Status = GoalOnTrack
+		}
+	}
+	progress.OnTrack = progress.Status == GoalCompleted || progress.Status == GoalOnTrack
+	return progress
+}
+
+// contributionsBetween soma os aportes, menos as retiradas, feitos entre from e to (inclusive): o
+// dinheiro guardado numa meta deixa de estar disponível no saldo.
+func contributionsBetween(contributions []models.GoalContribution, from, to time.Time) float64 {
+	total := 0.0
+	for _, contribution := range contributions {
+		if !contribution.CreatedAt.Before(from) && !contribution.CreatedAt.After(to) {
+			total += contribution.Amount
+		}
+	}
+	return total
+}
@@ -0,0 +1,75 @@
+package finance
+
+import (
+	"personal-finance-app/backend/models"
+	"testing"
+	"time"
+)
+
+func contributions(amounts ...float64) []models.GoalContribution {
+	var list []models.GoalContribution
+	for _, amount := range amounts {
+		list = append(list, models.GoalContribution{Amount: amount})
+	}
+	return list
+}
+
+func TestComputeGoalProgress(t *testing.T) {
+	// Reserva de 12000 criada em 1º de janeiro para 31 de dezembro
+	goal := models.Goal{TargetAmount: 12000, CreatedAt: date(2023, time.January, 1, 0), Deadline: date(2023, time.December, 31, 0)}
+	tests := []struct {
+		name          string
+		now           time.Time
+		contributions []models.GoalContribution
+		wantSaved     float64
+		wantPercent   float64
+		wantMonths    int
+		wantRequired  float64
+		wantStatus    string
+	}{
+		{
+			// Na metade do ano, cerca de 5970 esperados
+			name: "no ritmo", now: date(2023, time.July, 1, 12), contributions: contributions(3000, 3000),
+			wantSaved: 6000, wantPercent: 50, wantMonths: 6, wantRequired: 1000, wantStatus: GoalOnTrack,
+		},
+		{
+			name: "atrasada, com retirada", now: date(2023, time.July, 1, 12), contributions: contributions(4000, -1000),
+			wantSaved: 3000, wantPercent: 25, wantMonths: 6, wantRequired: 1500, wantStatus: GoalBehind,
+		},
+		{
+			name: "atingida", now: date(2023, time.July, 1, 12), contributions: contributions(10000, 2500),
+			wantSaved: 12500, wantPercent: 100, wantMonths: 6, wantRequired: 0, wantStatus: GoalCompleted,
+		},
+		{
+			// O dia do prazo ainda conta como um mês para aportar
+			name: "no dia do prazo", now: date(2023, time.December, 31, 20), contributions: contributions(11000),
+			wantSaved: 11000, wantPercent: 11000.0 / 120, wantMonths: 1, wantRequired: 1000, wantStatus: GoalBehind,
+		},
+		{
+			name: "vencida", now: date(2024, time.January, 1, 12), contributions: contributions(6000),
+			wantSaved: 6000, wantPercent: 50, wantMonths: 0, wantRequired: 6000, wantStatus: GoalOverdue,
+		},
+		{
+			name: "sem aportes na criação", now: date(2023, time.January, 1, 0),
+			wantSaved: 0, wantPercent: 0, wantMonths: 12, wantRequired: 1000, wantStatus: GoalOnTrack,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ComputeGoalProgress(goal, tt.contributions, FixedClock(tt.now))
+			if !almostEqual(got.Saved, tt.wantSaved) || !almostEqual(got.ProgressPercentage, tt.wantPercent) {
+				t.Errorf("saved = %v (%v%%); want %v (%v%%)", got.Saved, got.ProgressPercentage, tt.wantSaved, tt.wantPercent)
+			}
+			if got.MonthsLeft != tt.wantMonths || !almostEqual(got.RequiredMonthlyContribution, tt.wantRequired) {
+				t.Errorf("required = %v over %d months; want %v over %d", got.RequiredMonthlyContribution, got.MonthsLeft, tt.wantRequired, tt.wantMonths)
+			}
+			wantOnTrack := tt.wantStatus == GoalCompleted || tt.wantStatus == GoalOnTrack
+			if got.Status != tt.wantStatus || got.OnTrack != wantOnTrack {
+				t.Errorf("status = %s (on track %v); want %s", got.Status, got.OnTrack, tt.wantStatus)
+			}
+			if got.ExpectedSaved < 0 || got.ExpectedSaved > goal.TargetAmount {
+				t.Errorf("expected saved = %v; want between 0 and %v", got.ExpectedSaved, goal.TargetAmount)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package finance
+
+import (
+	"math/rand"
+	"personal-finance-app/backend/models"
+	"sort"
+	"time"
+)
+
+// MonteCarloSimulations é a quantidade de meses simulados na projeção probabilística.
+const MonteCarloSimulations = 2000
+
+// MonteCarloProjection é a projeção probabilística do fim do mês: a distribuição do saldo final e a
+// chance de o saldo atingir os limites da saúde financeira nos dias restantes.
+type MonteCarloProjection struct {
+	Simulations          int     `json:"simulations"`
+	SampleDays           int     `json:"sampleDays"` // Dias de gasto observados, reamostrados nas simulações
+	Seed                 int64   `json:"seed"`       // Semente do gerador: a mesma semente repete o resultado
+	P10EndOfMonthBalance float64 `json:"p10EndOfMonthBalance"`
+	P50EndOfMonthBalance float64 `json:"p50EndOfMonthBalance"`
+	P90EndOfMonthBalance float64 `json:"p90EndOfMonthBalance"`
+	YellowProbability    float64 `json:"yellowProbability"` // De 0 a 1: o saldo deixa de ser verde em algum dia restante
+	RedProbability       float64 `json:"redProbability"`    // De 0 a 1: o saldo fica vermelho em algum dia restante
+}
+
+// monthInput retorna os dados de previsão do mês de month no momento now: no mês corrente, os dias
+// até hoje já foram realizados; num mês futuro, nenhum. ok é false para meses passados.
+func monthInput(variableExpenses []models.VariableExpense, month time.Time, history SpendingHistory, now time.Time) (ForecastInput, bool) {
+	start, _ := MonthRange(month.In(now.Location()))
+	currentMonth, _ := MonthRange(now)
+	switch {
+	case start.Before(currentMonth):
+		return ForecastInput{}, false
+	case start.Equal(currentMonth):
+		return ForecastInput{Month: start, ElapsedDays: now.Day(), Spent: variablesBetween(variableExpenses, start, now), History: history}, true
+	}
+	return ForecastInput{Month: start, Spent: variablesBetween(variableExpenses, start, endOfMonth(start)), History: history}, true
+}
+
+// MonteCarlo simula simulations vezes os dias restantes do mês de month (corrente ou futuro),
+// sorteando para cada dia o gasto de um dos dias observados (o histórico e os dias já realizados
+// do mês), sobre o fluxo de caixa diário das rendas, despesas, transações recorrentes e aportes em
+// metas (ver CashFlow). Os dias do histórico começam na primeira despesa registrada (ver
+// SpendingHistory): dias sem registro não são sorteados como dias sem gasto. Com a mesma semente, o
+// resultado é o mesmo. ok é false para meses passados ou com menos de ProjectionStartDay dias
+// observados.
+func MonteCarlo(incomes []models.Income, fixedExpenses []models.FixedExpense, recurring []models.RecurringTransaction, variableExpenses []models.VariableExpense, goalContributions []models.GoalContribution, month time.Time, history SpendingHistory, rules Rules, simulations int, seed int64, clock Clock) (MonteCarloProjection, bool) {
+	in, ok := monthInput(variableExpenses, month, history, clock())
+	if !ok || simulations < 1 {
+		return MonteCarloProjection{}, false
+	}
+	observed := in.observedDays()
+	if len(observed) < ProjectionStartDay {
+		return MonteCarloProjection{}, false
+	}
+
+	income := TotalIncome(incomes)
+	flow := monthCashFlow(incomes, fixedExpenses, recurring, in.Spent, goalContributions, nil, in.ElapsedDays, in.Month, rules)
+	remaining := flow[in.ElapsedDays:]
+
+	rng := rand.New(rand.NewSource(seed))
+	endBalances := make([]float64, simulations)
+	yellow, red := 0, 0
+	for i := range endBalances {
+		spent, hitYellow, hitRed := 0.0, false, false
+		for _, day := range remaining {
+			spent += observed[rng.Intn(len(observed))].Total
+			status := rules.Status(rules.Percentage(day.Balance-spent, income))
+			hitYellow = hitYellow || status != HealthGreen
+			hitRed = hitRed || status == HealthRed
+		}
+		endBalances[i] = flow[len(flow)-1].Balance - spent
+		if hitYellow {
+			yellow++
+		}
+		if hitRed {
+			red++
+		}
+	}
+
+	sort.Float64s(endBalances)
+	return MonteCarloProjection{
+		Simulations:          simulations,
+		SampleDays:           len(observed),
+		Seed:                 seed,
+		P10EndOfMonthBalance: Percentile(endBalances, 10),
+		P50EndOfMonthBalance: Percentile(endBalances, 50),
+		P90EndOfMonthBalance: Percentile(endBalances, 90),
+		YellowProbability:    float64(yellow) / float64(simulations),
+		RedProbability:       float64(red) / float64(simulations),
+	}, true
+}
@@ -0,0 +1,140 @@
+package finance
+
+import (
+	"personal-finance-app/backend/models"
+	"testing"
+	"time"
+)
+
+func TestMonteCarloConstantSpending(t *testing.T) {
+	// Com o mesmo gasto em todos os dias observados, todas as simulações coincidem com a projeção
+	now := date(2023, time.June, 10, 12)
+	may := SpendingHistory{Expenses: repeat(10, date(2023, time.May, 1, 9), 31), From: date(2023, time.May, 1, 0), To: date(2023, time.June, 1, 0)}
+	tests := []struct {
+		name       string
+		month      time.Time
+		expenses   []models.VariableExpense
+		recurring  []models.RecurringTransaction
+		goals      []models.GoalContribution
+		history    SpendingHistory
+		wantEnd    float64
+		wantYellow float64
+		wantSample int
+	}{
+		{
+			name: "mês corrente", month: now, expenses: repeat(10, date(2023, time.June, 1, 9), 10),
+			wantEnd: 1000 - 300, wantYellow: 0, wantSample: 10,
+		},
+		{
+			// A despesa registrada de 90 e 310 reamostrados de maio: 600 (60%) no fim do mês
+			name: "mês futuro", month: date(2023, time.July, 1, 0), expenses: []models.VariableExpense{variable(90, date(2023, time.July, 5, 9))}, history: may,
+			wantEnd: 600, wantYellow: 1, wantSample: 31,
+		},
+		{
+			// A saída recorrente de 200 no dia 20 leva o saldo a 600 (60%)
+			name: "mês corrente com saída recorrente", month: now, expenses: repeat(10, date(2023, time.June, 1, 9), 10),
+			recurring: []models.RecurringTransaction{{Kind: models.RecurringKindExpense, Amount: 200, Cadence: models.CadenceMonthly, StartDate: date(2023, time.May, 20, 0)}},
+			wantEnd:   1000 - 300 - 200, wantYellow: 1, wantSample: 10,
+		},
+		{
+			// O aporte de 200 no dia 5 leva o saldo a 600 (60%) no dia 20
+			name: "mês corrente com aporte em meta", month: now, expenses: repeat(10, date(2023, time.June, 1, 9), 10),
+			goals:   []models.GoalContribution{{Amount: 200, CreatedAt: date(2023, time.June, 5, 9)}},
+			wantEnd: 1000 - 300 - 200, wantYellow: 1, wantSample: 10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := MonteCarlo(salary(1000, 0), nil, tt.recurring, tt.expenses, tt.goals, tt.month, tt.history, DefaultRules, 500, 1, FixedClock(now))
+			if !ok {
+				t.Fatal("ok = false")
+			}
+			for _, p := range []float64{got.P10EndOfMonthBalance, got.P50EndOfMonthBalance, got.P90EndOfMonthBalance} {
+				if !almostEqual(p, tt.wantEnd) {
+					t.Errorf("percentiles = %v/%v/%v; want %v", got.P10EndOfMonthBalance, got.P50EndOfMonthBalance, got.P90EndOfMonthBalance, tt.wantEnd)
+					break
+				}
+			}
+			if !almostEqual(got.YellowProbability, tt.wantYellow) || got.RedProbability != 0 {
+				t.Errorf("probabilities = %v/%v; want %v/0", got.YellowProbability, got.RedProbability, tt.wantYellow)
+			}
+			if got.Simulations != 500 || got.SampleDays != tt.wantSample || got.Seed != 1 {
+				t.Errorf("got %d simulations over %d days with seed %d; want 500 over %d with seed 1", got.Simulations, got.SampleDays, got.Seed, tt.wantSample)
+			}
+		})
+	}
+}
+
+func TestMonteCarloDistribution(t *testing.T) {
+	// Dias alternados de 0 e 100: saldo de 2500 no dia 10 e, nos 20 dias restantes, gasto de
+	// 100 vezes uma binomial(20; 0,5): mediana 1500, verde (acima de 1800) em ~6% das simulações e
+	// vermelho (abaixo de 750) quase nunca
+	now := date(2023, time.June, 10, 12)
+	var expenses []models.VariableExpense
+	for day := 1; day <= 10; day += 2 {
+		expenses = append(expenses, variable(100, date(2023, time.June, day, 9)))
+	}
+	run := func(seed int64) MonteCarloProjection {
+		got, ok := MonteCarlo(salary(3000, 0), nil, nil, expenses, nil, now, SpendingHistory{}, DefaultRules, MonteCarloSimulations, seed, FixedClock(now))
+		if !ok {
+			t.Fatal("ok = false")
+		}
+		return got
+	}
+
+	got := run(42)
+	if !(got.P10EndOfMonthBalance < got.P50EndOfMonthBalance && got.P50EndOfMonthBalance < got.P90EndOfMonthBalance) {
+		t.Errorf("percentiles = %v/%v/%v; want P10 < P50 < P90", got.P10EndOfMonthBalance, got.P50EndOfMonthBalance, got.P90EndOfMonthBalance)
+	}
+	if got.P50EndOfMonthBalance < 1300 || got.P50EndOfMonthBalance > 1700 {
+		t.Errorf("P50 = %v; want about 1500", got.P50EndOfMonthBalance)
+	}
+	if got.YellowProbability < 0.85 || got.YellowProbability >= 1 || got.RedProbability > 0.01 {
+		t.Errorf("probabilities = %v/%v; want about 0.94/0", got.YellowProbability, got.RedProbability)
+	}
+	if again := run(42); again != got {
+		t.Errorf("same seed gave %+v and %+v", got, again)
+	}
+}
+
+func TestMonteCarloUnavailable(t *testing.T) {
+	now := date(2023, time.June, 5, 12)
+	expenses := repeat(10, date(2023, time.June, 1, 9), 5)
+	if _, ok := MonteCarlo(salary(1000, 0), nil, nil, expenses, nil, date(2023, time.May, 1, 0), SpendingHistory{}, DefaultRules, 100, 1, FixedClock(now)); ok {
+		t.Error("ok for a past month")
+	}
+	if _, ok := MonteCarlo(salary(1000, 0), nil, nil, expenses, nil, now, SpendingHistory{}, DefaultRules, 100, 1, FixedClock(now)); ok {
+		t.Error("ok with 5 days observed")
+	}
+}
+
+func TestMonteCarloNewUser(t *testing.T) {
+	// Usuário que começou a registrar despesas em 22 de maio: 200 por dia, sem dias vazios antes disso
+	now := date(2023, time.June, 2, 12)
+	june := date(2023, time.June, 1, 0)
+	spent := repeat(200, date(2023, time.June, 1, 9), 2)
+	tests := []struct {
+		name    string
+		history SpendingHistory
+		wantOK  bool
+	}{
+		{"menos de 8 dias registrados", SpendingHistory{Expenses: repeat(200, date(2023, time.May, 28, 9), 4), From: date(2023, time.May, 28, 0), To: june}, false},
+		{"sem histórico", SpendingHistory{}, false},
+		{"dez dias no histórico", SpendingHistory{Expenses: repeat(200, date(2023, time.May, 22, 9), 10), From: date(2023, time.May, 22, 0), To: june}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := MonteCarlo(salary(10000, 0), nil, nil, spent, nil, now, tt.history, DefaultRules, 100, 1, FixedClock(now))
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v; want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			// 12 dias observados, todos de 200: 10000 - 30 × 200 em todas as simulações
+			if got.SampleDays != 12 || !almostEqual(got.P50EndOfMonthBalance, 4000) || !almostEqual(got.P90EndOfMonthBalance, 4000) {
+				t.Errorf("got %d sample days and P50/P90 %v/%v; want 12 and 4000", got.SampleDays, got.P50EndOfMonthBalance, got.P90EndOfMonthBalance)
+			}
+		})
+	}
+}
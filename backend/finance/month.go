@@ -0,0 +1,153 @@
+package finance
+
+import (
+	"personal-finance-app/backend/models"
+	"time"
+)
+
+// MonthFormat é o formato dos meses na API ("YYYY-MM").
+const MonthFormat = "2006-01"
+
+// MonthSummary resume um mês no histórico do saldo.
+type MonthSummary struct {
+	Month                 string  `json:"month"` // "YYYY-MM"
+	TotalIncome           float64 `json:"totalIncome"`
+	TotalFixedExpenses    float64 `json:"totalFixedExpenses"`
+	TotalVariableExpenses float64 `json:"totalVariableExpenses"`
+	GoalContributions     float64 `json:"goalContributions"` // Aportes em metas no mês, menos retiradas
+	NetFlow               float64 `json:"netFlow"`           // Renda - despesas fixas - despesas variáveis - aportes em metas
+	HealthPercentage      float64 `json:"healthPercentage"`
+	FinancialHealthStatus string  `json:"financialHealthStatus"` // "verde", "amarelo", "vermelho"
+}
+
+// endOfMonth retorna o último instante do mês de t.
+func endOfMonth(t time.Time) time.Time {
+	start, _ := MonthRange(t)
+	return start.AddDate(0, 1, 0).Add(-time.Nanosecond)
+}
+
+// sameMonth informa se a e b estão no mesmo mês.
+func sameMonth(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.Month() == b.Month()
+}
+
+// activeFixedTotal soma as despesas fixas ativas.
+func activeFixedTotal(fixedExpenses []models.FixedExpense) float64 {
+	total := 0.0
+	for _, expense := range fixedExpenses {
+		if expense.Active {
+			total += expense.Value
+		}
+	}
+	return total
+}
+
+// variableTotal soma as despesas variáveis com data entre from e to (inclusive).
+func variableTotal(variableExpenses []models.VariableExpense, from, to time.Time) float64 {
+	total := 0.0
+	for _, expense := range variableExpenses {
+		if !expense.Date.Before(from) && !expense.Date.After(to) {
+			total += expense.Value
+		}
+	}
+	return total
+}
+
+// DailyAverage é o gasto médio diário das despesas variáveis entre o início do mês de from e o fim
+// do mês anterior a to (meses completos). Sem meses completos no período, é 0.
+func DailyAverage(variableExpenses []models.VariableExpense, from, to time.Time) float64 {
+	start, _ := MonthRange(from)
+	end, _ := MonthRange(to)
+	days := int(end.Sub(start).Hours()/24 + 0.5) // Arredondado: o horário de verão altera a duração de um dia
+	if days <= 0 {
+		return 0
+	}
+	return variableTotal(variableExpenses, start, end.Add(-time.Nanosecond)) / float64(days)
+}
+
+// ComputeMonthBalance calcula o saldo de qualquer mês, a partir do momento atual do relógio, com a
+// projeção do forecaster sobre o histórico de gastos (os meses completos anteriores ao corrente):
+//   - mês corrente: como ComputeBalance;
+//   - mês passado: o saldo no fim do mês, com todas as despesas variáveis do mês (a projeção
+//     coincide com o realizado);
+//   - mês futuro: o saldo com as despesas variáveis já registradas no mês e a projeção de todos
+//     os dias do mês (sem dados para prever, o saldo projetado é o atual).
+//
+// Renda e despesas fixas são os valores atuais; as transações recorrentes entram com as ocorrências
+// do mês e os aportes em metas (menos as retiradas), no dia em que foram feitos. CategoryTotals e
+// Budgets ficam a cargo de quem chama.
+func ComputeMonthBalance(incomes []models.Income, fixedExpenses []models.FixedExpense, recurring []models.RecurringTransaction, variableExpenses []models.VariableExpense, goalContributions []models.GoalContribution, month time.Time, history SpendingHistory, forecaster Forecaster, rules Rules, clock Clock) BalanceResponse {
+	now := clock()
+	start, lastDay := MonthRange(month.In(now.Location()))
+	switch {
+	case sameMonth(start, now):
+		return computeBalance(incomes, fixedExpenses, recurring, variableExpenses, goalContributions, history, forecaster, rules, now)
+	case start.Before(now):
+		return computeBalance(incomes, fixedExpenses, recurring, variableExpenses, goalContributions, SpendingHistory{}, forecaster, rules, endOfMonth(start))
+	}
+
+	income := TotalIncome(incomes)
+	totalFixed := activeFixedTotal(fixedExpenses)
+	recurringIncome, recurringExpenses := recurringTotals(recurring, start)
+	registered := variablesBetween(variableExpenses, start, endOfMonth(start))
+	totalVariable := variableTotal(registered, start, endOfMonth(start))
+	contributions := contributionsBetween(goalContributions, start, endOfMonth(start))
+	currentBalance := income + recurringIncome - totalFixed - recurringExpenses - totalVariable - contributions
+	healthPercentage := rules.Percentage(currentBalance, income)
+	forecast, ok := forecaster.Forecast(ForecastInput{Month: start, Spent: registered, History: history})
+	if !ok {
+		forecast = SpendingForecast{Daily: []float64{}}
+	}
+	return BalanceResponse{
+		CurrentBalance:           currentBalance,
+		TotalIncome:              income,
+		TotalFixedExpenses:       totalFixed,
+		TotalVariableExpenses:    totalVariable,
+		TotalRecurringIncome:     recurringIncome,
+		TotalRecurringExpenses:   recurringExpenses,
+		GoalContributions:        contributions,
+		FinancialHealthStatus:    rules.Status(healthPercentage),
+		HealthPercentage:         healthPercentage,
+		DaysInMonthForProjection: lastDay.Day(),
+		Projection:               projectFrom(incomes, fixedExpenses, recurring, registered, goalContributions, forecast, 0, start, rules),
+		HealthRules:              rules.Definitions(),
+	}
+}
+
+// History resume, mês a mês, a renda, as despesas fixas e variáveis e a saúde financeira (segundo as
+// regras) de from a to (inclusive). Renda e despesas fixas são os valores atuais em todos os meses;
+// as despesas variáveis e os aportes em metas (descontados como no saldo do mês) são os registrados
+// em cada mês.
+func History(income float64, fixedExpenses []models.FixedExpense, variableExpenses []models.VariableExpense, goalContributions []models.GoalContribution, from, to time.Time, rules Rules) []MonthSummary {
+	totalFixed := activeFixedTotal(fixedExpenses)
+	start, _ := MonthRange(from)
+	last, _ := MonthRange(to)
+
+	history := []MonthSummary{}
+	for month := start; !month.After(last); month = month.AddDate(0, 1, 0) {
+		totalVariable := variableTotal(variableExpenses, month, endOfMonth(month))
+		contributions := contributionsBetween(goalContributions, month, endOfMonth(month))
+		netFlow := income - totalFixed - totalVariable - contributions
+		healthPercentage := rules.Percentage(netFlow, income)
+		history = append(history, MonthSummary{
+			Month:                 month.Format(MonthFormat),
+			TotalIncome:           income,
+			TotalFixedExpenses:    totalFixed,
+			TotalVariableExpenses: totalVariable,
+			GoalContributions:     contributions,
+			NetFlow:               netFlow,
+			HealthPercentage:      healthPercentage,
+			FinancialHealthStatus: rules.Status(healthPercentage),
+		})
+	}
+	return history
+}
+
+// MonthsBetween conta os meses de from a to, inclusive (0 se to for anterior a from).
+func MonthsBetween(from, to time.Time) int {
+	months := (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month()) + 1
+	if months < 0 {
+		return 0
+	}
+	return months
+}
@@ -0,0 +1,189 @@
+package finance
+
+import (
+	"personal-finance-app/backend/models"
+	"testing"
+	"time"
+)
+
+func TestMonthsBetween(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     int
+	}{
+		{"mesmo mês", date(2023, time.May, 31, 0), date(2023, time.May, 1, 0), 1},
+		{"meses seguidos", date(2023, time.May, 1, 0), date(2023, time.June, 1, 0), 2},
+		{"virada do ano", date(2023, time.November, 1, 0), date(2024, time.February, 1, 0), 4},
+		{"doze meses", date(2023, time.January, 1, 0), date(2023, time.December, 1, 0), 12},
+		{"to antes de from", date(2023, time.June, 1, 0), date(2023, time.May, 1, 0), 0},
+		{"anos antes", date(2023, time.June, 1, 0), date(2020, time.May, 1, 0), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MonthsBetween(tt.from, tt.to); got != tt.want {
+				t.Errorf("MonthsBetween = %d; want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDailyAverage(t *testing.T) {
+	expenses := []models.VariableExpense{
+		variable(310, date(2023, time.January, 15, 12)),
+		variable(280, date(2023, time.February, 28, 23)),
+		variable(310, date(2023, time.March, 31, 23)),
+		variable(999, date(2023, time.April, 1, 0)), // Fora do período (mês de to)
+		variable(999, date(2022, time.December, 31, 23)),
+	}
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     float64
+	}{
+		{"três meses completos", date(2023, time.January, 20, 0), date(2023, time.April, 10, 0), 900.0 / 90},
+		{"fevereiro", date(2023, time.February, 1, 0), date(2023, time.March, 1, 0), 10},
+		{"sem meses completos", date(2023, time.April, 1, 0), date(2023, time.April, 30, 0), 0},
+		{"to antes de from", date(2023, time.April, 1, 0), date(2023, time.January, 1, 0), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DailyAverage(expenses, tt.from, tt.to); !almostEqual(got, tt.want) {
+				t.Errorf("DailyAverage = %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeMonthBalance(t *testing.T) {
+	now := date(2023, time.June, 15, 12)
+	fixedExpenses := []models.FixedExpense{fixed(300, true), fixed(100, false)}
+	expenses := []models.VariableExpense{
+		variable(150, date(2023, time.June, 10, 9)),
+		variable(50, date(2023, time.June, 20, 9)), // Depois de agora
+		variable(620, date(2023, time.May, 31, 23)),
+		variable(90, date(2023, time.July, 5, 9)), // Registrada no mês futuro
+	}
+	rent := models.RecurringTransaction{Kind: models.RecurringKindExpense, Amount: 200, Cadence: models.CadenceMonthly, StartDate: date(2023, time.January, 20, 0)}
+	bonus := models.RecurringTransaction{Kind: models.RecurringKindIncome, Amount: 100, Cadence: models.CadenceYearly, StartDate: date(2022, time.August, 10, 0)}
+
+	tests := []struct {
+		name           string
+		month          time.Time
+		recurring      []models.RecurringTransaction
+		contributions  []models.GoalContribution
+		history        SpendingHistory
+		wantBalance    float64
+		wantVariable   float64
+		wantStatus     string
+		wantEndOfMonth float64
+		wantElapsed    int
+		wantYellow     string
+	}{
+		{
+			name: "mês corrente", month: date(2023, time.June, 1, 0),
+			wantBalance: 550, wantVariable: 150, wantStatus: HealthYellow,
+			wantEndOfMonth: 1000 - 300 - 10*30, wantElapsed: 15, wantYellow: "2023-06-16",
+		},
+		{
+			name: "mês passado: realizado", month: date(2023, time.May, 1, 0),
+			wantBalance: 80, wantVariable: 620, wantStatus: HealthRed,
+			wantEndOfMonth: 80, wantElapsed: 31,
+		},
+		{
+			name: "mês futuro: registradas mais o gasto médio dos meses anteriores", month: date(2023, time.July, 1, 0),
+			history:     SpendingHistory{Expenses: []models.VariableExpense{variable(155, date(2023, time.May, 10, 9))}, From: date(2023, time.May, 1, 0), To: date(2023, time.June, 1, 0)},
+			wantBalance: 610, wantVariable: 90, wantStatus: HealthGreen,
+			// A despesa de 90 entra no dia 5: 1000 - 300 - 90 - 5*5 = 585 (58,5%)
+			wantEndOfMonth: 1000 - 300 - 90 - 5*31, wantYellow: "2023-07-05",
+		},
+		{
+			name: "mês futuro sem histórico", month: date(2023, time.August, 1, 0),
+			wantBalance: 700, wantStatus: HealthGreen, wantEndOfMonth: 700,
+		},
+		{
+			// A saída de 200 ainda vai ocorrer no dia 20, mas já sai do saldo, como as despesas fixas
+			name: "mês corrente com saída recorrente", month: date(2023, time.June, 1, 0), recurring: []models.RecurringTransaction{rent},
+			wantBalance: 350, wantVariable: 150, wantStatus: HealthYellow,
+			wantEndOfMonth: 1000 - 300 - 200 - 10*30, wantElapsed: 15, wantYellow: "2023-06-16",
+		},
+		{
+			// O bônus do dia 10 não compensa o aluguel do dia 20: 1000 - 300 + 100 - 200 = 600 (60%)
+			name: "mês futuro com entrada recorrente", month: date(2023, time.August, 1, 0), recurring: []models.RecurringTransaction{rent, bonus},
+			wantBalance: 600, wantStatus: HealthYellow, wantEndOfMonth: 600, wantYellow: "2023-08-20",
+		},
+		{
+			name: "mês corrente com aporte em meta", month: date(2023, time.June, 1, 0),
+			contributions: []models.GoalContribution{{Amount: 250, CreatedAt: date(2023, time.June, 12, 9)}, {Amount: -50, CreatedAt: date(2023, time.June, 14, 9)}},
+			wantBalance:   350, wantVariable: 150, wantStatus: HealthYellow,
+			wantEndOfMonth: 1000 - 300 - 200 - 10*30, wantElapsed: 15, wantYellow: "2023-06-16",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ComputeMonthBalance(salary(1000, 0), fixedExpenses, tt.recurring, expenses, tt.contributions, tt.month, tt.history, MonthAverage{}, DefaultRules, FixedClock(now))
+			if !almostEqual(got.CurrentBalance, tt.wantBalance) || !almostEqual(got.TotalVariableExpenses, tt.wantVariable) {
+				t.Errorf("balance = %v (variable %v); want %v (variable %v)", got.CurrentBalance, got.TotalVariableExpenses, tt.wantBalance, tt.wantVariable)
+			}
+			if got.FinancialHealthStatus != tt.wantStatus {
+				t.Errorf("FinancialHealthStatus = %q; want %q", got.FinancialHealthStatus, tt.wantStatus)
+			}
+			if got.Projection == nil {
+				t.Fatal("Projection = nil")
+			}
+			if !almostEqual(got.Projection.EndOfMonthBalance, tt.wantEndOfMonth) {
+				t.Errorf("EndOfMonthBalance = %v; want %v", got.Projection.EndOfMonthBalance, tt.wantEndOfMonth)
+			}
+			if got.DayOfMonthForProjection != tt.wantElapsed {
+				t.Errorf("DayOfMonthForProjection = %d; want %d", got.DayOfMonthForProjection, tt.wantElapsed)
+			}
+			if got.Projection.YellowAlertDay != tt.wantYellow {
+				t.Errorf("YellowAlertDay = %q; want %q", got.Projection.YellowAlertDay, tt.wantYellow)
+			}
+		})
+	}
+}
+
+func TestHistory(t *testing.T) {
+	expenses := []models.VariableExpense{
+		variable(100, date(2023, time.November, 30, 23)),
+		variable(500, date(2023, time.December, 1, 0)),
+		variable(200, date(2023, time.December, 31, 23)),
+		variable(900, date(2024, time.February, 29, 12)),
+	}
+	goals := []models.GoalContribution{
+		{Amount: 300, CreatedAt: date(2024, time.January, 10, 9)},
+		{Amount: -100, CreatedAt: date(2024, time.January, 20, 9)},
+		{Amount: 50, CreatedAt: date(2024, time.March, 1, 9)}, // Fora do período
+	}
+	got := History(2000, []models.FixedExpense{fixed(500, true)}, expenses, goals, date(2023, time.November, 15, 0), date(2024, time.February, 1, 0), DefaultRules)
+
+	want := []struct {
+		month    string
+		variable float64
+		goals    float64
+		netFlow  float64
+		status   string
+	}{
+		{"2023-11", 100, 0, 1400, HealthGreen},
+		{"2023-12", 700, 0, 800, HealthYellow},
+		{"2024-01", 0, 200, 1300, HealthGreen},
+		{"2024-02", 900, 0, 600, HealthYellow},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("History returned %d months; want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		m := got[i]
+		if m.Month != w.month || !almostEqual(m.TotalVariableExpenses, w.variable) || !almostEqual(m.GoalContributions, w.goals) || !almostEqual(m.NetFlow, w.netFlow) || m.FinancialHealthStatus != w.status {
+			t.Errorf("History[%d] = %+v; want month %s, variable %v, goals %v, net flow %v, %s", i, m, w.month, w.variable, w.goals, w.netFlow, w.status)
+		}
+		if !almostEqual(m.TotalIncome, 2000) || !almostEqual(m.TotalFixedExpenses, 500) || !almostEqual(m.HealthPercentage, w.netFlow/20) {
+			t.Errorf("History[%d] = %+v; want income 2000, fixed 500, health %v", i, m, w.netFlow/20)
+		}
+	}
+
+	if empty := History(2000, nil, nil, nil, date(2024, time.March, 1, 0), date(2024, time.January, 1, 0), DefaultRules); empty == nil || len(empty) != 0 {
+		t.Errorf("History with to before from = %#v; want an empty slice", empty)
+	}
+}
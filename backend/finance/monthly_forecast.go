@@ -0,0 +1,65 @@
+package finance
+
+import (
+	"math"
+	"personal-finance-app/backend/models"
+	"time"
+)
+
+// MonthForecast é a previsão de um mês no fluxo de caixa dos próximos meses.
+type MonthForecast struct {
+	Month                      string  `json:"month"` // "YYYY-MM"
+	Income                     float64 `json:"income"`
+	FixedExpenses              float64 `json:"fixedExpenses"`
+	RecurringIncome            float64 `json:"recurringIncome"`            // Entradas recorrentes (ver models.RecurringTransaction)
+	RecurringExpenses          float64 `json:"recurringExpenses"`          // Saídas recorrentes
+	RegisteredVariableExpenses float64 `json:"registeredVariableExpenses"` // Realizadas e agendadas (com data futura)
+	ProjectedVariableExpenses  float64 `json:"projectedVariableExpenses"`  // Previstas pelo modelo nos dias ainda não realizados
+	GoalContributions          float64 `json:"goalContributions"`          // Aportes em metas, menos retiradas, já feitos no mês
+	NetFlow                    float64 `json:"netFlow"`                    // Renda e entradas recorrentes - despesas fixas, saídas recorrentes, variáveis e aportes em metas
+	CumulativeBalance          float64 `json:"cumulativeBalance"`          // Saldo acumulado no fim do mês, desde o início do mês corrente
+	LowestBalance              float64 `json:"lowestBalance"`              // Menor saldo acumulado em um dia do mês
+	Negative                   bool    `json:"negative"`                   // O saldo acumulado fica negativo em algum dia do mês
+}
+
+// ForecastMonths prevê os próximos months meses, a partir do mês corrente (segundo o relógio),
+// somando mês a mês o fluxo de caixa diário (ver CashFlow): a renda, as despesas fixas e as transações
+// recorrentes nas suas datas, as despesas variáveis registradas (inclusive as agendadas para datas
+// futuras), os aportes em metas já feitos e, nos dias ainda não realizados, o gasto previsto pelo
+// forecaster a partir do histórico.
+func ForecastMonths(incomes []models.Income, fixedExpenses []models.FixedExpense, recurring []models.RecurringTransaction, variableExpenses []models.VariableExpense, goalContributions []models.GoalContribution, months int, history SpendingHistory, forecaster Forecaster, rules Rules, clock Clock) []MonthForecast {
+	now := clock()
+	start, _ := MonthRange(now)
+	forecasts := []MonthForecast{}
+	if months < 1 {
+		return forecasts
+	}
+	_, lastDay := MonthRange(start.AddDate(0, months-1, 0))
+	flow := CashFlow(incomes, fixedExpenses, recurring, variableExpenses, goalContributions, start, lastDay, history, forecaster, rules, FixedClock(now))
+
+	for _, day := range flow {
+		month := day.Date[:len(MonthFormat)]
+		if len(forecasts) == 0 || forecasts[len(forecasts)-1].Month != month {
+			forecasts = append(forecasts, MonthForecast{Month: month, LowestBalance: math.Inf(1)})
+		}
+		forecast := &forecasts[len(forecasts)-1]
+		forecast.Income += day.Income
+		forecast.FixedExpenses += day.FixedExpenses
+		forecast.RecurringIncome += day.RecurringIncome
+		forecast.RecurringExpenses += day.RecurringExpenses
+		forecast.GoalContributions += day.GoalContributions
+		forecast.ProjectedVariableExpenses += day.VariableExpenses // Descontadas as registradas abaixo
+		forecast.NetFlow = day.Balance
+		forecast.CumulativeBalance = day.CumulativeBalance
+		forecast.LowestBalance = math.Min(forecast.LowestBalance, day.CumulativeBalance)
+	}
+
+	for i := range forecasts {
+		forecast := &forecasts[i]
+		month, _ := time.ParseInLocation(MonthFormat, forecast.Month, now.Location())
+		forecast.RegisteredVariableExpenses = variableTotal(variableExpenses, month, endOfMonth(month))
+		forecast.ProjectedVariableExpenses -= forecast.RegisteredVariableExpenses
+		forecast.Negative = forecast.LowestBalance < 0
+	}
+	return forecasts
+}
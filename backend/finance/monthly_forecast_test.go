@@ -0,0 +1,48 @@
+package finance
+
+import (
+	"personal-finance-app/backend/models"
+	"testing"
+	"time"
+)
+
+func TestForecastMonths(t *testing.T) {
+	now := date(2023, time.June, 10, 12)
+	may := SpendingHistory{Expenses: repeat(10, date(2023, time.May, 1, 9), 31), From: date(2023, time.May, 1, 0), To: date(2023, time.June, 1, 0)}
+	expenses := []models.VariableExpense{
+		variable(100, date(2023, time.June, 2, 9)),
+		variable(50, date(2023, time.June, 10, 9)),
+		variable(2000, date(2023, time.August, 20, 0)), // Viagem agendada
+	}
+	ipva := models.RecurringTransaction{Kind: models.RecurringKindExpense, Amount: 500, Cadence: models.CadenceYearly, StartDate: date(2022, time.July, 20, 0)}
+	got := ForecastMonths(salary(3000, 5), []models.FixedExpense{due(2000, 10)}, []models.RecurringTransaction{ipva}, expenses, nil, 3, may, MonthAverage{}, DefaultRules, FixedClock(now))
+
+	want := []MonthForecast{
+		// Junho: 150 realizados e 15 por dia (média do mês) nos 20 dias restantes; antes do salário, o saldo é negativo
+		{Month: "2023-06", Income: 3000, FixedExpenses: 2000, RegisteredVariableExpenses: 150, ProjectedVariableExpenses: 300,
+			NetFlow: 550, CumulativeBalance: 550, LowestBalance: -100, Negative: true},
+		// Julho: 10 por dia (média de maio) e o IPVA anual; o menor saldo é no dia 4, antes do salário
+		{Month: "2023-07", Income: 3000, FixedExpenses: 2000, RecurringExpenses: 500, ProjectedVariableExpenses: 310,
+			NetFlow: 190, CumulativeBalance: 740, LowestBalance: 510},
+		// Agosto: a viagem deixa o saldo acumulado negativo no fim do mês
+		{Month: "2023-08", Income: 3000, FixedExpenses: 2000, RegisteredVariableExpenses: 2000, ProjectedVariableExpenses: 310,
+			NetFlow: -1310, CumulativeBalance: -570, LowestBalance: -570, Negative: true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ForecastMonths returned %d months; want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Month != w.Month || g.Negative != w.Negative ||
+			!almostEqual(g.Income, w.Income) || !almostEqual(g.FixedExpenses, w.FixedExpenses) ||
+			!almostEqual(g.RecurringIncome, w.RecurringIncome) || !almostEqual(g.RecurringExpenses, w.RecurringExpenses) ||
+			!almostEqual(g.RegisteredVariableExpenses, w.RegisteredVariableExpenses) || !almostEqual(g.ProjectedVariableExpenses, w.ProjectedVariableExpenses) ||
+			!almostEqual(g.NetFlow, w.NetFlow) || !almostEqual(g.CumulativeBalance, w.CumulativeBalance) || !almostEqual(g.LowestBalance, w.LowestBalance) {
+			t.Errorf("ForecastMonths[%d] = %+v; want %+v", i, g, w)
+		}
+	}
+
+	if empty := ForecastMonths(salary(3000, 5), nil, nil, nil, nil, 0, may, MonthAverage{}, DefaultRules, FixedClock(now)); empty == nil || len(empty) != 0 {
+		t.Errorf("ForecastMonths(0 months) = %#v; want an empty slice", empty)
+	}
+}
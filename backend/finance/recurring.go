@@ -0,0 +1,72 @@
+package finance
+
+import (
+	"personal-finance-app/backend/models"
+	"time"
+)
+
+// calendarDay é a meia-noite da data de t no fuso loc.
+func calendarDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// daysBetween conta os dias de calendário de a até b (negativo se b for anterior a a), sem depender
+// do horário de verão.
+func daysBetween(a, b time.Time) int {
+	utcA := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	utcB := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(utcB.Sub(utcA).Hours() / 24)
+}
+
+// recurringDays retorna os dias do mês de month (1 a 31) em que a transação ocorre. As ocorrências
+// semanais caem a cada 7 dias a partir de StartDate; as mensais e anuais, no dia de StartDate ou,
+// se o mês não tiver esse dia (31 em abril, 29 de fevereiro), no último dia do mês.
+func recurringDays(transaction models.RecurringTransaction, month time.Time) []int {
+	start, lastDay := MonthRange(month)
+	first := calendarDay(transaction.StartDate, start.Location())
+	if first.After(lastDay) {
+		return nil
+	}
+	last := lastDay
+	if transaction.EndDate != nil {
+		if end := calendarDay(*transaction.EndDate, start.Location()); end.Before(last) {
+			last = end
+		}
+	}
+
+	var days []int
+	switch transaction.Cadence {
+	case models.CadenceWeekly:
+		next := first
+		if first.Before(start) {
+			next = first.AddDate(0, 0, (daysBetween(first, start)+6)/7*7)
+		}
+		for ; !next.After(last); next = next.AddDate(0, 0, 7) {
+			days = append(days, next.Day())
+		}
+	case models.CadenceMonthly, models.CadenceYearly:
+		if transaction.Cadence == models.CadenceYearly && month.Month() != first.Month() {
+			return nil
+		}
+		day := paymentDay(first.Day(), lastDay)
+		if occurrence := start.AddDate(0, 0, day-1); !occurrence.Before(first) && !occurrence.After(last) {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+// recurringTotals soma as ocorrências das transações recorrentes no mês de month: as entradas e as
+// saídas.
+func recurringTotals(recurring []models.RecurringTransaction, month time.Time) (income, expenses float64) {
+	for _, transaction := range recurring {
+		total := transaction.Amount * float64(len(recurringDays(transaction, month)))
+		if transaction.Kind == models.RecurringKindIncome {
+			income += total
+		} else {
+			expenses += total
+		}
+	}
+	return income, expenses
+}
@@ -0,0 +1,34 @@
+package finance
+
+import (
+	"personal-finance-app/backend/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRecurringDays(t *testing.T) {
+	end := date(2023, time.June, 20, 0)
+	tests := []struct {
+		name        string
+		transaction models.RecurringTransaction
+		month       time.Time
+		want        []int
+	}{
+		{"semanal", models.RecurringTransaction{Cadence: models.CadenceWeekly, StartDate: date(2023, time.May, 26, 9)}, date(2023, time.June, 1, 0), []int{2, 9, 16, 23, 30}},
+		{"semanal até o fim", models.RecurringTransaction{Cadence: models.CadenceWeekly, StartDate: date(2023, time.May, 26, 9), EndDate: &end}, date(2023, time.June, 1, 0), []int{2, 9, 16}},
+		{"semanal começando no mês", models.RecurringTransaction{Cadence: models.CadenceWeekly, StartDate: date(2023, time.June, 14, 0)}, date(2023, time.June, 1, 0), []int{14, 21, 28}},
+		{"mensal no último dia", models.RecurringTransaction{Cadence: models.CadenceMonthly, StartDate: date(2023, time.January, 31, 0)}, date(2023, time.April, 1, 0), []int{30}},
+		{"mensal antes do início", models.RecurringTransaction{Cadence: models.CadenceMonthly, StartDate: date(2023, time.July, 5, 0)}, date(2023, time.June, 1, 0), nil},
+		{"mensal depois do fim", models.RecurringTransaction{Cadence: models.CadenceMonthly, StartDate: date(2023, time.January, 25, 0), EndDate: &end}, date(2023, time.June, 1, 0), nil},
+		{"anual no mês", models.RecurringTransaction{Cadence: models.CadenceYearly, StartDate: date(2020, time.February, 29, 0)}, date(2023, time.February, 1, 0), []int{28}},
+		{"anual em outro mês", models.RecurringTransaction{Cadence: models.CadenceYearly, StartDate: date(2020, time.February, 29, 0)}, date(2023, time.March, 1, 0), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := recurringDays(tt.transaction, tt.month); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("recurringDays = %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package finance
+
+import "fmt"
+
+// Bases da saúde financeira: o percentual do saldo é calculado sobre a renda ou sobre a meta de economia
+const (
+	HealthBasisIncome        = "income"
+	HealthBasisSavingsTarget = "savings_target"
+)
+
+// Rules são as regras de classificação da saúde financeira, configuráveis por usuário.
+type Rules struct {
+	Thresholds
+	Basis         string  // HealthBasisIncome ou HealthBasisSavingsTarget
+	SavingsTarget float64 // Quanto o usuário quer que sobre no mês (base HealthBasisSavingsTarget)
+}
+
+// DefaultRules são as regras de quem não configurou as suas: percentual da renda, 60% e 25%.
+var DefaultRules = Rules{Thresholds: DefaultThresholds, Basis: HealthBasisIncome}
+
+// Percentage é o saldo como percentual da base das regras (a renda ou a meta de economia).
+func (r Rules) Percentage(balance, income float64) float64 {
+	if r.Basis == HealthBasisSavingsTarget {
+		return HealthPercentage(balance, r.SavingsTarget)
+	}
+	return HealthPercentage(balance, income)
+}
+
+// Status classifica o percentual em verde, amarelo ou vermelho.
+func (r Rules) Status(percentage float64) string {
+	return HealthStatus(percentage, r.Thresholds)
+}
+
+// StatusDefinition descreve a faixa de percentual de um estado da saúde financeira, para que os
+// clientes exibam as regras sem repetir a lógica.
+type StatusDefinition struct {
+	Status        string   `json:"status"`                  // "verde", "amarelo" ou "vermelho"
+	MinPercentage *float64 `json:"minPercentage,omitempty"` // Sem limite inferior se ausente
+	MinInclusive  bool     `json:"minInclusive"`
+	MaxPercentage *float64 `json:"maxPercentage,omitempty"` // Sem limite superior se ausente
+	MaxInclusive  bool     `json:"maxInclusive"`
+	Description   string   `json:"description"`
+}
+
+// HealthRules são as regras da saúde financeira aplicadas no cálculo, devolvidas com o saldo.
+type HealthRules struct {
+	Basis           string             `json:"basis"` // "income" ou "savings_target"
+	SavingsTarget   float64            `json:"savingsTarget,omitempty"`
+	GreenThreshold  float64            `json:"greenThreshold"`
+	YellowThreshold float64            `json:"yellowThreshold"`
+	Statuses        []StatusDefinition `json:"statuses"`
+}
+
+// Definitions descreve as regras e as faixas de cada estado.
+func (r Rules) Definitions() HealthRules {
+	green, yellow := r.Green, r.Yellow
+	basis := "da renda"
+	if r.Basis == HealthBasisSavingsTarget {
+		basis = "da meta de economia"
+	}
+	return HealthRules{
+		Basis:           r.Basis,
+		SavingsTarget:   r.SavingsTarget,
+		GreenThreshold:  green,
+		YellowThreshold: yellow,
+		Statuses: []StatusDefinition{
+			{Status: HealthGreen, MinPercentage: &green,
+				Description: fmt.Sprintf("O saldo é mais de %g%% %s", green, basis)},
+			{Status: HealthYellow, MinPercentage: &yellow, MinInclusive: true, MaxPercentage: &green, MaxInclusive: true,
+				Description: fmt.Sprintf("O saldo é de %g%% a %g%% %s", yellow, green, basis)},
+			{Status: HealthRed, MaxPercentage: &yellow,
+				Description: fmt.Sprintf("O saldo é menos de %g%% %s", yellow, basis)},
+		},
+	}
+}
@@ -0,0 +1,102 @@
+package finance
+
+import (
+	"personal-finance-app/backend/models"
+	"testing"
+	"time"
+)
+
+func TestRulesPercentage(t *testing.T) {
+	tests := []struct {
+		name            string
+		rules           Rules
+		balance, income float64
+		want            float64
+	}{
+		{"renda", DefaultRules, 600, 2000, 30},
+		{"meta de economia", Rules{Basis: HealthBasisSavingsTarget, SavingsTarget: 500}, 600, 2000, 120},
+		{"meta de economia zerada", Rules{Basis: HealthBasisSavingsTarget}, 600, 2000, 0},
+		{"base vazia usa a renda", Rules{}, 600, 2000, 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rules.Percentage(tt.balance, tt.income); !almostEqual(got, tt.want) {
+				t.Errorf("Percentage(%v, %v) = %v; want %v", tt.balance, tt.income, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRulesDefinitions(t *testing.T) {
+	rules := Rules{Thresholds: Thresholds{Green: 80, Yellow: 40}, Basis: HealthBasisSavingsTarget, SavingsTarget: 1000}
+	got := rules.Definitions()
+	if got.Basis != HealthBasisSavingsTarget || got.SavingsTarget != 1000 || got.GreenThreshold != 80 || got.YellowThreshold != 40 {
+		t.Errorf("Definitions() = %+v", got)
+	}
+	if len(got.Statuses) != 3 {
+		t.Fatalf("Definitions() returned %d statuses; want 3", len(got.Statuses))
+	}
+
+	// Cada faixa deve classificar os percentuais como Status
+	for _, percentage := range []float64{-50, 0, 39.99, 40, 60, 80, 80.01, 150} {
+		matches := 0
+		for _, definition := range got.Statuses {
+			aboveMin := definition.MinPercentage == nil || percentage > *definition.MinPercentage ||
+				(definition.MinInclusive && percentage == *definition.MinPercentage)
+			belowMax := definition.MaxPercentage == nil || percentage < *definition.MaxPercentage ||
+				(definition.MaxInclusive && percentage == *definition.MaxPercentage)
+			if aboveMin && belowMax {
+				matches++
+				if want := rules.Status(percentage); definition.Status != want {
+					t.Errorf("%v%% falls in the %q definition; Status returns %q", percentage, definition.Status, want)
+				}
+			}
+		}
+		if matches != 1 {
+			t.Errorf("%v%% falls in %d definitions; want exactly 1", percentage, matches)
+		}
+	}
+}
+
+func TestComputeBalanceWithRules(t *testing.T) {
+	now := date(2023, time.June, 10, 12)
+	expenses := []models.VariableExpense{variable(500, date(2023, time.June, 5, 9))}
+	tests := []struct {
+		name       string
+		rules      Rules
+		wantHealth float64
+		wantStatus string
+		wantYellow string
+		wantRed    string
+	}{
+		{
+			// Saldo 1500 de 2000 (75%), GMD 50: 60% no dia 16; no dia 30, 25% ainda é amarelo
+			name: "padrão", rules: DefaultRules,
+			wantHealth: 75, wantStatus: HealthGreen, wantYellow: "2023-06-16", wantRed: "",
+		},
+		{
+			// 72,5% no dia 11, 70% (amarelo) no dia 12 e 67,5% no dia 13
+			name: "limites próprios", rules: Rules{Thresholds: Thresholds{Green: 80, Yellow: 70}, Basis: HealthBasisIncome},
+			wantHealth: 75, wantStatus: HealthYellow, wantYellow: "2023-06-11", wantRed: "2023-06-13",
+		},
+		{
+			// Meta de 1000: saldo 1500 é 150% da meta; 1000 (100%) no dia 20, 500 (50%) no dia 30
+			name: "meta de economia", rules: Rules{Thresholds: Thresholds{Green: 100, Yellow: 50}, Basis: HealthBasisSavingsTarget, SavingsTarget: 1000},
+			wantHealth: 150, wantStatus: HealthGreen, wantYellow: "2023-06-20", wantRed: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ComputeBalance(salary(2000, 0), nil, expenses, tt.rules, FixedClock(now))
+			if !almostEqual(got.HealthPercentage, tt.wantHealth) || got.FinancialHealthStatus != tt.wantStatus {
+				t.Errorf("health = %v %q; want %v %q", got.HealthPercentage, got.FinancialHealthStatus, tt.wantHealth, tt.wantStatus)
+			}
+			if got.Projection.YellowAlertDay != tt.wantYellow || got.Projection.RedAlertDay != tt.wantRed {
+				t.Errorf("alerts = %q/%q; want %q/%q", got.Projection.YellowAlertDay, got.Projection.RedAlertDay, tt.wantYellow, tt.wantRed)
+			}
+			if got.HealthRules.GreenThreshold != tt.rules.Green || len(got.HealthRules.Statuses) != 3 {
+				t.Errorf("HealthRules = %+v; want the rules used", got.HealthRules)
+			}
+		})
+	}
+}
@@ -0,0 +1,4 @@
+// Package financepb contém o código Go gerado a partir de proto/finance.proto (API gRPC).
+package financepb
+
+//go:generate protoc -I ../proto --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative finance.proto
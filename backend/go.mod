@@ -3,10 +3,16 @@ module personal-finance-app/backend
 go 1.21 // Ou a versão Go que você pretende usar
 
 require (
+	github.com/getkin/kin-openapi v0.123.0
 	github.com/gin-gonic/gin v1.9.1
+	github.com/go-playground/validator/v10 v10.14.0
 	github.com/golang-jwt/jwt/v4 v4.5.0
+	github.com/graphql-go/graphql v0.8.1
+	github.com/jackc/pgx/v5 v5.4.3
 	github.com/joho/godotenv v1.5.1 // Adicionado para carregar .env
 	golang.org/x/crypto v0.17.0
+	google.golang.org/grpc v1.62.1
+	google.golang.org/protobuf v1.34.1
 	gorm.io/driver/postgres v1.5.4
 	gorm.io/gorm v1.25.5
 )
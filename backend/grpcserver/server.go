@@ -0,0 +1,159 @@
+// Package grpcserver expõe a API gRPC (proto/finance.proto) para serviços internos, usando as mesmas
+// regras de negócio e a mesma validação de JWT da API HTTP.
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"personal-finance-app/backend/apierrors"
+	"personal-finance-app/backend/financepb"
+	"personal-finance-app/backend/middleware"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// contextKey é o tipo das chaves guardadas no contexto das chamadas.
+type contextKey string
+
+// userIDKey guarda o ID do usuário autenticado pelo interceptor.
+const userIDKey contextKey = "userID"
+
+// publicMethods são os métodos que não exigem o metadado "authorization".
+var publicMethods = map[string]bool{
+	financepb.AuthService_VerifyToken_FullMethodName: true,
+}
+
+// NewServer cria o servidor gRPC com todos os serviços registrados.
+func NewServer() *grpc.Server {
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(unaryAuthInterceptor),
+		grpc.StreamInterceptor(streamAuthInterceptor),
+	)
+	financepb.RegisterAuthServiceServer(server, &authService{})
+	financepb.RegisterExpenseServiceServer(server, &expenseService{})
+	financepb.RegisterBalanceServiceServer(server, &balanceService{})
+	return server
+}
+
+// Serve inicia o servidor gRPC na porta informada. Bloqueia até o servidor parar.
+func Serve(port string) error {
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return err
+	}
+	log.Printf("gRPC server started at port %s", port)
+	return NewServer().Serve(listener)
+}
+
+// authenticate valida o token enviado no metadado "authorization" ("Bearer <token>")
+// e retorna um contexto com o ID do usuário.
+func authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var header string
+	if values := md.Get("authorization"); len(values) > 0 {
+		header = values[0]
+	}
+	tokenString, err := middleware.BearerToken(header)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	claims, err := middleware.ParseToken(tokenString)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 32)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "Invalid user ID format")
+	}
+	return context.WithValue(ctx, userIDKey, uint(userID)), nil
+}
+
+func unaryAuthInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if publicMethods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+	ctx, err := authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// authenticatedStream substitui o contexto do stream pelo contexto autenticado.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context { return s.ctx }
+
+func streamAuthInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if publicMethods[info.FullMethod] {
+		return handler(srv, stream)
+	}
+	ctx, err := authenticate(stream.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+}
+
+// userIDFromContext lê o usuário autenticado pelo interceptor.
+func userIDFromContext(ctx context.Context) uint {
+	userID, _ := ctx.Value(userIDKey).(uint)
+	return userID
+}
+
+// httpStatusCodes mapeia os status HTTP dos erros de negócio para códigos gRPC.
+var httpStatusCodes = map[int]codes.Code{
+	http.StatusBadRequest:            codes.InvalidArgument,
+	http.StatusUnauthorized:          codes.Unauthenticated,
+	http.StatusForbidden:             codes.PermissionDenied,
+	http.StatusNotFound:              codes.NotFound,
+	http.StatusConflict:              codes.AlreadyExists,
+	http.StatusPreconditionFailed:    codes.FailedPrecondition,
+	http.StatusRequestEntityTooLarge: codes.ResourceExhausted,
+}
+
+// toStatus converte o erro das regras de negócio em um erro gRPC. Erros inesperados são
+// registrados no log e retornados como Internal, sem detalhes.
+func toStatus(err error, internalMessage string) error {
+	var serviceErr *apierrors.Error
+	if errors.As(err, &serviceErr) {
+		code, known := httpStatusCodes[serviceErr.Status]
+		if !known {
+			code = codes.Unknown
+		}
+		return status.Error(code, serviceErr.Error())
+	}
+	log.Printf("%s: %v", internalMessage, err)
+	return status.Error(codes.Internal, internalMessage)
+}
+
+// optionalID converte um ID opcional do protobuf (0 ou ausente) em *uint.
+func optionalID(id *uint32) *uint {
+	if id == nil || *id == 0 {
+		return nil
+	}
+	value := uint(*id)
+	return &value
+}
+
+// trimmed remove espaços das strings de uma lista, descartando as vazias.
+func trimmed(values []string) []string {
+	var result []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
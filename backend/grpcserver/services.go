@@ -0,0 +1,212 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"personal-finance-app/backend/database"
+	"personal-finance-app/backend/financepb"
+	"personal-finance-app/backend/handlers"
+	"personal-finance-app/backend/middleware"
+	"personal-finance-app/backend/models"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+// authService implementa financepb.AuthServiceServer.
+type authService struct {
+	financepb.UnimplementedAuthServiceServer
+}
+
+// VerifyToken valida um token JWT e retorna o usuário a que ele pertence.
+func (s *authService) VerifyToken(ctx context.Context, req *financepb.VerifyTokenRequest) (*financepb.VerifyTokenResponse, error) {
+	claims, err := middleware.ParseToken(req.GetToken())
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 32)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "Invalid user ID format")
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, uint(userID)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Error(codes.Unauthenticated, "User not found")
+		}
+		return nil, toStatus(err, "Failed to fetch user")
+	}
+
+	response := &financepb.VerifyTokenResponse{UserId: uint32(user.ID), Email: user.Email}
+	if claims.ExpiresAt != nil {
+		response.ExpiresAt = timestamppb.New(claims.ExpiresAt.Time)
+	}
+	return response, nil
+}
+
+// expenseService implementa financepb.ExpenseServiceServer.
+type expenseService struct {
+	financepb.UnimplementedExpenseServiceServer
+}
+
+// toExpenseMessage converte a despesa da API HTTP em sua mensagem protobuf.
+func toExpenseMessage(expense handlers.ExpenseResponse) *financepb.Expense {
+	message := &financepb.Expense{
+		Id:          uint32(expense.ID),
+		UserId:      uint32(expense.UserID),
+		Value:       expense.Value,
+		Category:    expense.Category,
+		Description: expense.Description,
+		Date:        expense.Date,
+		Version:     uint32(expense.Version),
+		ClientId:    expense.ClientID,
+		CreatedAt:   timestamppb.New(expense.CreatedAt),
+		UpdatedAt:   timestamppb.New(expense.UpdatedAt),
+	}
+	if expense.HouseholdID != nil {
+		householdID := uint32(*expense.HouseholdID)
+		message.HouseholdId = &householdID
+	}
+	for _, split := range expense.Splits {
+		message.Splits = append(message.Splits, &financepb.ExpenseSplit{Category: split.Category, Value: split.Value, Note: split.Note})
+	}
+	return message
+}
+
+// CreateExpense registra uma despesa variável, como POST /expenses.
+func (s *expenseService) CreateExpense(ctx context.Context, req *financepb.CreateExpenseRequest) (*financepb.Expense, error) {
+	payload := handlers.CreateExpensePayload{
+		Value:       req.GetValue(),
+		Category:    req.GetCategory(),
+		Description: req.GetDescription(),
+		Date:        req.GetDate(),
+		HouseholdID: optionalID(req.HouseholdId),
+	}
+	for _, split := range req.GetSplits() {
+		payload.Splits = append(payload.Splits, handlers.ExpenseSplitPayload{Category: split.GetCategory(), Value: split.GetValue(), Note: split.GetNote()})
+	}
+
+	expense, err := handlers.CreateExpense(userIDFromContext(ctx), payload)
+	if err != nil {
+		return nil, toStatus(err, "Failed to save expense")
+	}
+	return toExpenseMessage(expense), nil
+}
+
+// ListExpenses lista as despesas variáveis, com os mesmos filtros e paginação de GET /expenses.
+func (s *expenseService) ListExpenses(ctx context.Context, req *financepb.ListExpensesRequest) (*financepb.ListExpensesResponse, error) {
+	filter := handlers.ExpenseFilter{
+		Categories: trimmed(req.GetCategories()),
+		MinValue:   req.MinValue,
+		MaxValue:   req.MaxValue,
+		Search:     req.GetSearch(),
+		Sort:       req.GetSort(),
+		Limit:      int(req.GetLimit()),
+		Cursor:     req.GetCursor(),
+	}
+	for name, field := range map[string]struct {
+		raw    string
+		target **time.Time
+	}{"from": {req.GetFrom(), &filter.From}, "to": {req.GetTo(), &filter.To}} {
+		if field.raw == "" {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", field.raw)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "Invalid '"+name+"' date format. Use YYYY-MM-DD.")
+		}
+		*field.target = &date
+	}
+
+	list, err := handlers.ListExpenses(userIDFromContext(ctx), optionalID(req.HouseholdId), filter)
+	if err != nil {
+		return nil, toStatus(err, "Failed to list expenses")
+	}
+	response := &financepb.ListExpensesResponse{HasMore: list.HasMore}
+	if list.NextCursor != nil {
+		response.NextCursor = *list.NextCursor
+	}
+	for _, expense := range list.Expenses {
+		response.Expenses = append(response.Expenses, toExpenseMessage(expense))
+	}
+	return response, nil
+}
+
+// DeleteExpense envia uma despesa variável para a lixeira, como DELETE /expenses/{id}.
+func (s *expenseService) DeleteExpense(ctx context.Context, req *financepb.DeleteExpenseRequest) (*financepb.DeleteExpenseResponse, error) {
+	if err := handlers.DeleteExpense(userIDFromContext(ctx), uint(req.GetId())); err != nil {
+		return nil, toStatus(err, "Failed to delete expense")
+	}
+	return &financepb.DeleteExpenseResponse{}, nil
+}
+
+// StreamNewExpenses envia as despesas criadas (por qualquer API, em qualquer instância) enquanto o
+// stream estiver aberto.
+func (s *expenseService) StreamNewExpenses(req *financepb.StreamNewExpensesRequest, stream financepb.ExpenseService_StreamNewExpensesServer) error {
+	events, unsubscribe, err := handlers.SubscribeNewExpenses(userIDFromContext(stream.Context()), optionalID(req.HouseholdId))
+	if err != nil {
+		return toStatus(err, "Failed to subscribe to new expenses")
+	}
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case expense, open := <-events:
+			if !open {
+				return nil
+			}
+			if err := stream.Send(toExpenseMessage(expense)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// balanceService implementa financepb.BalanceServiceServer.
+type balanceService struct {
+	financepb.UnimplementedBalanceServiceServer
+}
+
+// GetBalance calcula o saldo e a projeção, como GET /balance.
+func (s *balanceService) GetBalance(ctx context.Context, req *financepb.GetBalanceRequest) (*financepb.Balance, error) {
+	balance, err := handlers.GetBalance(userIDFromContext(ctx), optionalID(req.HouseholdId))
+	if err != nil {
+		return nil, toStatus(err, "Failed to compute balance")
+	}
+
+	response := &financepb.Balance{
+		CurrentBalance:             balance.CurrentBalance,
+		TotalIncome:                balance.TotalIncome,
+		TotalFixedExpenses:         balance.TotalFixedExpenses,
+		TotalVariableExpensesMonth: balance.TotalVariableExpenses,
+		FinancialHealthStatus:      balance.FinancialHealthStatus,
+		HealthPercentage:           balance.HealthPercentage,
+		CategoryTotals:             balance.CategoryTotals,
+	}
+	if projection := balance.Projection; projection != nil {
+		response.Projection = &financepb.Projection{
+			EndOfMonthBalance:         projection.EndOfMonthBalance,
+			ProjectedVariableExpenses: projection.ProjectedVariableExpenses,
+			ProjectedTotalExpenses:    projection.ProjectedTotalExpenses,
+			YellowAlertDay:            projection.YellowAlertDay,
+			RedAlertDay:               projection.RedAlertDay,
+			GmdVariableExpenses:       projection.GMDVariableExpenses,
+		}
+	}
+	for _, budget := range balance.Budgets {
+		response.Budgets = append(response.Budgets, &financepb.BudgetStatus{
+			Category:    budget.Category,
+			Limit:       budget.Limit,
+			Spent:       budget.Spent,
+			Remaining:   budget.Remaining,
+			PercentUsed: budget.PercentUsed,
+		})
+	}
+	return response, nil
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"personal-finance-app/backend/apierrors"
+	"personal-finance-app/backend/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Versões da API HTTP. Os handlers são os mesmos em todas as versões; só os corpos (DTOs) que
+// mudaram de formato são convertidos conforme a versão. As rotas sem prefixo (obsoletas) seguem a v1.
+const (
+	APIVersionV1 = "v1" // Formato original (campos do onboarding em português, modelos serializados diretamente)
+	APIVersionV2 = "v2" // Nomes de campos em inglês e camelCase em todas as rotas
+)
+
+// apiVersion retorna a versão da API da requisição, definida pelo APIVersionMiddleware (v1 por padrão).
+func apiVersion(c *gin.Context) string {
+	if version := c.GetString("apiVersion"); version != "" {
+		return version
+	}
+	return APIVersionV1
+}
+
+// IncomePayloadV2 é o corpo de POST /v2/onboarding/income
+type IncomePayloadV2 struct {
+	MonthlyIncome float64 `json:"monthlyIncome" binding:"required,gte=0"`
+	PayDay        int     `json:"payDay" binding:"omitempty,min=1,max=31"` // Opcional
+}
+
+// FixedExpensePayloadV2 é uma despesa fixa no corpo de POST /v2/onboarding/fixed-expenses
+type FixedExpensePayloadV2 struct {
+	Name     string  `json:"name" binding:"required"`
+	Value    float64 `json:"value" binding:"required,gt=0"`
+	Category string  `json:"category"`                                // Opcional (ex: vindo das sugestões do perfil)
+	DueDay   int     `json:"dueDay" binding:"omitempty,min=1,max=31"` // Opcional
+}
+
+// FixedExpensesPayloadV2 é o corpo de POST /v2/onboarding/fixed-expenses
+type FixedExpensesPayloadV2 struct {
+	Expenses []FixedExpensePayloadV2 `json:"fixedExpenses" binding:"required,dive"`
+}
+
+// InvitationResponse é a representação JSON de um convite para um domicílio na v2
+type InvitationResponse struct {
+	ID          uint       `json:"id"`
+	HouseholdID uint       `json:"householdId"`
+	Email       string     `json:"email"`
+	Role        string     `json:"role"`
+	InvitedByID uint       `json:"invitedById"`
+	ExpiresAt   time.Time  `json:"expiresAt"`
+	AcceptedAt  *time.Time `json:"acceptedAt"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+// bindIncomePayload lê o corpo de POST /onboarding/income no formato da versão da requisição.
+// Se o corpo for inválido, já responde 400 e retorna false.
+func bindIncomePayload(c *gin.Context) (IncomePayload, bool) {
+	var payload IncomePayload
+	var err error
+	if apiVersion(c) == APIVersionV2 {
+		var body IncomePayloadV2
+		err = c.ShouldBindJSON(&body)
+		payload = IncomePayload(body)
+	} else {
+		err = c.ShouldBindJSON(&payload)
+	}
+	if err != nil {
+		apierrors.RespondInvalid(c, err)
+		return payload, false
+	}
+	return payload, true
+}
+
+// bindFixedExpensesPayload lê o corpo de POST /onboarding/fixed-expenses no formato da versão da
+// requisição. Se o corpo for inválido, já responde 400 e retorna false.
+func bindFixedExpensesPayload(c *gin.Context) (FixedExpensesPayload, bool) {
+	var payload FixedExpensesPayload
+	var err error
+	if apiVersion(c) == APIVersionV2 {
+		var body FixedExpensesPayloadV2
+		if err = c.ShouldBindJSON(&body); err == nil {
+			payload.Expenses = make([]FixedExpensePayload, 0, len(body.Expenses))
+			for _, expense := range body.Expenses {
+				payload.Expenses = append(payload.Expenses, FixedExpensePayload(expense))
+			}
+		}
+	} else {
+		err = c.ShouldBindJSON(&payload)
+	}
+	if err != nil {
+		apierrors.RespondInvalid(c, err)
+		return payload, false
+	}
+	return payload, true
+}
+
+// incomeDTO retorna a renda no formato da versão da requisição.
+func incomeDTO(c *gin.Context, income models.Income) interface{} {
+	if apiVersion(c) == APIVersionV2 {
+		return toIncomeResponse(income)
+	}
+	return income
+}
+
+// fixedExpensesDTO retorna as despesas fixas no formato da versão da requisição.
+func fixedExpensesDTO(c *gin.Context, fixedExpenses []models.FixedExpense) interface{} {
+	if apiVersion(c) == APIVersionV2 {
+		response := make([]FixedExpenseResponse, 0, len(fixedExpenses))
+		for _, fixedExpense := range fixedExpenses {
+			response = append(response, toFixedExpenseResponse(fixedExpense))
+		}
+		return response
+	}
+	return fixedExpenses
+}
+
+// toInvitationResponse converte o modelo de convite para sua representação JSON na v2
+func toInvitationResponse(invitation models.HouseholdInvitation) InvitationResponse {
+	return InvitationResponse{
+		ID:          invitation.ID,
+		HouseholdID: invitation.HouseholdID,
+		Email:       invitation.Email,
+		Role:        invitation.Role,
+		InvitedByID: invitation.InvitedByID,
+		ExpiresAt:   invitation.ExpiresAt,
+		AcceptedAt:  invitation.AcceptedAt,
+		CreatedAt:   invitation.CreatedAt,
+	}
+}
+
+// invitationsDTO retorna os convites no formato da versão da requisição.
+func invitationsDTO(c *gin.Context, invitations []models.HouseholdInvitation) interface{} {
+	if apiVersion(c) == APIVersionV2 {
+		response := make([]InvitationResponse, 0, len(invitations))
+		for _, invitation := range invitations {
+			response = append(response, toInvitationResponse(invitation))
+		}
+		return response
+	}
+	return invitations
+}
+
+// invitationDTO retorna o convite no formato da versão da requisição.
+func invitationDTO(c *gin.Context, invitation models.HouseholdInvitation) interface{} {
+	if apiVersion(c) == APIVersionV2 {
+		return toInvitationResponse(invitation)
+	}
+	return invitation
+}
@@ -7,6 +7,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"personal-finance-app/backend/apierrors"
 	"personal-finance-app/backend/database"
 	"personal-finance-app/backend/models"
 	"regexp"
@@ -64,13 +65,13 @@ func checkDataHash(data, hash string) bool {
 func RequestCodeHandler(c *gin.Context) {
 	var body RequestCodeBody
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		apierrors.RespondInvalid(c, err)
 		return
 	}
 
 	// Validar formato do e-mail
 	if !EmailRegex.MatchString(body.Email) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email format"})
+		apierrors.Respond(c, http.StatusBadRequest, apierrors.InvalidEmail)
 		return
 	}
 
@@ -78,7 +79,7 @@ func RequestCodeHandler(c *gin.Context) {
 	code, err := generateSecureCode(6)
 	if err != nil {
 		log.Printf("Error generating secure code: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate authentication code"})
+		apierrors.RespondInternal(c)
 		return
 	}
 
@@ -86,7 +87,7 @@ func RequestCodeHandler(c *gin.Context) {
 	codeHash, err := hashData(code)
 	if err != nil {
 		log.Printf("Error hashing code: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to secure authentication code"})
+		apierrors.RespondInternal(c)
 		return
 	}
 
@@ -98,7 +99,7 @@ func RequestCodeHandler(c *gin.Context) {
 	}
 	if result := database.DB.Create(&authCodeEntry); result.Error != nil {
 		log.Printf("Error saving auth code to DB: %v", result.Error)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save authentication code"})
+		apierrors.RespondInternal(c)
 		return
 	}
 
@@ -112,36 +113,36 @@ func RequestCodeHandler(c *gin.Context) {
 func VerifyCodeHandler(c *gin.Context) {
 	var body VerifyCodeBody
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		apierrors.RespondInvalid(c, err)
 		return
 	}
 
 	// Validar formato do e-mail
 	if !EmailRegex.MatchString(body.Email) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email format"})
+		apierrors.Respond(c, http.StatusBadRequest, apierrors.InvalidEmail)
 		return
 	}
 	if len(body.Code) != 6 { // Assumindo que o código tem sempre 6 dígitos
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code format"})
+		apierrors.Respond(c, http.StatusBadRequest, apierrors.InvalidAuthCodeFormat)
 		return
 	}
 
 	// Buscar código no banco de dados
 	var authCodeEntry models.AuthCode
 	if result := database.DB.Where("email = ?", body.Email).Order("created_at desc").First(&authCodeEntry); result.Error != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or code. Code not found."})
+		apierrors.Respond(c, http.StatusUnauthorized, apierrors.AuthCodeNotFound)
 		return
 	}
 
 	// Verificar se o código expirou
 	if time.Now().After(authCodeEntry.ExpiresAt) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication code expired."})
+		apierrors.Respond(c, http.StatusUnauthorized, apierrors.AuthCodeExpired)
 		return
 	}
 
 	// Verificar o hash do código
 	if !checkDataHash(body.Code, authCodeEntry.CodeHash) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication code."})
+		apierrors.Respond(c, http.StatusUnauthorized, apierrors.AuthCodeInvalid)
 		return
 	}
 
@@ -149,7 +150,7 @@ func VerifyCodeHandler(c *gin.Context) {
 	var user models.User
 	if result := database.DB.Where("email = ?", body.Email).FirstOrCreate(&user, models.User{Email: body.Email}); result.Error != nil {
 		log.Printf("Error finding or creating user: %v", result.Error)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process user account"})
+		apierrors.RespondInternal(c)
 		return
 	}
 
@@ -179,7 +180,7 @@ func VerifyCodeHandler(c *gin.Context) {
 	tokenString, err := token.SignedString(jwtKey)
 	if err != nil {
 		log.Printf("Error generating JWT token: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate session token"})
+		apierrors.RespondInternal(c)
 		return
 	}
 
@@ -1,198 +1,318 @@
 package handlers
 
 import (
+	"errors"
 	"log"
-	"math"
 	"net/http"
+	"personal-finance-app/backend/apierrors"
 	"personal-finance-app/backend/database"
+	"personal-finance-app/backend/finance"
 	"personal-finance-app/backend/models"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
-type BalanceResponse struct {
-	CurrentBalance          float64     `json:"currentBalance"`
-	TotalIncome             float64     `json:"totalIncome"`
-	TotalFixedExpenses      float64     `json:"totalFixedExpenses"`
-	TotalVariableExpenses   float64     `json:"totalVariableExpensesMonth"`
-	Projection              *Projection `json:"projection,omitempty"`
-	FinancialHealthStatus   string      `json:"financialHealthStatus"` // "verde", "amarelo", "vermelho"
-	HealthPercentage        float64     `json:"healthPercentage"`
-	DaysInMonthForProjection int        `json:"daysInMonthForProjection,omitempty"` // Para debug/info
-	DayOfMonthForProjection  int        `json:"dayOfMonthForProjection,omitempty"`  // Para debug/info
+// Tipos do saldo, definidos no pacote finance junto com as regras de cálculo
+type (
+	BalanceResponse = finance.BalanceResponse
+	BudgetStatus    = finance.BudgetStatus
+	Projection      = finance.Projection
+)
+
+const (
+	balanceBaselineMonths   = 3  // Meses completos de gastos usados pelos modelos de previsão
+	defaultBalanceHistory   = 12 // Meses do histórico quando "from" não é informado
+	maxBalanceHistoryMonths = 36
+	defaultMonteCarloSeed   = 1 // A mesma semente mantém o resultado enquanto os dados não mudam
+)
+
+// Modos da projeção do saldo (?projection=)
+const (
+	projectionDeterministic = "deterministic" // Padrão: o gasto previsto pelo modelo do usuário
+	projectionMonteCarlo    = "monte_carlo"   // Também a projeção probabilística (finance.MonteCarlo)
+)
+
+// BalanceOptions ajusta o cálculo do saldo
+type BalanceOptions struct {
+	MonteCarlo bool  // Inclui a projeção probabilística em Projection.MonteCarlo
+	Seed       int64 // Semente das simulações
 }
 
-type Projection struct {
-	EndOfMonthBalance         float64 `json:"endOfMonthBalance"`
-	ProjectedVariableExpenses float64 `json:"projectedVariableExpenses"`
-	ProjectedTotalExpenses    float64 `json:"projectedTotalExpenses"`
-	YellowAlertDay            string  `json:"yellowAlertDay,omitempty"` // Data "YYYY-MM-DD" ou dia do mês
-	RedAlertDay               string  `json:"redAlertDay,omitempty"`    // Data "YYYY-MM-DD" ou dia do mês
-	GMDVariableExpenses       float64 `json:"gmdVariableExpenses"`    // Gasto Médio Diário de Despesas Variáveis
+// monthFromQuery lê um parâmetro de mês ("YYYY-MM"); sem o parâmetro, retorna fallback.
+// Em caso de falha, já responde à requisição e retorna false.
+func monthFromQuery(c *gin.Context, param string, fallback time.Time) (time.Time, bool) {
+	raw := c.Query(param)
+	if raw == "" {
+		return fallback, true
+	}
+	month, err := time.ParseInLocation(finance.MonthFormat, raw, time.Local)
+	if err != nil {
+		apierrors.Respond(c, http.StatusBadRequest, apierrors.InvalidMonth, param)
+		return month, false
+	}
+	return month, true
 }
 
 // GetBalanceHandler calcula e retorna o saldo atual e a projeção.
+// Com ?householdId=, calcula o saldo do domicílio: a soma das rendas dos membros
+// menos as despesas fixas e variáveis do domicílio. Com ?month=YYYY-MM, calcula o saldo de um
+// mês passado (realizado) ou futuro (projetado) em vez do mês corrente. Com
+// ?projection=monte_carlo (e, opcionalmente, ?seed=), inclui a projeção probabilística.
 func GetBalanceHandler(c *gin.Context) {
-	userIDStr, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	householdID, ok := householdIDFromQuery(c)
+	if !ok {
 		return
 	}
-	userID, err := strconv.ParseUint(userIDStr.(string), 10, 32)
+	month, ok := monthFromQuery(c, "month", time.Now())
+	if !ok {
+		return
+	}
+	options := BalanceOptions{Seed: defaultMonteCarloSeed}
+	switch c.DefaultQuery("projection", projectionDeterministic) {
+	case projectionDeterministic:
+	case projectionMonteCarlo:
+		options.MonteCarlo = true
+	default:
+		apierrors.Respond(c, http.StatusBadRequest, apierrors.InvalidParameter, "projection")
+		return
+	}
+	if raw := c.Query("seed"); raw != "" {
+		seed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			apierrors.Respond(c, http.StatusBadRequest, apierrors.InvalidParameter, "seed")
+			return
+		}
+		options.Seed = seed
+	}
+
+	response, err := GetMonthBalance(userID, householdID, month, options)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
+		respondServiceError(c, err, "Failed to compute balance")
 		return
 	}
 
-	// 1. Buscar Renda Mensal
-	var income models.Income
-	if err := database.DB.Where("user_id = ?", uint(userID)).First(&income).Error; err != nil {
-		// Se não houver renda cadastrada, podemos retornar um erro ou um valor padrão.
-		// Por enquanto, vamos assumir que o onboarding garantiu uma renda.
-		// Em um app real, tratar o caso de não haver renda.
-		log.Printf("Error fetching income for user %d: %v", userID, err)
-		c.JSON(http.StatusNotFound, gin.H{"error": "Income data not found for user. Please complete onboarding."})
+	c.JSON(http.StatusOK, response)
+}
+
+// GetBalanceHistoryHandler retorna o histórico mensal (?from=YYYY-MM&to=YYYY-MM, por padrão os
+// últimos 12 meses) de renda, despesas fixas e variáveis e saúde financeira.
+func GetBalanceHistoryHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	householdID, ok := householdIDFromQuery(c)
+	if !ok {
+		return
+	}
+	to, ok := monthFromQuery(c, "to", time.Now())
+	if !ok {
+		return
+	}
+	from, ok := monthFromQuery(c, "from", to.AddDate(0, 1-defaultBalanceHistory, 0))
+	if !ok {
 		return
 	}
-	totalIncome := income.MonthlyIncome
 
-	// 2. Buscar Total de Despesas Fixas
-	var fixedExpenses []models.FixedExpense
-	database.DB.Where("user_id = ?", uint(userID)).Find(&fixedExpenses)
-	totalFixedExpenses := 0.0
-	for _, fe := range fixedExpenses {
-		totalFixedExpenses += fe.Value
+	history, err := GetBalanceHistory(userID, householdID, from, to)
+	if err != nil {
+		respondServiceError(c, err, "Failed to compute balance history")
+		return
 	}
+	c.JSON(http.StatusOK, history)
+}
 
-	// 3. Buscar Total de Despesas Variáveis no Mês Corrente
-	now := time.Now()
-	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
-	endOfMonth := startOfMonth.AddDate(0, 1, -1) // Último dia do mês corrente
+// GetBalance calcula o saldo e a projeção do mês corrente do usuário ou, com householdID, de um
+// domicílio do qual ele é membro.
+func GetBalance(userID uint, householdID *uint) (BalanceResponse, error) {
+	return GetMonthBalance(userID, householdID, time.Now(), BalanceOptions{})
+}
 
-	var variableExpensesMonth []models.VariableExpense
-	database.DB.Where("user_id = ? AND date >= ? AND date <= ?", uint(userID), startOfMonth, now).Find(&variableExpensesMonth) // até o dia atual
+// GetMonthBalance calcula o saldo e a projeção de qualquer mês (ver finance.ComputeMonthBalance).
+func GetMonthBalance(userID uint, householdID *uint, month time.Time, options BalanceOptions) (BalanceResponse, error) {
+	if householdID != nil {
+		if _, err := authorizeHousehold(userID, *householdID, models.HouseholdRoleViewer); err != nil {
+			return BalanceResponse{}, authorizationServiceError(err, apierrors.HouseholdNotFound)
+		}
+	}
 
-	totalVariableExpensesMonth := 0.0
-	for _, ve := range variableExpensesMonth {
-		totalVariableExpensesMonth += ve.Value
+	response, err := computeMonthBalance(ownerScope{UserID: userID, HouseholdID: householdID}, month, options)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Se não houver renda cadastrada, podemos retornar um erro ou um valor padrão.
+		// Por enquanto, vamos assumir que o onboarding garantiu uma renda.
+		// Em um app real, tratar o caso de não haver renda.
+		log.Printf("Income not found for balance of user %d", userID)
+		return response, newServiceError(http.StatusNotFound, apierrors.IncomeNotFound)
 	}
+	return response, err
+}
 
-	// Calcular Saldo Atual
-	currentBalance := totalIncome - totalFixedExpenses - totalVariableExpensesMonth
+// BalanceHistory é a resposta de GET /balance/history
+type BalanceHistory struct {
+	Months      []finance.MonthSummary `json:"months"`
+	HealthRules finance.HealthRules    `json:"healthRules"`
+}
 
-	// Calcular Saúde Financeira (para cor de fundo e status)
-	healthPercentage := 0.0
-	if totalIncome > 0 {
-		// Net flow atual = Renda - Fixas - Variáveis do mês
-		netFlowCurrent := totalIncome - totalFixedExpenses - totalVariableExpensesMonth
-		healthPercentage = (netFlowCurrent / totalIncome) * 100
+// GetBalanceHistory resume o saldo mês a mês de from a to (ver finance.History).
+func GetBalanceHistory(userID uint, householdID *uint, from, to time.Time) (BalanceHistory, error) {
+	if months := finance.MonthsBetween(from, to); months < 1 || months > maxBalanceHistoryMonths {
+		return BalanceHistory{}, newServiceError(http.StatusBadRequest, apierrors.InvalidMonthRange, maxBalanceHistoryMonths)
+	}
+	if householdID != nil {
+		if _, err := authorizeHousehold(userID, *householdID, models.HouseholdRoleViewer); err != nil {
+			return BalanceHistory{}, authorizationServiceError(err, apierrors.HouseholdNotFound)
+		}
 	}
-	// Garante que não seja negativo para a lógica de cor do frontend que espera >= 0
-	// healthPercentage = math.Max(healthPercentage, 0)
 
-	financialHealthStatus := "vermelho" // Default
-	if healthPercentage > 60 {
-		financialHealthStatus = "verde"
-	} else if healthPercentage >= 25 {
-		financialHealthStatus = "amarelo"
+	scope := ownerScope{UserID: userID, HouseholdID: householdID}
+	incomes, err := scopeIncomes(scope)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return BalanceHistory{}, newServiceError(http.StatusNotFound, apierrors.IncomeNotFound)
 	}
+	if err != nil {
+		return BalanceHistory{}, err
+	}
+	settings, err := scopeSettings(scope)
+	if err != nil {
+		return BalanceHistory{}, err
+	}
+	rules := settingsRules(settings)
 
+	var fixedExpenses []models.FixedExpense
+	if err := scope.apply(database.DB).Where("active = ?", true).Find(&fixedExpenses).Error; err != nil {
+		return BalanceHistory{}, err
+	}
+	start, _ := finance.MonthRange(from)
+	end, _ := finance.MonthRange(to)
+	var variableExpenses []models.VariableExpense
+	err = scope.apply(database.DB).Where("date >= ? AND date < ?", start, end.AddDate(0, 1, 0)).Find(&variableExpenses).Error
+	if err != nil {
+		return BalanceHistory{}, err
+	}
+	contributions, err := goalContributionsBetween(scope, start, end.AddDate(0, 1, 0).Add(-time.Nanosecond))
+	if err != nil {
+		return BalanceHistory{}, err
+	}
+	return BalanceHistory{
+		Months:      finance.History(finance.TotalIncome(incomes), fixedExpenses, variableExpenses, contributions, from, to, rules),
+		HealthRules: rules.Definitions(),
+	}, nil
+}
 
-	var projectionData *Projection = nil
-	daysInMonthForProjection := 0
-	dayOfMonthForProjection := 0
+// computeBalance calcula o saldo do mês corrente do escopo (ver computeMonthBalance).
+func computeBalance(scope ownerScope) (BalanceResponse, error) {
+	return computeMonthBalance(scope, time.Now(), BalanceOptions{})
+}
 
+// computeMonthBalance carrega os dados do escopo e calcula (finance.ComputeMonthBalance) o saldo,
+// a projeção (e, se pedida, a projeção probabilística), já descontados os aportes em metas, e o uso
+// dos orçamentos do mês. Retorna
+// gorm.ErrRecordNotFound se o escopo não tiver renda cadastrada.
+func computeMonthBalance(scope ownerScope, month time.Time, options BalanceOptions) (BalanceResponse, error) {
+	incomes, err := scopeIncomes(scope)
+	if err != nil {
+		return BalanceResponse{}, err
+	}
+	settings, err := scopeSettings(scope) // No domicílio, as regras do dono, para qualquer membro
+	if err != nil {
+		return BalanceResponse{}, err
+	}
 
-	// Lógica de Projeção (se dia atual > 7 - ou seja, a partir do dia 8)
-	dayOfMonth := now.Day()
-	if dayOfMonth > 7 { // Condição: mais de 7 dias no mês (ou seja, a partir do dia 8)
-		daysInMonth := float64(endOfMonth.Day()) // Número de dias no mês corrente
-		daysInMonthForProjection = int(daysInMonth) // para debug
-		dayOfMonthForProjection = dayOfMonth // para debug
+	var fixedExpenses []models.FixedExpense
+	scope.apply(database.DB).Where("active = ?", true).Find(&fixedExpenses)
+	var recurring []models.RecurringTransaction
+	scope.apply(database.DB).Find(&recurring)
+
+	// Despesas variáveis do mês; no mês corrente, apenas até o momento atual
+	now := time.Now()
+	startOfMonth, _ := finance.MonthRange(month)
+	currentMonth, _ := finance.MonthRange(now)
+	end := startOfMonth.AddDate(0, 1, 0).Add(-time.Nanosecond)
+	if startOfMonth.Equal(currentMonth) {
+		end = now
+	}
+	var variableExpensesMonth []models.VariableExpense
+	scope.apply(database.DB.Preload("Splits")).Where("date >= ? AND date <= ?", startOfMonth, end).Find(&variableExpensesMonth)
+	// O que foi guardado em metas no mês não está mais disponível
+	contributions, err := goalContributionsBetween(scope, startOfMonth, end)
+	if err != nil {
+		return BalanceResponse{}, err
+	}
 
+	// Os modelos de previsão usam os gastos dos últimos meses completos (desnecessários em meses passados)
+	history := finance.SpendingHistory{}
+	if !startOfMonth.Before(currentMonth) {
+		history = spendingHistory(scope, now)
+	}
 
-		gmdVariableExpenses := 0.0
-		if dayOfMonth > 0 && totalVariableExpensesMonth > 0 { // Evita divisão por zero se não houver gastos ou no primeiro dia
-			gmdVariableExpenses = totalVariableExpensesMonth / float64(dayOfMonth)
+	response := finance.ComputeMonthBalance(incomes, fixedExpenses, recurring, variableExpensesMonth, contributions, month, history,
+		settingsForecaster(settings), settingsRules(settings), finance.FixedClock(now))
+	if options.MonteCarlo && response.Projection != nil {
+		simulation, ok := finance.MonteCarlo(incomes, fixedExpenses, recurring, variableExpensesMonth, contributions, month, history,
+			settingsRules(settings), finance.MonteCarloSimulations, options.Seed, finance.FixedClock(now))
+		if ok {
+			response.Projection.MonteCarlo = &simulation
 		}
+	}
 
-		projectedVariableExpensesMonth := gmdVariableExpenses * daysInMonth
-		projectedTotalExpensesMonth := projectedVariableExpensesMonth + totalFixedExpenses
-		projectedEndOfMonthBalance := totalIncome - projectedTotalExpensesMonth
+	// Orçamentos por categoria (pessoais), usando as linhas de divisão das despesas
+	response.CategoryTotals = categoryTotals(variableExpensesMonth)
+	response.Budgets = []BudgetStatus{}
+	if scope.HouseholdID == nil {
+		var budgets []models.Budget
+		database.DB.Where("user_id = ?", scope.UserID).Order("category").Find(&budgets)
+		response.Budgets = finance.BudgetUsage(budgets, response.CategoryTotals)
+	}
+	return response, nil
+}
 
-		projectionData = &Projection{
-			EndOfMonthBalance:         projectedEndOfMonthBalance,
-			ProjectedVariableExpenses: projectedVariableExpensesMonth,
-			ProjectedTotalExpenses:    projectedTotalExpensesMonth,
-			GMDVariableExpenses:       gmdVariableExpenses,
-		}
+// spendingHistory carrega as despesas variáveis do escopo nos balanceBaselineMonths meses completos
+// anteriores ao mês de now, usadas pelos modelos de previsão. Os dias antes da primeira despesa
+// registrada no escopo não entram no histórico: não são dias sem gasto, e sim dias sem registro.
+func spendingHistory(scope ownerScope, now time.Time) finance.SpendingHistory {
+	currentMonth, _ := finance.MonthRange(now)
+	history := finance.SpendingHistory{From: currentMonth.AddDate(0, -balanceBaselineMonths, 0), To: currentMonth}
+	var first models.VariableExpense
+	if err := scope.apply(database.DB.Unscoped()).Order("date").First(&first).Error; err != nil {
+		return finance.SpendingHistory{} // Sem despesas registradas, não há histórico
+	}
+	if firstDay := first.Date.In(now.Location()); firstDay.After(history.From) {
+		history.From = time.Date(firstDay.Year(), firstDay.Month(), firstDay.Day(), 0, 0, 0, 0, now.Location())
+	}
+	if !history.From.Before(history.To) {
+		return finance.SpendingHistory{}
+	}
+	scope.apply(database.DB).Where("date >= ? AND date < ?", history.From, history.To).Find(&history.Expenses)
+	return history
+}
 
-		// Estimativa de Dia para Alerta (Amarelo/Vermelho)
-		if gmdVariableExpenses > 0 { // Só faz sentido projetar se houver um gasto médio diário
-			currentSimBalance := totalIncome - totalFixedExpenses - totalVariableExpensesMonth
-
-			foundYellow := false
-			foundRed := false
-
-			// Simula para os dias restantes no mês
-			for d := dayOfMonth + 1; d <= int(daysInMonth); d++ {
-				currentSimBalance -= gmdVariableExpenses
-
-				// Calcula o percentual do saldo simulado em relação à renda
-				// para determinar o estado (verde, amarelo, vermelho)
-				// Saldo simulado aqui é o que sobraria da renda após todas as despesas fixas e variáveis até aquele dia.
-				// Para fins de alerta, o que importa é o "dinheiro que sobra da renda mensal"
-				// Então, o percentual é (currentSimBalance / totalIncome) * 100
-				// No entanto, a lógica de cor é sobre o que *sobra* da renda após *todas* as despesas.
-				// O saldo para alerta deve ser o saldo que o usuário teria no dia 'd'.
-				// O currentSimBalance já é o saldo líquido projetado para o dia 'd'.
-				// A questão é: o alerta é sobre o saldo absoluto ou sobre o percentual da renda que este saldo representa?
-				// O enunciado diz: "dia estimado em que o saldo ficará 'amarelo' ou 'vermelho'".
-				// As cores são definidas por faixas percentuais do (Receita - Despesas Fixas - Despesas Variáveis) / Receita.
-				// Então, precisamos calcular esse percentual para o saldo simulado.
-
-				// Saldo para o dia 'd' = Renda - Fixas - (Variáveis já ocorridas + Variáveis projetadas até 'd')
-				// Variáveis projetadas até 'd' = gmd * (d - diaAtual)
-				// Saldo no dia d = (Renda - Fixas - Variáveis_ja_ocorridas) - GMD * (d - diaAtual)
-				//                = currentBalance_inicial_simulacao - GMD * (d - diaAtual)
-				// currentSimBalance já é isso.
-
-				simHealthPercentage := 0.0
-				if totalIncome > 0 {
-					simHealthPercentage = (currentSimBalance / totalIncome) * 100
-				}
-				// simHealthPercentage = math.Max(simHealthPercentage, 0) // Para consistência com a lógica de cor
-
-				simDate := time.Date(now.Year(), now.Month(), d, 0, 0, 0, 0, now.Location())
-
-				if !foundYellow && simHealthPercentage < 60 { // Limite para amarelo
-					projectionData.YellowAlertDay = simDate.Format("2006-01-02")
-					foundYellow = true
-				}
-				if !foundRed && simHealthPercentage < 25 { // Limite para vermelho
-					projectionData.RedAlertDay = simDate.Format("2006-01-02")
-					foundRed = true
-				}
-				if foundYellow && foundRed {
-					break // Já encontrou ambos os alertas
-				}
-			}
+// scopeIncomes retorna as rendas do escopo: a renda do usuário ou, para um domicílio, as rendas de
+// todos os membros. Retorna gorm.ErrRecordNotFound se não houver renda.
+func scopeIncomes(scope ownerScope) ([]models.Income, error) {
+	if scope.HouseholdID == nil {
+		var income models.Income
+		if err := database.DB.Where("user_id = ?", scope.UserID).First(&income).Error; err != nil {
+			return nil, err
 		}
+		return []models.Income{income}, nil
 	}
 
-	response := BalanceResponse{
-		CurrentBalance:          currentBalance,
-		TotalIncome:             totalIncome,
-		TotalFixedExpenses:      totalFixedExpenses,
-		TotalVariableExpenses:   totalVariableExpensesMonth,
-		Projection:              projectionData,
-		FinancialHealthStatus:   financialHealthStatus,
-		HealthPercentage:        healthPercentage,
-		DaysInMonthForProjection: daysInMonthForProjection,
-		DayOfMonthForProjection:  dayOfMonthForProjection,
+	var incomes []models.Income
+	err := database.DB.Joins("JOIN household_members ON household_members.user_id = incomes.user_id").
+		Where("household_members.household_id = ?", *scope.HouseholdID).
+		Find(&incomes).Error
+	if err != nil {
+		return nil, err
 	}
-
-	c.JSON(http.StatusOK, response)
+	if len(incomes) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return incomes, nil
 }
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"personal-finance-app/backend/apierrors"
+	"personal-finance-app/backend/database"
+	"personal-finance-app/backend/finance"
+	"personal-finance-app/backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// BalanceSettingsPayload define o corpo de PUT /balance/settings
+type BalanceSettingsPayload struct {
+	GreenThreshold  *float64 `json:"greenThreshold" binding:"required"`
+	YellowThreshold *float64 `json:"yellowThreshold" binding:"required"`
+	Basis           string   `json:"basis" binding:"required,oneof=income savings_target"`
+	SavingsTarget   float64  `json:"savingsTarget" binding:"gte=0"`                                                              // Obrigatória com a base "savings_target"
+	ForecastModel   string   `json:"forecastModel" binding:"omitempty,oneof=month_average weighted_average weekday_seasonality"` // Padrão: "month_average"
+	TrimOutliers    bool     `json:"trimOutliers"`
+}
+
+// BalanceSettingsResponse são as regras da saúde financeira do usuário e o modelo de previsão dos gastos
+type BalanceSettingsResponse struct {
+	finance.HealthRules
+	ForecastModel string `json:"forecastModel"`
+	TrimOutliers  bool   `json:"trimOutliers"`
+}
+
+// balanceSettings retorna as configurações do saldo do usuário, ou as padrão se ele não configurou as suas.
+func balanceSettings(userID uint) (models.BalanceSettings, error) {
+	var settings models.BalanceSettings
+	err := database.DB.Where("user_id = ?", userID).First(&settings).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.BalanceSettings{
+			UserID:          userID,
+			GreenThreshold:  finance.DefaultRules.Green,
+			YellowThreshold: finance.DefaultRules.Yellow,
+			HealthBasis:     finance.DefaultRules.Basis,
+			ForecastModel:   finance.ForecastMonthAverage,
+		}, nil
+	}
+	return settings, err
+}
+
+// scopeSettings retorna as configurações do saldo do escopo: as do usuário no saldo pessoal e as do
+// dono no saldo de um domicílio, para que todos os membros e as verificações em segundo plano
+// (checkBalanceEvents) avaliem o domicílio com as mesmas regras.
+func scopeSettings(scope ownerScope) (models.BalanceSettings, error) {
+	if scope.HouseholdID == nil {
+		return balanceSettings(scope.UserID)
+	}
+	var household models.Household
+	if err := database.DB.Select("id", "owner_id").First(&household, *scope.HouseholdID).Error; err != nil {
+		return models.BalanceSettings{}, err
+	}
+	return balanceSettings(household.OwnerID)
+}
+
+func settingsRules(settings models.BalanceSettings) finance.Rules {
+	return finance.Rules{
+		Thresholds:    finance.Thresholds{Green: settings.GreenThreshold, Yellow: settings.YellowThreshold},
+		Basis:         settings.HealthBasis,
+		SavingsTarget: settings.SavingsTarget,
+	}
+}
+
+// settingsForecaster retorna o modelo de previsão escolhido; um modelo desconhecido vira o padrão.
+func settingsForecaster(settings models.BalanceSettings) finance.Forecaster {
+	forecaster, ok := finance.NewForecaster(settings.ForecastModel, settings.TrimOutliers)
+	if !ok {
+		forecaster, _ = finance.NewForecaster(finance.ForecastMonthAverage, settings.TrimOutliers)
+	}
+	return forecaster
+}
+
+func settingsResponse(settings models.BalanceSettings) BalanceSettingsResponse {
+	return BalanceSettingsResponse{
+		HealthRules:   settingsRules(settings).Definitions(),
+		ForecastModel: settings.ForecastModel,
+		TrimOutliers:  settings.TrimOutliers,
+	}
+}
+
+// GetBalanceSettingsHandler retorna as regras da saúde financeira do usuário, as faixas de cada
+// estado e o modelo de previsão. Elas valem para o saldo pessoal e para os dos domicílios que ele criou.
+func GetBalanceSettingsHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	settings, err := balanceSettings(userID)
+	if err != nil {
+		log.Printf("Error fetching balance settings for user %d: %v", userID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+	c.JSON(http.StatusOK, settingsResponse(settings))
+}
+
+// PutBalanceSettingsHandler salva as regras da saúde financeira e o modelo de previsão do usuário
+func PutBalanceSettingsHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var payload BalanceSettingsPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		apierrors.RespondInvalid(c, err)
+		return
+	}
+	if *payload.YellowThreshold >= *payload.GreenThreshold {
+		apierrors.Respond(c, http.StatusBadRequest, apierrors.InvalidThresholds)
+		return
+	}
+	if payload.Basis == finance.HealthBasisSavingsTarget && payload.SavingsTarget <= 0 {
+		apierrors.Respond(c, http.StatusBadRequest, apierrors.SavingsTargetRequired)
+		return
+	}
+	if payload.ForecastModel == "" {
+		payload.ForecastModel = finance.ForecastMonthAverage
+	}
+
+	settings := models.BalanceSettings{
+		GreenThreshold:  *payload.GreenThreshold,
+		YellowThreshold: *payload.YellowThreshold,
+		HealthBasis:     payload.Basis,
+		SavingsTarget:   payload.SavingsTarget,
+		ForecastModel:   payload.ForecastModel,
+		TrimOutliers:    payload.TrimOutliers,
+	}
+	err := database.DB.Where(models.BalanceSettings{UserID: userID}).
+		Assign(map[string]interface{}{
+			"green_threshold":  settings.GreenThreshold,
+			"yellow_threshold": settings.YellowThreshold,
+			"health_basis":     settings.HealthBasis,
+			"savings_target":   settings.SavingsTarget,
+			"forecast_model":   settings.ForecastModel,
+			"trim_outliers":    settings.TrimOutliers,
+		}).
+		FirstOrCreate(&settings).Error
+	if err != nil {
+		log.Printf("Error saving balance settings for user %d: %v", userID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+
+	c.JSON(http.StatusOK, settingsResponse(settings))
+}
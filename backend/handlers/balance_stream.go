@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"personal-finance-app/backend/apierrors"
+	"personal-finance-app/backend/database"
+	"personal-finance-app/backend/models"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// balanceStreamKeepAlive é o intervalo dos comentários enviados em streams sem alterações, para que
+// proxies não encerrem a conexão ociosa.
+const balanceStreamKeepAlive = 25 * time.Second
+
+// balanceSubscriber é um stream de saldo aberto (GET /balance/stream).
+type balanceSubscriber struct {
+	scope   ownerScope
+	changed chan struct{} // Capacidade 1: alterações seguidas resultam em um único recálculo
+}
+
+// balanceSubscribers são os streams de saldo abertos nesta instância.
+var balanceSubscribers = struct {
+	sync.Mutex
+	all map[*balanceSubscriber]struct{}
+}{all: make(map[*balanceSubscriber]struct{})}
+
+// subscribeBalanceChanges inscreve o escopo nas alterações que mudam o seu saldo. Retorna o canal
+// que sinaliza as alterações e a função que cancela a inscrição.
+func subscribeBalanceChanges(scope ownerScope) (<-chan struct{}, func()) {
+	subscriber := &balanceSubscriber{scope: scope, changed: make(chan struct{}, 1)}
+	balanceSubscribers.Lock()
+	balanceSubscribers.all[subscriber] = struct{}{}
+	balanceSubscribers.Unlock()
+
+	unsubscribe := func() {
+		balanceSubscribers.Lock()
+		delete(balanceSubscribers.all, subscriber)
+		balanceSubscribers.Unlock()
+	}
+	return subscriber.changed, unsubscribe
+}
+
+// notifyBalanceChange sinaliza os streams cujo saldo depende da linha alterada: o escopo pessoal do
+// dono para dados pessoais; o domicílio para dados do domicílio e, no caso da renda, todos os
+// domicílios de que o dono participa (a renda do domicílio é a soma das rendas dos membros).
+// Os domicílios do dono são consultados fora do lock dos inscritos, para que uma consulta lenta não
+// bloqueie novas inscrições. O canal de um inscrito nunca é fechado: sinalizar um stream que acabou
+// de cancelar a inscrição é inofensivo.
+func notifyBalanceChange(change database.Change) {
+	if change.Entity == models.SyncEntityCategory {
+		return
+	}
+
+	balanceSubscribers.Lock()
+	subscribers := make([]*balanceSubscriber, 0, len(balanceSubscribers.all))
+	for subscriber := range balanceSubscribers.all {
+		subscribers = append(subscribers, subscriber)
+	}
+	balanceSubscribers.Unlock()
+	if len(subscribers) == 0 {
+		return
+	}
+
+	var incomeHouseholds map[uint]bool // Domicílios do dono da renda alterada, buscados uma única vez
+	for _, subscriber := range subscribers {
+		affected := false
+		switch scope := subscriber.scope; {
+		case scope.HouseholdID == nil:
+			affected = change.HouseholdID == nil && change.UserID == scope.UserID
+		case change.HouseholdID != nil:
+			affected = *change.HouseholdID == *scope.HouseholdID
+		case change.Entity == models.SyncEntityIncome:
+			if incomeHouseholds == nil {
+				incomeHouseholds = memberHouseholds(change.UserID)
+			}
+			affected = incomeHouseholds[*scope.HouseholdID]
+		}
+		if !affected {
+			continue
+		}
+		select {
+		case subscriber.changed <- struct{}{}:
+		default: // Já há um recálculo pendente
+		}
+	}
+}
+
+// memberHouseholds retorna os domicílios de que o usuário é membro.
+func memberHouseholds(userID uint) map[uint]bool {
+	var householdIDs []uint
+	if err := database.DB.Model(&models.HouseholdMember{}).Where("user_id = ?", userID).Pluck("household_id", &householdIDs).Error; err != nil {
+		log.Printf("Error loading households of user %d: %v", userID, err)
+	}
+	households := make(map[uint]bool, len(householdIDs))
+	for _, id := range householdIDs {
+		households[id] = true
+	}
+	return households
+}
+
+// StartBalanceNotifications escuta, em segundo plano, as alterações publicadas pelo banco
+// (LISTEN/NOTIFY) e atualiza os streams de saldo abertos nesta instância, inclusive quando a
+// alteração foi feita por outra instância do backend.
+func StartBalanceNotifications() {
+	go database.ListenChanges(notifyBalanceChange)
+}
+
+// StreamBalanceHandler envia o saldo (o mesmo corpo do GET /balance) por Server-Sent Events: um
+// evento "balance" ao conectar e outro a cada alteração de renda, despesas fixas, despesas
+// variáveis, transações recorrentes ou aportes em metas do escopo, feita por qualquer membro. Se o
+// usuário perder o acesso ao escopo, envia um evento "error" com o corpo de erro padrão e encerra o
+// stream.
+func StreamBalanceHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	householdID, ok := householdIDFromQuery(c)
+	if !ok {
+		return
+	}
+
+	// Inscrição antes do primeiro cálculo, para não perder alterações feitas entre os dois
+	changes, unsubscribe := subscribeBalanceChanges(ownerScope{UserID: userID, HouseholdID: householdID})
+	defer unsubscribe()
+
+	response, err := GetBalance(userID, householdID)
+	if err != nil {
+		respondServiceError(c, err, "Failed to compute balance")
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Desativa o buffer do Nginx
+	c.Status(http.StatusOK)
+	c.SSEvent("balance", response)
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(balanceStreamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-keepAlive.C:
+			c.Writer.WriteString(": keep-alive\n\n")
+			c.Writer.Flush()
+		case <-changes:
+			response, err := GetBalance(userID, householdID)
+			var serviceErr *apierrors.Error
+			if errors.As(err, &serviceErr) {
+				c.SSEvent("error", apierrors.NewBody(c, serviceErr))
+				c.Writer.Flush()
+				return
+			}
+			if err != nil {
+				log.Printf("Failed to compute balance for stream of user %d: %v", userID, err)
+				continue
+			}
+			c.SSEvent("balance", response)
+			c.Writer.Flush()
+		}
+	}
+}
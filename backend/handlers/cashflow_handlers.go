@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"personal-finance-app/backend/apierrors"
+	"personal-finance-app/backend/database"
+	"personal-finance-app/backend/finance"
+	"personal-finance-app/backend/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	maxCashFlowDays       = 366
+	defaultForecastMonths = 12
+	maxForecastMonths     = 24
+)
+
+// CashFlowResponse é a resposta de GET /cashflow
+type CashFlowResponse struct {
+	Days        []finance.CashFlowDay `json:"days"`
+	HealthRules finance.HealthRules   `json:"healthRules"`
+}
+
+// ForecastResponse é a resposta de GET /forecast
+type ForecastResponse struct {
+	ForecastModel string                  `json:"forecastModel"` // Modelo de previsão das despesas variáveis
+	Months        []finance.MonthForecast `json:"months"`
+}
+
+// dateFromQuery lê um parâmetro de data ("YYYY-MM-DD"); sem o parâmetro, retorna fallback.
+// Se o valor for inválido, já responde 400 e retorna false.
+func dateFromQuery(c *gin.Context, param string, fallback time.Time) (time.Time, bool) {
+	raw := c.Query(param)
+	if raw == "" {
+		return fallback, true
+	}
+	date, err := time.ParseInLocation("2006-01-02", raw, time.Local)
+	if err != nil {
+		apierrors.Respond(c, http.StatusBadRequest, apierrors.InvalidDate, param)
+		return date, false
+	}
+	return date, true
+}
+
+// GetCashFlowHandler retorna o saldo projetado dia a dia (?from=YYYY-MM-DD&to=YYYY-MM-DD, por padrão
+// o mês corrente), com as rendas no dia do pagamento, as despesas fixas no vencimento e as transações
+// recorrentes em cada ocorrência.
+func GetCashFlowHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	householdID, ok := householdIDFromQuery(c)
+	if !ok {
+		return
+	}
+	startOfMonth, lastDay := finance.MonthRange(time.Now())
+	from, ok := dateFromQuery(c, "from", startOfMonth)
+	if !ok {
+		return
+	}
+	to, ok := dateFromQuery(c, "to", lastDay)
+	if !ok {
+		return
+	}
+
+	response, err := GetCashFlow(userID, householdID, from, to)
+	if err != nil {
+		respondServiceError(c, err, "Failed to compute cash flow")
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetForecastHandler retorna a previsão dos próximos meses (?months=, por padrão 12, até 24), a partir
+// do mês corrente: fluxo líquido e saldo acumulado de cada mês, marcando os meses em que ele fica negativo.
+func GetForecastHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	householdID, ok := householdIDFromQuery(c)
+	if !ok {
+		return
+	}
+	months := defaultForecastMonths
+	if raw := c.Query("months"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			apierrors.Respond(c, http.StatusBadRequest, apierrors.InvalidParameter, "months")
+			return
+		}
+		months = parsed
+	}
+
+	response, err := GetForecast(userID, householdID, months)
+	if err != nil {
+		respondServiceError(c, err, "Failed to compute forecast")
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetCashFlow projeta o saldo dia a dia de from a to (ver finance.CashFlow) do usuário ou, com
+// householdID, de um domicílio do qual ele é membro.
+func GetCashFlow(userID uint, householdID *uint, from, to time.Time) (CashFlowResponse, error) {
+	if days := int(to.Sub(from).Hours()/24+0.5) + 1; days < 1 || days > maxCashFlowDays {
+		return CashFlowResponse{}, newServiceError(http.StatusBadRequest, apierrors.InvalidDateRange, maxCashFlowDays)
+	}
+	now := time.Now()
+	inputs, err := loadCashFlowInputs(userID, householdID, from, to, now)
+	if err != nil {
+		return CashFlowResponse{}, err
+	}
+
+	rules := settingsRules(inputs.settings)
+	return CashFlowResponse{
+		Days: finance.CashFlow(inputs.incomes, inputs.fixedExpenses, inputs.recurring, inputs.variableExpenses, inputs.goalContributions, from, to, inputs.history,
+			settingsForecaster(inputs.settings), rules, finance.FixedClock(now)),
+		HealthRules: rules.Definitions(),
+	}, nil
+}
+
+// GetForecast prevê os próximos months meses (ver finance.ForecastMonths) do usuário ou, com
+// householdID, de um domicílio do qual ele é membro.
+func GetForecast(userID uint, householdID *uint, months int) (ForecastResponse, error) {
+	if months < 1 || months > maxForecastMonths {
+		return ForecastResponse{}, newServiceError(http.StatusBadRequest, apierrors.InvalidParameter, "months")
+	}
+	now := time.Now()
+	start, _ := finance.MonthRange(now)
+	_, lastDay := finance.MonthRange(start.AddDate(0, months-1, 0))
+	inputs, err := loadCashFlowInputs(userID, householdID, start, lastDay, now)
+	if err != nil {
+		return ForecastResponse{}, err
+	}
+
+	return ForecastResponse{
+		ForecastModel: inputs.settings.ForecastModel,
+		Months: finance.ForecastMonths(inputs.incomes, inputs.fixedExpenses, inputs.recurring, inputs.variableExpenses, inputs.goalContributions, months, inputs.history,
+			settingsForecaster(inputs.settings), settingsRules(inputs.settings), finance.FixedClock(now)),
+	}, nil
+}
+
+// cashFlowInputs são os dados do escopo usados no fluxo de caixa
+type cashFlowInputs struct {
+	incomes           []models.Income
+	fixedExpenses     []models.FixedExpense
+	recurring         []models.RecurringTransaction
+	variableExpenses  []models.VariableExpense  // Dos meses de from a to, inteiros
+	goalContributions []models.GoalContribution // Dos meses de from a to, inteiros
+	history           finance.SpendingHistory   // Vazio se o período terminar antes do mês corrente
+	settings          models.BalanceSettings
+}
+
+// loadCashFlowInputs verifica o acesso ao domicílio e carrega os dados do fluxo de caixa de from a to.
+func loadCashFlowInputs(userID uint, householdID *uint, from, to, now time.Time) (cashFlowInputs, error) {
+	if householdID != nil {
+		if _, err := authorizeHousehold(userID, *householdID, models.HouseholdRoleViewer); err != nil {
+			return cashFlowInputs{}, authorizationServiceError(err, apierrors.HouseholdNotFound)
+		}
+	}
+
+	var inputs cashFlowInputs
+	var err error
+	scope := ownerScope{UserID: userID, HouseholdID: householdID}
+	inputs.incomes, err = scopeIncomes(scope)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return inputs, newServiceError(http.StatusNotFound, apierrors.IncomeNotFound)
+	}
+	if err != nil {
+		return inputs, err
+	}
+	if inputs.settings, err = scopeSettings(scope); err != nil {
+		return inputs, err
+	}
+
+	if err := scope.apply(database.DB).Where("active = ?", true).Find(&inputs.fixedExpenses).Error; err != nil {
+		return inputs, err
+	}
+	if err := scope.apply(database.DB).Find(&inputs.recurring).Error; err != nil {
+		return inputs, err
+	}
+	// Meses inteiros: o saldo de cada mês começa no dia 1
+	start, _ := finance.MonthRange(from)
+	end, _ := finance.MonthRange(to)
+	err = scope.apply(database.DB).Where("date >= ? AND date < ?", start, end.AddDate(0, 1, 0)).Find(&inputs.variableExpenses).Error
+	if err != nil {
+		return inputs, err
+	}
+	if inputs.goalContributions, err = goalContributionsBetween(scope, start, end.AddDate(0, 1, 0).Add(-time.Nanosecond)); err != nil {
+		return inputs, err
+	}
+
+	if currentMonth, _ := finance.MonthRange(now); !end.Before(currentMonth) {
+		inputs.history = spendingHistory(scope, now)
+	}
+	return inputs, nil
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"personal-finance-app/backend/apierrors"
+	"personal-finance-app/backend/database"
+	"personal-finance-app/backend/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultExpenseBatchMaxSize é o tamanho máximo de um lote quando EXPENSE_BATCH_MAX_SIZE não está definida.
+const defaultExpenseBatchMaxSize = 100
+
+// Status possíveis de cada item de um lote
+const (
+	batchItemCreated   = "created"
+	batchItemInvalid   = "invalid"
+	batchItemDuplicate = "duplicate"
+)
+
+// BatchExpenseItem é um item do lote: os mesmos campos de CreateExpensePayload,
+// mais um identificador opcional gerado pelo cliente para detectar reenvios.
+type BatchExpenseItem struct {
+	CreateExpensePayload
+	ClientID string `json:"clientId" binding:"omitempty,max=100"`
+}
+
+// BatchExpensesPayload define a estrutura para criar várias despesas variáveis de uma vez.
+// Os itens são recebidos sem validação para que cada um seja validado individualmente.
+type BatchExpensesPayload struct {
+	Expenses []json.RawMessage `json:"expenses" binding:"required,min=1"`
+}
+
+// BatchItemResult é o resultado do processamento de um item do lote
+type BatchItemResult struct {
+	Index               int              `json:"index"`
+	ClientID            string           `json:"clientId,omitempty"`
+	Status              string           `json:"status"` // "created", "invalid" ou "duplicate"
+	apierrors.ItemError                  // Motivo, quando inválido ou repetido no lote
+	ExpenseID           uint             `json:"expenseId,omitempty"` // Despesa criada ou já existente (duplicata)
+	Expense             *ExpenseResponse `json:"expense,omitempty"`   // Despesa criada
+}
+
+// expenseBatchMaxSize lê o tamanho máximo do lote da variável de ambiente EXPENSE_BATCH_MAX_SIZE.
+func expenseBatchMaxSize() int {
+	if raw := os.Getenv("EXPENSE_BATCH_MAX_SIZE"); raw != "" {
+		if size, err := strconv.Atoi(raw); err == nil && size > 0 {
+			return size
+		}
+		log.Printf("Warning: invalid EXPENSE_BATCH_MAX_SIZE %q, using %d", raw, defaultExpenseBatchMaxSize)
+	}
+	return defaultExpenseBatchMaxSize
+}
+
+// PostExpensesBatchHandler registra várias despesas variáveis de uma vez (ex: sincronização após
+// período offline). Cada item é validado individualmente; os válidos são gravados em uma única
+// transação e a resposta traz o status de cada item, na mesma ordem do envio.
+func PostExpensesBatchHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var payload BatchExpensesPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		apierrors.RespondInvalid(c, err)
+		return
+	}
+	if maxSize := expenseBatchMaxSize(); len(payload.Expenses) > maxSize {
+		apierrors.Respond(c, http.StatusRequestEntityTooLarge, apierrors.TooManyItems, maxSize)
+		return
+	}
+
+	results := make([]BatchItemResult, len(payload.Expenses))
+	expenses := make([]*models.VariableExpense, len(payload.Expenses))
+	seenClientIDs := make(map[string]int)
+	duplicateOf := make(map[int]int) // Índice do item repetido -> índice da primeira ocorrência no lote
+	now := time.Now()
+
+	// 1. Validação individual de cada item
+	for i, raw := range payload.Expenses {
+		results[i] = BatchItemResult{Index: i}
+
+		var item BatchExpenseItem
+		if err := json.Unmarshal(raw, &item); err != nil {
+			results[i].Status, results[i].ItemError = batchItemInvalid, apierrors.NewItemError(c, apierrors.Invalid(err))
+			continue
+		}
+		results[i].ClientID = item.ClientID
+		if err := binding.Validator.ValidateStruct(&item); err != nil {
+			results[i].Status, results[i].ItemError = batchItemInvalid, apierrors.NewItemError(c, apierrors.Invalid(err))
+			continue
+		}
+		if item.HouseholdID != nil {
+			if _, err := authorizeHousehold(userID, *item.HouseholdID, models.HouseholdRoleEditor); err != nil {
+				var reason *apierrors.Error
+				if !errors.As(authorizationServiceError(err, apierrors.HouseholdNotFound), &reason) {
+					reason = apierrors.New(http.StatusNotFound, apierrors.HouseholdNotFound)
+				}
+				results[i].Status, results[i].ItemError = batchItemInvalid, apierrors.NewItemError(c, reason)
+				continue
+			}
+		}
+
+		expense := &models.VariableExpense{UserID: userID, HouseholdID: item.HouseholdID}
+		if err := applyExpensePayload(expense, item.CreateExpensePayload, now); err != nil {
+			results[i].Status, results[i].ItemError = batchItemInvalid, apierrors.NewItemError(c, err)
+			continue
+		}
+
+		// Reenvio do mesmo item dentro do próprio lote
+		if item.ClientID != "" {
+			if first, seen := seenClientIDs[item.ClientID]; seen {
+				results[i].Status = batchItemDuplicate
+				results[i].ItemError = apierrors.NewItemError(c, apierrors.New(http.StatusConflict, apierrors.DuplicateBatchItem, first))
+				duplicateOf[i] = first
+				continue
+			}
+			seenClientIDs[item.ClientID] = i
+			clientRef := item.ClientID
+			expense.ClientRef = &clientRef
+		}
+		expenses[i] = expense
+	}
+
+	// 2. Gravação dos itens válidos em uma única transação
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		for i, expense := range expenses {
+			if expense == nil {
+				continue
+			}
+			splits := expense.Splits
+			// ON CONFLICT DO NOTHING: um clientId já gravado (ex: lote reenviado, mesmo que na lixeira) vira "duplicate"
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Omit("Splits").Create(expense)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 && expense.ClientRef != nil {
+				var existing models.VariableExpense
+				if err := tx.Unscoped().Select("id").Where("user_id = ? AND client_ref = ?", userID, *expense.ClientRef).First(&existing).Error; err != nil {
+					return err
+				}
+				results[i].Status, results[i].ExpenseID = batchItemDuplicate, existing.ID
+				continue
+			}
+
+			for j := range splits {
+				splits[j].VariableExpenseID = expense.ID
+			}
+			if len(splits) > 0 {
+				if err := tx.Create(&splits).Error; err != nil {
+					return err
+				}
+			}
+			expense.Splits = splits
+
+			response := toExpenseResponse(*expense)
+			results[i].Status, results[i].ExpenseID, results[i].Expense = batchItemCreated, expense.ID, &response
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error saving expense batch for user %d: %v", userID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+
+	var created []models.VariableExpense
+	for _, result := range results {
+		if result.Status == batchItemCreated {
+			created = append(created, *expenses[result.Index])
+		}
+	}
+	publishNewExpenses(created...)
+
+	for i, first := range duplicateOf {
+		results[i].ExpenseID = results[first].ExpenseID
+	}
+
+	summary := map[string]int{batchItemCreated: 0, batchItemInvalid: 0, batchItemDuplicate: 0}
+	for _, result := range results {
+		summary[result.Status]++
+	}
+	c.JSON(http.StatusOK, gin.H{"results": results, "summary": summary})
+}
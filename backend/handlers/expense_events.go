@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"personal-finance-app/backend/apierrors"
+	"personal-finance-app/backend/database"
+	"personal-finance-app/backend/models"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// expenseEventBuffer é quantas despesas novas podem aguardar a leitura de um inscrito
+// antes que as seguintes sejam descartadas.
+const expenseEventBuffer = 32
+
+// expenseSubscriber recebe as despesas novas visíveis a um usuário, opcionalmente restritas a um domicílio.
+type expenseSubscriber struct {
+	userID      uint
+	householdID *uint
+	events      chan ExpenseResponse
+}
+
+// expenseSubscribers são os inscritos em novas despesas desta instância (ex: streams gRPC abertos).
+var expenseSubscribers = struct {
+	sync.Mutex
+	all map[*expenseSubscriber]struct{}
+}{all: make(map[*expenseSubscriber]struct{})}
+
+// SubscribeNewExpenses inscreve o usuário nas despesas criadas a partir de agora que ele pode ver
+// (pessoais e dos domicílios de que participa) ou, se householdID for informado, apenas nas do
+// domicílio. Retorna o canal de despesas e a função que cancela a inscrição (e fecha o canal).
+func SubscribeNewExpenses(userID uint, householdID *uint) (<-chan ExpenseResponse, func(), error) {
+	if householdID != nil {
+		if _, err := authorizeHousehold(userID, *householdID, models.HouseholdRoleViewer); err != nil {
+			return nil, nil, authorizationServiceError(err, apierrors.HouseholdNotFound)
+		}
+	}
+
+	subscriber := &expenseSubscriber{userID: userID, householdID: householdID, events: make(chan ExpenseResponse, expenseEventBuffer)}
+	expenseSubscribers.Lock()
+	expenseSubscribers.all[subscriber] = struct{}{}
+	expenseSubscribers.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			expenseSubscribers.Lock()
+			delete(expenseSubscribers.all, subscriber)
+			expenseSubscribers.Unlock()
+			close(subscriber.events)
+		})
+	}
+	return subscriber.events, unsubscribe, nil
+}
+
+// publishNewExpenses notifica as despesas recém-criadas aos webhooks (expense.created). Deve ser
+// chamada uma única vez, pela instância que criou as despesas, depois de a criação ter sido
+// confirmada no banco. Os inscritos de todas as instâncias as recebem pelo LISTEN/NOTIFY (ver
+// StartExpenseNotifications).
+func publishNewExpenses(expenses ...models.VariableExpense) {
+	go queueExpenseWebhooks(models.WebhookEventExpenseCreated, expenses)
+}
+
+// StartExpenseNotifications escuta, em segundo plano, as alterações publicadas pelo banco
+// (LISTEN/NOTIFY) e entrega as despesas criadas aos inscritos desta instância, inclusive quando a
+// despesa foi criada por outra instância do backend.
+func StartExpenseNotifications() {
+	go database.ListenChanges(notifyNewExpense)
+}
+
+// notifyNewExpense carrega a despesa inserida na alteração e a entrega aos inscritos que podem vê-la.
+func notifyNewExpense(change database.Change) {
+	if change.Entity != models.SyncEntityVariableExpense || !change.Created || change.EntityID == 0 {
+		return
+	}
+	expenseSubscribers.Lock()
+	subscribed := len(expenseSubscribers.all) > 0
+	expenseSubscribers.Unlock()
+	if !subscribed {
+		return
+	}
+
+	var expense models.VariableExpense
+	if err := database.DB.Preload("Splits").First(&expense, change.EntityID).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) { // Removida logo depois de criada
+			log.Printf("Error loading new expense %d: %v", change.EntityID, err)
+		}
+		return
+	}
+	broadcastNewExpense(expense)
+}
+
+// broadcastNewExpense entrega a despesa recém-criada aos inscritos desta instância que podem vê-la.
+// Os membros do domicílio são consultados uma única vez e fora do lock dos inscritos, para que
+// consultas lentas não bloqueiem novas inscrições nem outras entregas.
+func broadcastNewExpense(expense models.VariableExpense) {
+	expenseSubscribers.Lock()
+	subscribers := make([]*expenseSubscriber, 0, len(expenseSubscribers.all))
+	for subscriber := range expenseSubscribers.all {
+		subscribers = append(subscribers, subscriber)
+	}
+	expenseSubscribers.Unlock()
+	if len(subscribers) == 0 {
+		return
+	}
+
+	var members map[uint]bool // Membros do domicílio da despesa, buscados uma única vez
+	response := toExpenseResponse(expense)
+	for _, subscriber := range subscribers {
+		if subscriber.householdID != nil && (expense.HouseholdID == nil || *expense.HouseholdID != *subscriber.householdID) {
+			continue
+		}
+		if expense.HouseholdID == nil {
+			if expense.UserID != subscriber.userID {
+				continue
+			}
+		} else {
+			if members == nil {
+				members = householdMembers(*expense.HouseholdID)
+			}
+			if !members[subscriber.userID] {
+				continue
+			}
+		}
+		deliverNewExpense(subscriber, expense.ID, response)
+	}
+}
+
+// deliverNewExpense envia a despesa ao inscrito sem bloquear, descartando-a se o buffer estiver cheio.
+// O inscrito pode ter cancelado a inscrição (e fechado o canal) depois de copiado para a entrega.
+func deliverNewExpense(subscriber *expenseSubscriber, expenseID uint, response ExpenseResponse) {
+	expenseSubscribers.Lock()
+	defer expenseSubscribers.Unlock()
+	if _, ok := expenseSubscribers.all[subscriber]; !ok {
+		return
+	}
+	select {
+	case subscriber.events <- response:
+	default:
+		log.Printf("Dropping new expense %d for slow subscriber of user %d", expenseID, subscriber.userID)
+	}
+}
+
+// householdMembers retorna os usuários que participam do domicílio, se ele não tiver sido removido.
+// Qualquer papel permite ver as despesas do domicílio.
+func householdMembers(householdID uint) map[uint]bool {
+	var userIDs []uint
+	err := database.DB.Model(&models.HouseholdMember{}).
+		Joins("JOIN households ON households.id = household_members.household_id AND households.deleted_at IS NULL").
+		Where("household_members.household_id = ?", householdID).
+		Pluck("household_members.user_id", &userIDs).Error
+	if err != nil {
+		log.Printf("Error loading members of household %d: %v", householdID, err)
+	}
+	members := make(map[uint]bool, len(userIDs))
+	for _, id := range userIDs {
+		members[id] = true
+	}
+	return members
+}
+
+// publishDeletedExpense notifica aos webhooks (expense.deleted) uma despesa enviada para a lixeira.
+// Deve ser chamada depois de a remoção ter sido confirmada no banco.
+func publishDeletedExpense(expense models.VariableExpense) {
+	go queueExpenseWebhooks(models.WebhookEventExpenseDeleted, []models.VariableExpense{expense})
+}
@@ -1,104 +1,477 @@
 package handlers
 
 import (
+	"errors"
+	"log"
+	"math"
 	"net/http"
+	"personal-finance-app/backend/apierrors"
 	"personal-finance-app/backend/database"
 	"personal-finance-app/backend/models"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
 )
 
+// splitTolerance é a diferença máxima aceita entre a soma das linhas e o valor da despesa (meio centavo).
+const splitTolerance = 0.005
+
+// ExpenseSplitPayload define uma linha de divisão de uma despesa variável
+type ExpenseSplitPayload struct {
+	Category string  `json:"category" binding:"required"`
+	Value    float64 `json:"value" binding:"required,gt=0"`
+	Note     string  `json:"note"` // Opcional
+}
+
 // CreateExpensePayload define a estrutura para criar uma nova despesa variável
 type CreateExpensePayload struct {
-	Value       float64 `json:"value" binding:"required,gt=0"`
-	Category    string  `json:"category" binding:"required"`
-	Description string  `json:"description"` // Opcional
-	Date        string  `json:"date"`        // Opcional, formato "YYYY-MM-DD"
+	Value       float64               `json:"value" binding:"required,gt=0"`
+	Category    string                `json:"category" binding:"required_without=Splits"` // Opcional se houver linhas de divisão
+	Description string                `json:"description"`                                // Opcional
+	Date        string                `json:"date"`                                       // Opcional, formato "YYYY-MM-DD"
+	Splits      []ExpenseSplitPayload `json:"splits" binding:"omitempty,dive"`            // Opcional, divisão entre categorias
+	HouseholdID *uint                 `json:"householdId"`                                // Opcional, registra a despesa no domicílio
+}
+
+// PatchExpensePayload define a estrutura para alterar parcialmente uma despesa variável.
+// Campos ausentes mantêm o valor atual; "splits": [] remove a divisão entre categorias.
+type PatchExpensePayload struct {
+	Value       *float64               `json:"value"`
+	Category    *string                `json:"category"`
+	Description *string                `json:"description"`
+	Date        *string                `json:"date"` // Formato "YYYY-MM-DD"
+	Splits      *[]ExpenseSplitPayload `json:"splits"`
+}
+
+// ExpenseSplitResponse é a representação JSON de uma linha de divisão
+type ExpenseSplitResponse struct {
+	Category string  `json:"category"`
+	Value    float64 `json:"value"`
+	Note     string  `json:"note"`
+}
+
+// ExpenseResponse é a representação JSON estável de uma despesa variável retornada pela API.
+// Evita expor diretamente o modelo do GORM (e seus relacionamentos, como User).
+type ExpenseResponse struct {
+	ID          uint                   `json:"id"`
+	UserID      uint                   `json:"userId"`
+	HouseholdID *uint                  `json:"householdId"`
+	Value       float64                `json:"value"`
+	Category    string                 `json:"category"`
+	Description string                 `json:"description"`
+	Date        string                 `json:"date"` // Formato "YYYY-MM-DD"
+	Splits      []ExpenseSplitResponse `json:"splits"`
+	Version     uint                   `json:"version"`  // Também enviada no cabeçalho ETag
+	ClientID    *string                `json:"clientId"` // Identificador do cliente, se enviado em lote
+	CreatedAt   time.Time              `json:"createdAt"`
+	UpdatedAt   time.Time              `json:"updatedAt"`
+}
+
+// toExpenseResponse converte o modelo em sua representação JSON.
+func toExpenseResponse(expense models.VariableExpense) ExpenseResponse {
+	splits := make([]ExpenseSplitResponse, 0, len(expense.Splits))
+	for _, split := range expense.Splits {
+		splits = append(splits, ExpenseSplitResponse{Category: split.Category, Value: split.Value, Note: split.Note})
+	}
+	return ExpenseResponse{
+		ID:          expense.ID,
+		UserID:      expense.UserID,
+		HouseholdID: expense.HouseholdID,
+		Value:       expense.Value,
+		Category:    expense.Category,
+		Description: expense.Description,
+		Date:        expense.Date.Format("2006-01-02"),
+		Splits:      splits,
+		Version:     expense.Version,
+		ClientID:    expense.ClientRef,
+		CreatedAt:   expense.CreatedAt,
+		UpdatedAt:   expense.UpdatedAt,
+	}
+}
+
+// validateSplits verifica se as linhas de divisão somam o valor total da despesa.
+func validateSplits(total float64, splits []ExpenseSplitPayload) *apierrors.Error {
+	if len(splits) == 0 {
+		return nil
+	}
+	sum := 0.0
+	for _, split := range splits {
+		sum += split.Value
+	}
+	if math.Abs(sum-total) > splitTolerance {
+		return apierrors.New(http.StatusBadRequest, apierrors.InvalidSplits, sum, total)
+	}
+	return nil
+}
+
+// buildSplits converte as linhas do payload em modelos e retorna a categoria principal da despesa,
+// que é a categoria informada ou, na falta dela, a categoria da maior linha.
+func buildSplits(category string, splits []ExpenseSplitPayload) ([]models.ExpenseSplit, string) {
+	var lines []models.ExpenseSplit
+	mainCategory, largest := category, 0.0
+	for _, split := range splits {
+		lines = append(lines, models.ExpenseSplit{
+			Category: split.Category,
+			Value:    split.Value,
+			Note:     split.Note,
+		})
+		if category == "" && split.Value > largest {
+			mainCategory, largest = split.Category, split.Value
+		}
+	}
+	return lines, mainCategory
+}
+
+// applyExpensePayload valida datas e divisões do payload e preenche os campos editáveis da despesa.
+// defaultDate é usada quando o payload não informa a data. O erro retornado (400) pode ser exibido ao cliente.
+func applyExpensePayload(expense *models.VariableExpense, payload CreateExpensePayload, defaultDate time.Time) *apierrors.Error {
+	expenseDate := defaultDate
+	if payload.Date != "" {
+		parsedDate, err := time.Parse("2006-01-02", payload.Date)
+		if err != nil {
+			return apierrors.New(http.StatusBadRequest, apierrors.InvalidDate, "date")
+		}
+		expenseDate = parsedDate
+	}
+
+	if err := validateSplits(payload.Value, payload.Splits); err != nil {
+		return err
+	}
+	splits, category := buildSplits(payload.Category, payload.Splits)
+
+	expense.Value = payload.Value
+	expense.Category = category
+	expense.Description = payload.Description
+	expense.Date = expenseDate
+	expense.Splits = splits
+	return nil
+}
+
+// expenseLines retorna as linhas por categoria de uma despesa: suas linhas de divisão,
+// se existirem, ou uma única linha com a categoria e o valor da própria despesa.
+// Todo cálculo por categoria deve usar esta função em vez da categoria da despesa.
+func expenseLines(expense models.VariableExpense) []models.ExpenseSplit {
+	if len(expense.Splits) > 0 {
+		return expense.Splits
+	}
+	return []models.ExpenseSplit{{
+		VariableExpenseID: expense.ID,
+		Category:          expense.Category,
+		Value:             expense.Value,
+		Note:              expense.Description,
+	}}
+}
+
+// categoryTotals soma os valores das despesas por categoria, considerando as linhas de divisão.
+func categoryTotals(expenses []models.VariableExpense) map[string]float64 {
+	totals := make(map[string]float64)
+	for _, expense := range expenses {
+		for _, line := range expenseLines(expense) {
+			totals[line.Category] += line.Value
+		}
+	}
+	return totals
 }
 
 // PostExpenseHandler lida com o registro de uma nova despesa variável
 func PostExpenseHandler(c *gin.Context) {
-	userIDStr, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
-		return
-	}
-	userID, err := strconv.ParseUint(userIDStr.(string), 10, 32)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
+	userID, ok := getUserID(c)
+	if !ok {
 		return
 	}
 
 	var payload CreateExpensePayload
 	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		apierrors.RespondInvalid(c, err)
 		return
 	}
 
-	expenseDate := time.Now() // Default para hoje
-	if payload.Date != "" {
-		parsedDate, err := time.Parse("2006-01-02", payload.Date)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD."})
-			return
+	expense, err := CreateExpense(userID, payload)
+	if err != nil {
+		respondServiceError(c, err, "Failed to save expense")
+		return
+	}
+
+	setVersionETag(c, expense.Version)
+	c.JSON(http.StatusCreated, gin.H{"message": "Expense registered successfully", "expense": expense})
+}
+
+// CreateExpense valida o payload e registra uma nova despesa variável do usuário ou, com
+// HouseholdID, de um domicílio no qual ele seja editor. Notifica os inscritos em novas despesas.
+func CreateExpense(userID uint, payload CreateExpensePayload) (ExpenseResponse, error) {
+	if err := binding.Validator.ValidateStruct(&payload); err != nil {
+		return ExpenseResponse{}, apierrors.Invalid(err)
+	}
+	// Despesas de domicílio exigem papel de editor
+	if payload.HouseholdID != nil {
+		if _, err := authorizeHousehold(userID, *payload.HouseholdID, models.HouseholdRoleEditor); err != nil {
+			return ExpenseResponse{}, authorizationServiceError(err, apierrors.HouseholdNotFound)
 		}
-		expenseDate = parsedDate
 	}
 
 	variableExpense := models.VariableExpense{
-		UserID:      uint(userID),
-		Value:       payload.Value,
-		Category:    payload.Category,
-		Description: payload.Description,
-		Date:        expenseDate,
+		UserID:      userID,
+		HouseholdID: payload.HouseholdID,
+	}
+	if err := applyExpensePayload(&variableExpense, payload, time.Now()); err != nil { // Data default: hoje
+		return ExpenseResponse{}, err
 	}
 
-	if result := database.DB.Create(&variableExpense); result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save expense: " + result.Error.Error()})
+	if err := database.DB.Create(&variableExpense).Error; err != nil {
+		return ExpenseResponse{}, err
+	}
+	publishNewExpenses(variableExpense)
+	return toExpenseResponse(variableExpense), nil
+}
+
+// GetExpenseHandler retorna uma despesa variável do usuário ou de um domicílio do qual ele é membro
+func GetExpenseHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	expenseID, ok := parseIDParam(c, "id")
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "Expense registered successfully", "expense": variableExpense})
+	var expense models.VariableExpense
+	if result := database.DB.Preload("Splits").First(&expense, expenseID); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			apierrors.Respond(c, http.StatusNotFound, apierrors.ExpenseNotFound)
+		} else {
+			log.Printf("Error fetching expense %d: %v", expenseID, result.Error)
+			apierrors.RespondInternal(c)
+		}
+		return
+	}
+
+	if err := authorizeRecord(userID, expense.UserID, expense.HouseholdID, models.HouseholdRoleViewer); err != nil {
+		respondAuthorizationError(c, err, apierrors.ExpenseNotFound)
+		return
+	}
+
+	setVersionETag(c, expense.Version)
+	c.JSON(http.StatusOK, toExpenseResponse(expense))
 }
 
-// DeleteExpenseHandler lida com a remoção de uma despesa variável
+// DeleteExpenseHandler lida com a remoção de uma despesa variável (envia para a lixeira)
 func DeleteExpenseHandler(c *gin.Context) {
-	userIDStr, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+	userID, ok := getUserID(c)
+	if !ok {
 		return
 	}
-	userID, err := strconv.ParseUint(userIDStr.(string), 10, 32)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
+
+	expenseID, ok := parseIDParam(c, "id")
+	if !ok {
 		return
 	}
 
-	expenseIDStr := c.Param("id")
-	expenseID, err := strconv.ParseUint(expenseIDStr, 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid expense ID format"})
+	if err := DeleteExpense(userID, expenseID); err != nil {
+		respondServiceError(c, err, "Failed to delete expense")
 		return
 	}
 
+	c.JSON(http.StatusOK, gin.H{"message": "Expense moved to trash. It can be restored for " + strconv.Itoa(trashRetentionDays) + " days."})
+}
+
+// DeleteExpense envia uma despesa variável para a lixeira. Despesas pessoais só podem ser
+// removidas pelo dono; despesas de domicílio, por editores.
+func DeleteExpense(userID, expenseID uint) error {
+	var expense models.VariableExpense
+	// Primeiro, encontrar a despesa para garantir que existe
+	if err := database.DB.First(&expense, expenseID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return newServiceError(http.StatusNotFound, apierrors.ExpenseNotFound)
+		}
+		return err
+	}
+
+	if err := authorizeRecord(userID, expense.UserID, expense.HouseholdID, models.HouseholdRoleEditor); err != nil {
+		return authorizationServiceError(err, apierrors.ExpenseNotFound)
+	}
+
+	// Mover para a lixeira (as linhas de divisão são mantidas para uma eventual restauração
+	// e removidas junto com a despesa na limpeza definitiva)
+	if err := database.DB.Delete(&expense).Error; err != nil {
+		return err
+	}
+	publishDeletedExpense(expense)
+	return nil
+}
+
+// errStaleVersion indica que o registro foi alterado por outra requisição durante a atualização.
+var errStaleVersion = errors.New("stale record version")
+
+// loadEditableExpense busca a despesa da rota e verifica se o usuário pode alterá-la.
+// Em caso de falha, já responde à requisição e retorna false.
+func loadEditableExpense(c *gin.Context) (models.VariableExpense, bool) {
 	var expense models.VariableExpense
-	// Primeiro, encontrar a despesa para garantir que pertence ao usuário e existe
-	if result := database.DB.Where("id = ? AND user_id = ?", uint(expenseID), uint(userID)).First(&expense); result.Error != nil {
-		if result.Error.Error() == "record not found" { // gorm.ErrRecordNotFound não é exportado diretamente em alguns contextos
-			c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found or you do not have permission to delete it."})
+	userID, ok := getUserID(c)
+	if !ok {
+		return expense, false
+	}
+	expenseID, ok := parseIDParam(c, "id")
+	if !ok {
+		return expense, false
+	}
+
+	if result := database.DB.Preload("Splits").First(&expense, expenseID); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			apierrors.Respond(c, http.StatusNotFound, apierrors.ExpenseNotFound)
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error: " + result.Error.Error()})
+			log.Printf("Error fetching expense %d: %v", expenseID, result.Error)
+			apierrors.RespondInternal(c)
 		}
+		return expense, false
+	}
+
+	// Mesmas regras de DeleteExpenseHandler: dono da despesa pessoal ou editor do domicílio
+	if err := authorizeRecord(userID, expense.UserID, expense.HouseholdID, models.HouseholdRoleEditor); err != nil {
+		respondAuthorizationError(c, err, apierrors.ExpenseNotFound)
+		return expense, false
+	}
+	return expense, true
+}
+
+// writeExpenseUpdate grava os dados e as linhas de divisão da despesa somente se a versão no
+// banco ainda for readVersion; caso contrário retorna errStaleVersion. Recarrega a despesa gravada.
+func writeExpenseUpdate(tx *gorm.DB, expense *models.VariableExpense, readVersion uint) error {
+	result := tx.Model(&models.VariableExpense{}).
+		Where("id = ? AND version = ?", expense.ID, readVersion).
+		Updates(map[string]interface{}{
+			"value":       expense.Value,
+			"category":    expense.Category,
+			"description": expense.Description,
+			"date":        expense.Date,
+			"version":     gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errStaleVersion
+	}
+
+	// Substitui as linhas de divisão
+	if err := tx.Where("variable_expense_id = ?", expense.ID).Delete(&models.ExpenseSplit{}).Error; err != nil {
+		return err
+	}
+	for i := range expense.Splits {
+		expense.Splits[i].VariableExpenseID = expense.ID
+	}
+	if len(expense.Splits) > 0 {
+		if err := tx.Create(&expense.Splits).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Preload("Splits").First(expense, expense.ID).Error
+}
+
+// saveExpenseUpdate valida o payload completo, aplica-o à despesa e grava a alteração
+// somente se a versão no banco ainda for a lida (controle de concorrência otimista).
+func saveExpenseUpdate(c *gin.Context, expense models.VariableExpense, payload CreateExpensePayload) {
+	if payload.HouseholdID != nil && (expense.HouseholdID == nil || *payload.HouseholdID != *expense.HouseholdID) {
+		apierrors.Respond(c, http.StatusBadRequest, apierrors.HouseholdChangeDenied)
+		return
+	}
+
+	readVersion := expense.Version
+	if err := applyExpensePayload(&expense, payload, expense.Date); err != nil { // Sem data: mantém a atual
+		apierrors.RespondError(c, err)
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return writeExpenseUpdate(tx, &expense, readVersion)
+	})
+	if errors.Is(err, errStaleVersion) {
+		var current models.VariableExpense
+		database.DB.Select("version").First(&current, expense.ID)
+		respondVersionConflict(c, current.Version) // Versão lida já não é a atual
+		return
+	}
+	if err != nil {
+		log.Printf("Error updating expense %d: %v", expense.ID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+
+	setVersionETag(c, expense.Version)
+	c.JSON(http.StatusOK, gin.H{"message": "Expense updated successfully", "expense": toExpenseResponse(expense)})
+}
+
+// PutExpenseHandler substitui os dados de uma despesa variável, mantendo seu ID e data de criação.
+// Aceita o cabeçalho If-Match com o ETag da versão lida; responde 412 se a versão estiver desatualizada.
+func PutExpenseHandler(c *gin.Context) {
+	expense, ok := loadEditableExpense(c)
+	if !ok {
+		return
+	}
+	if !checkIfMatch(c, expense.Version) {
 		return
 	}
 
-	// Se encontrada e pertence ao usuário, deletar
-	if result := database.DB.Delete(&expense); result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete expense: " + result.Error.Error()})
+	var payload CreateExpensePayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		apierrors.RespondInvalid(c, err)
+		return
+	}
+
+	saveExpenseUpdate(c, expense, payload)
+}
+
+// PatchExpenseHandler altera apenas os campos informados de uma despesa variável.
+// As mesmas validações de CreateExpensePayload são aplicadas ao resultado da alteração.
+func PatchExpenseHandler(c *gin.Context) {
+	expense, ok := loadEditableExpense(c)
+	if !ok {
+		return
+	}
+	if !checkIfMatch(c, expense.Version) {
+		return
+	}
+
+	var patch PatchExpensePayload
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		apierrors.RespondInvalid(c, err)
+		return
+	}
+
+	// Parte do estado atual e aplica os campos enviados
+	payload := CreateExpensePayload{
+		Value:       expense.Value,
+		Category:    expense.Category,
+		Description: expense.Description,
+		Date:        expense.Date.Format("2006-01-02"),
+		HouseholdID: expense.HouseholdID,
+	}
+	for _, split := range expense.Splits {
+		payload.Splits = append(payload.Splits, ExpenseSplitPayload{Category: split.Category, Value: split.Value, Note: split.Note})
+	}
+	if patch.Value != nil {
+		payload.Value = *patch.Value
+	}
+	if patch.Category != nil {
+		payload.Category = *patch.Category
+	}
+	if patch.Description != nil {
+		payload.Description = *patch.Description
+	}
+	if patch.Date != nil {
+		payload.Date = *patch.Date
+	}
+	if patch.Splits != nil {
+		payload.Splits = *patch.Splits
+	}
+
+	if err := binding.Validator.ValidateStruct(&payload); err != nil {
+		apierrors.RespondInvalid(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Expense deleted successfully"})
+	saveExpenseUpdate(c, expense, payload)
 }
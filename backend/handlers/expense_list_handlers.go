@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"personal-finance-app/backend/apierrors"
+	"personal-finance-app/backend/database"
+	"personal-finance-app/backend/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultExpensePageSize = 50
+	maxExpensePageSize     = 200
+)
+
+// expenseSortColumns mapeia os valores aceitos em ?sort= para a coluna ordenada.
+// O prefixo "-" indica ordem decrescente.
+var expenseSortColumns = map[string]string{
+	"date":  "date",
+	"value": "value",
+}
+
+// expenseCursor é o conteúdo (codificado em base64) do cursor de paginação da listagem.
+// Guarda a posição do último item retornado na ordenação usada (keyset pagination).
+type expenseCursor struct {
+	Sort  string    `json:"s"`
+	Date  time.Time `json:"d,omitempty"`
+	Value float64   `json:"v,omitempty"`
+	ID    uint      `json:"id"`
+}
+
+// encode serializa o cursor em uma string opaca para o cliente.
+func (cur expenseCursor) encode() string {
+	raw, _ := json.Marshal(cur)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeExpenseCursor interpreta o cursor recebido em ?cursor=.
+func decodeExpenseCursor(encoded string) (expenseCursor, bool) {
+	var cur expenseCursor
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cur, false
+	}
+	if err := json.Unmarshal(raw, &cur); err != nil || cur.ID == 0 {
+		return cur, false
+	}
+	return cur, true
+}
+
+// ExpenseListResponse é a resposta paginada da listagem de despesas variáveis
+type ExpenseListResponse struct {
+	Expenses   []ExpenseResponse `json:"expenses"`
+	NextCursor *string           `json:"nextCursor"` // nil quando não há mais páginas
+	HasMore    bool              `json:"hasMore"`
+}
+
+// escapeLike escapa os curingas do LIKE para busca textual literal.
+func escapeLike(term string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(term)
+}
+
+// ExpenseFilter define os filtros, a ordenação e a paginação da listagem de despesas variáveis
+type ExpenseFilter struct {
+	From       *time.Time // Inclusivo
+	To         *time.Time // Inclusivo (o dia inteiro)
+	Categories []string   // Considera as linhas de divisão
+	MinValue   *float64
+	MaxValue   *float64
+	Search     string // Busca na descrição, categorias e notas
+	Sort       string // date, -date, value ou -value; padrão -date
+	Limit      int    // Padrão defaultExpensePageSize
+	Cursor     string // nextCursor da página anterior
+}
+
+// ListExpensesHandler lista as despesas variáveis do usuário (ou de um domicílio, com ?householdId=).
+//
+// Filtros opcionais: from/to (YYYY-MM-DD, inclusivos), category (uma ou mais, separadas por vírgula,
+// considerando as linhas de divisão), minValue/maxValue e q (busca na descrição, categorias e notas).
+// Ordenação por ?sort= (date, -date, value, -value; padrão -date) e paginação por cursor
+// com ?limit= e ?cursor= (o valor de nextCursor da página anterior).
+func ListExpensesHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	householdID, ok := householdIDFromQuery(c)
+	if !ok {
+		return
+	}
+
+	filter := ExpenseFilter{
+		Search: strings.TrimSpace(c.Query("q")),
+		Sort:   c.Query("sort"),
+		Cursor: c.Query("cursor"),
+	}
+
+	// Período
+	for param, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		raw := c.Query(param)
+		if raw == "" {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			apierrors.Respond(c, http.StatusBadRequest, apierrors.InvalidDate, param)
+			return
+		}
+		*target = &date
+	}
+
+	// Categorias, separadas por vírgula
+	for _, name := range strings.Split(c.Query("category"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			filter.Categories = append(filter.Categories, name)
+		}
+	}
+
+	// Faixa de valores
+	for param, target := range map[string]**float64{"minValue": &filter.MinValue, "maxValue": &filter.MaxValue} {
+		raw := c.Query(param)
+		if raw == "" {
+			continue
+		}
+		amount, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			apierrors.Respond(c, http.StatusBadRequest, apierrors.InvalidParameter, param)
+			return
+		}
+		*target = &amount
+	}
+
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			apierrors.Respond(c, http.StatusBadRequest, apierrors.InvalidLimit, maxExpensePageSize)
+			return
+		}
+		filter.Limit = parsed
+	}
+
+	response, err := ListExpenses(userID, householdID, filter)
+	if err != nil {
+		respondServiceError(c, err, "Failed to list expenses")
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// ListExpenses lista as despesas variáveis do usuário ou, com householdID, de um domicílio do qual
+// ele é membro, aplicando os filtros, a ordenação e a paginação por cursor.
+func ListExpenses(userID uint, householdID *uint, filter ExpenseFilter) (ExpenseListResponse, error) {
+	scope := ownerScope{UserID: userID, HouseholdID: householdID}
+	if householdID != nil {
+		if _, err := authorizeHousehold(userID, *householdID, models.HouseholdRoleViewer); err != nil {
+			return ExpenseListResponse{}, authorizationServiceError(err, apierrors.HouseholdNotFound)
+		}
+	}
+
+	query := scope.apply(database.DB.Model(&models.VariableExpense{}))
+
+	// Período
+	if filter.From != nil {
+		query = query.Where("date >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("date < ?", filter.To.AddDate(0, 0, 1)) // Inclui o dia inteiro
+	}
+
+	// Categorias: casa com a categoria da despesa ou de qualquer uma de suas linhas de divisão
+	if len(filter.Categories) > 0 {
+		query = query.Where("category IN ? OR id IN (SELECT variable_expense_id FROM expense_splits WHERE category IN ?)", filter.Categories, filter.Categories)
+	}
+
+	// Faixa de valores
+	if filter.MinValue != nil {
+		query = query.Where("value >= ?", *filter.MinValue)
+	}
+	if filter.MaxValue != nil {
+		query = query.Where("value <= ?", *filter.MaxValue)
+	}
+
+	// Busca textual
+	if filter.Search != "" {
+		pattern := "%" + escapeLike(filter.Search) + "%"
+		query = query.Where("description ILIKE ? OR category ILIKE ? OR id IN (SELECT variable_expense_id FROM expense_splits WHERE category ILIKE ? OR note ILIKE ?)",
+			pattern, pattern, pattern, pattern)
+	}
+
+	// Ordenação
+	sort := filter.Sort
+	if sort == "" {
+		sort = "-date"
+	}
+	column, known := expenseSortColumns[strings.TrimPrefix(sort, "-")]
+	if !known {
+		return ExpenseListResponse{}, newServiceError(http.StatusBadRequest, apierrors.InvalidSort)
+	}
+	direction, comparison := "ASC", ">"
+	if strings.HasPrefix(sort, "-") {
+		direction, comparison = "DESC", "<"
+	}
+
+	// Paginação
+	limit := filter.Limit
+	if limit == 0 {
+		limit = defaultExpensePageSize
+	}
+	if limit < 1 || limit > maxExpensePageSize {
+		return ExpenseListResponse{}, newServiceError(http.StatusBadRequest, apierrors.InvalidLimit, maxExpensePageSize)
+	}
+	if filter.Cursor != "" {
+		cur, valid := decodeExpenseCursor(filter.Cursor)
+		if !valid || cur.Sort != sort {
+			return ExpenseListResponse{}, newServiceError(http.StatusBadRequest, apierrors.InvalidCursor, "cursor")
+		}
+		var position interface{} = cur.Date
+		if column == "value" {
+			position = cur.Value
+		}
+		query = query.Where("("+column+" "+comparison+" ?) OR ("+column+" = ? AND id "+comparison+" ?)", position, position, cur.ID)
+	}
+
+	var expenses []models.VariableExpense
+	err := query.Preload("Splits", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Order(column + " " + direction).Order("id " + direction).
+		Limit(limit + 1). // Um item a mais para saber se há próxima página
+		Find(&expenses).Error
+	if err != nil {
+		return ExpenseListResponse{}, err
+	}
+
+	response := ExpenseListResponse{Expenses: make([]ExpenseResponse, 0, limit)}
+	if len(expenses) > limit {
+		expenses = expenses[:limit]
+		last := expenses[len(expenses)-1]
+		next := expenseCursor{Sort: sort, Date: last.Date, Value: last.Value, ID: last.ID}.encode()
+		response.NextCursor = &next
+		response.HasMore = true
+	}
+	for _, expense := range expenses {
+		response.Expenses = append(response.Expenses, toExpenseResponse(expense))
+	}
+	return response, nil
+}
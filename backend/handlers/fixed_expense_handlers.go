@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"personal-finance-app/backend/apierrors"
+	"personal-finance-app/backend/database"
+	"personal-finance-app/backend/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+// CreateFixedExpensePayload define a estrutura para criar uma despesa fixa individual
+type CreateFixedExpensePayload struct {
+	Name        string  `json:"name" binding:"required"`
+	Value       float64 `json:"value" binding:"required,gt=0"`
+	DueDay      int     `json:"dueDay" binding:"omitempty,min=1,max=31"` // Opcional, dia de vencimento
+	Category    string  `json:"category"`                                // Opcional
+	Active      *bool   `json:"active"`                                  // Opcional, padrão true
+	HouseholdID *uint   `json:"householdId"`                             // Opcional, registra a despesa no domicílio
+}
+
+// PatchFixedExpensePayload define a estrutura para alterar parcialmente uma despesa fixa.
+// Campos ausentes mantêm o valor atual; "dueDay": 0 remove o dia de vencimento.
+type PatchFixedExpensePayload struct {
+	Name     *string  `json:"name" binding:"omitempty,min=1"`
+	Value    *float64 `json:"value" binding:"omitempty,gt=0"`
+	DueDay   *int     `json:"dueDay" binding:"omitempty,min=0,max=31"`
+	Category *string  `json:"category"`
+	Active   *bool    `json:"active"`
+}
+
+// FixedExpenseResponse é a representação JSON de uma despesa fixa retornada pela API
+type FixedExpenseResponse struct {
+	ID          uint      `json:"id"`
+	UserID      uint      `json:"userId"`
+	HouseholdID *uint     `json:"householdId"`
+	Name        string    `json:"name"`
+	Value       float64   `json:"value"`
+	DueDay      int       `json:"dueDay"` // 0 se não informado
+	Category    string    `json:"category"`
+	Active      bool      `json:"active"`
+	Version     uint      `json:"version"` // Também enviada no cabeçalho ETag
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// toFixedExpenseResponse converte o modelo em sua representação JSON.
+func toFixedExpenseResponse(fe models.FixedExpense) FixedExpenseResponse {
+	return FixedExpenseResponse{
+		ID:          fe.ID,
+		UserID:      fe.UserID,
+		HouseholdID: fe.HouseholdID,
+		Name:        fe.Name,
+		Value:       fe.Value,
+		DueDay:      fe.DueDay,
+		Category:    fe.Category,
+		Active:      fe.Active,
+		Version:     fe.Version,
+		CreatedAt:   fe.CreatedAt,
+		UpdatedAt:   fe.UpdatedAt,
+	}
+}
+
+// ListFixedExpensesHandler lista as despesas fixas do usuário (ou de um domicílio, com ?householdId=).
+// Aceita ?active=true|false para filtrar pelo status.
+func ListFixedExpensesHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	householdID, ok := householdIDFromQuery(c)
+	if !ok {
+		return
+	}
+	scope, ok := resolveScope(c, userID, householdID, models.HouseholdRoleViewer)
+	if !ok {
+		return
+	}
+
+	query := scope.apply(database.DB)
+	if raw := c.Query("active"); raw != "" {
+		active, err := strconv.ParseBool(raw)
+		if err != nil {
+			apierrors.Respond(c, http.StatusBadRequest, apierrors.InvalidParameter, "active")
+			return
+		}
+		query = query.Where("active = ?", active)
+	}
+
+	var fixedExpenses []models.FixedExpense
+	if err := query.Order("due_day, id").Find(&fixedExpenses).Error; err != nil {
+		log.Printf("Error listing fixed expenses for user %d: %v", userID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+
+	response := make([]FixedExpenseResponse, 0, len(fixedExpenses))
+	for _, fe := range fixedExpenses {
+		response = append(response, toFixedExpenseResponse(fe))
+	}
+	c.JSON(http.StatusOK, gin.H{"fixedExpenses": response})
+}
+
+// loadFixedExpense busca a despesa fixa da rota e verifica se o usuário tem o papel exigido.
+// Em caso de falha, já responde à requisição e retorna false.
+func loadFixedExpense(c *gin.Context, minRole string) (models.FixedExpense, bool) {
+	var fixedExpense models.FixedExpense
+	userID, ok := getUserID(c)
+	if !ok {
+		return fixedExpense, false
+	}
+	fixedExpenseID, ok := parseIDParam(c, "id")
+	if !ok {
+		return fixedExpense, false
+	}
+
+	if err := database.DB.First(&fixedExpense, fixedExpenseID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			apierrors.Respond(c, http.StatusNotFound, apierrors.FixedExpenseNotFound)
+		} else {
+			log.Printf("Error fetching fixed expense %d: %v", fixedExpenseID, err)
+			apierrors.RespondInternal(c)
+		}
+		return fixedExpense, false
+	}
+
+	if err := authorizeRecord(userID, fixedExpense.UserID, fixedExpense.HouseholdID, minRole); err != nil {
+		respondAuthorizationError(c, err, apierrors.FixedExpenseNotFound)
+		return fixedExpense, false
+	}
+	return fixedExpense, true
+}
+
+// GetFixedExpenseHandler retorna uma despesa fixa
+func GetFixedExpenseHandler(c *gin.Context) {
+	fixedExpense, ok := loadFixedExpense(c, models.HouseholdRoleViewer)
+	if !ok {
+		return
+	}
+	setVersionETag(c, fixedExpense.Version)
+	c.JSON(http.StatusOK, toFixedExpenseResponse(fixedExpense))
+}
+
+// createFixedExpense grava uma nova despesa fixa respeitando o status ativo informado.
+func createFixedExpense(tx *gorm.DB, fixedExpense *models.FixedExpense) error {
+	active := fixedExpense.Active
+	if err := tx.Create(fixedExpense).Error; err != nil {
+		return err
+	}
+	// O GORM substitui false pelo default (true) na criação, então gravamos o status em seguida
+	if !active {
+		fixedExpense.Active = false
+		return tx.Model(fixedExpense).Update("active", false).Error
+	}
+	return nil
+}
+
+// trimFixedExpenseName remove os espaços do nome (se informado) e valida o payload de novo: um nome
+// só de espaços seria gravado vazio.
+func trimFixedExpenseName(payload interface{}, name *string) error {
+	if name != nil {
+		*name = strings.TrimSpace(*name)
+	}
+	return binding.Validator.ValidateStruct(payload)
+}
+
+// fixedExpensePatchUpdates monta as colunas alteradas por um patch, incrementando a versão. Sem
+// campos informados, retorna nil: nada muda, nem a versão.
+func fixedExpensePatchUpdates(patch PatchFixedExpensePayload) map[string]interface{} {
+	updates := map[string]interface{}{}
+	if patch.Name != nil {
+		updates["name"] = *patch.Name
+	}
+	if patch.Value != nil {
+		updates["value"] = *patch.Value
+	}
+	if patch.DueDay != nil {
+		updates["due_day"] = *patch.DueDay
+	}
+	if patch.Category != nil {
+		updates["category"] = *patch.Category
+	}
+	if patch.Active != nil {
+		updates["active"] = *patch.Active
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	updates["version"] = gorm.Expr("version + 1")
+	return updates
+}
+
+// PostFixedExpenseHandler cria uma despesa fixa individual, sem afetar as demais
+func PostFixedExpenseHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var payload CreateFixedExpensePayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		apierrors.RespondInvalid(c, err)
+		return
+	}
+	if err := trimFixedExpenseName(&payload, &payload.Name); err != nil {
+		apierrors.RespondInvalid(c, err)
+		return
+	}
+
+	if _, ok := resolveScope(c, userID, payload.HouseholdID, models.HouseholdRoleEditor); !ok {
+		return
+	}
+
+	fixedExpense := models.FixedExpense{
+		UserID:      userID,
+		HouseholdID: payload.HouseholdID,
+		Name:        payload.Name,
+		Value:       payload.Value,
+		DueDay:      payload.DueDay,
+		Category:    payload.Category,
+		Active:      payload.Active == nil || *payload.Active,
+		Version:     1,
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return createFixedExpense(tx, &fixedExpense)
+	})
+	if err != nil {
+		log.Printf("Error creating fixed expense for user %d: %v", userID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+
+	setVersionETag(c, fixedExpense.Version)
+	c.JSON(http.StatusCreated, gin.H{"message": "Fixed expense created successfully", "fixedExpense": toFixedExpenseResponse(fixedExpense)})
+}
+
+// PatchFixedExpenseHandler altera apenas os campos informados de uma despesa fixa. Sem campos
+// informados, responde com a despesa atual, sem alterar a versão.
+// Aceita o cabeçalho If-Match com o ETag da versão lida; responde 412 se a versão estiver desatualizada.
+func PatchFixedExpenseHandler(c *gin.Context) {
+	fixedExpense, ok := loadFixedExpense(c, models.HouseholdRoleEditor)
+	if !ok {
+		return
+	}
+	if !checkIfMatch(c, fixedExpense.Version) {
+		return
+	}
+
+	var patch PatchFixedExpensePayload
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		apierrors.RespondInvalid(c, err)
+		return
+	}
+	if err := trimFixedExpenseName(&patch, patch.Name); err != nil {
+		apierrors.RespondInvalid(c, err)
+		return
+	}
+	updates := fixedExpensePatchUpdates(patch)
+	if updates == nil {
+		setVersionETag(c, fixedExpense.Version)
+		c.JSON(http.StatusOK, gin.H{"message": "Fixed expense updated successfully", "fixedExpense": toFixedExpenseResponse(fixedExpense)})
+		return
+	}
+
+	result := database.DB.Model(&models.FixedExpense{}).
+		Where("id = ? AND version = ?", fixedExpense.ID, fixedExpense.Version).
+		Updates(updates)
+	if result.Error != nil {
+		log.Printf("Error updating fixed expense %d: %v", fixedExpense.ID, result.Error)
+		apierrors.RespondInternal(c)
+		return
+	}
+
+	var updated models.FixedExpense
+	if err := database.DB.First(&updated, fixedExpense.ID).Error; err != nil {
+		log.Printf("Error reloading fixed expense %d: %v", fixedExpense.ID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+	if result.RowsAffected == 0 {
+		respondVersionConflict(c, updated.Version) // Alterada por outra requisição entre a leitura e a gravação
+		return
+	}
+
+	setVersionETag(c, updated.Version)
+	c.JSON(http.StatusOK, gin.H{"message": "Fixed expense updated successfully", "fixedExpense": toFixedExpenseResponse(updated)})
+}
+
+// DeleteFixedExpenseHandler remove uma despesa fixa individual.
+// Aceita o cabeçalho If-Match para não remover uma despesa alterada por outro dispositivo.
+func DeleteFixedExpenseHandler(c *gin.Context) {
+	fixedExpense, ok := loadFixedExpense(c, models.HouseholdRoleEditor)
+	if !ok {
+		return
+	}
+	if !checkIfMatch(c, fixedExpense.Version) {
+		return
+	}
+
+	result := database.DB.Where("version = ?", fixedExpense.Version).Delete(&fixedExpense)
+	if result.Error != nil {
+		log.Printf("Error deleting fixed expense %d: %v", fixedExpense.ID, result.Error)
+		apierrors.RespondInternal(c)
+		return
+	}
+	if result.RowsAffected == 0 {
+		var current models.FixedExpense
+		if err := database.DB.First(&current, fixedExpense.ID).Error; err != nil {
+			apierrors.Respond(c, http.StatusNotFound, apierrors.FixedExpenseNotFound)
+			return
+		}
+		respondVersionConflict(c, current.Version)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Fixed expense deleted successfully"})
+}
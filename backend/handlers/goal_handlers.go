@@ -0,0 +1,318 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"personal-finance-app/backend/apierrors"
+	"personal-finance-app/backend/database"
+	"personal-finance-app/backend/finance"
+	"personal-finance-app/backend/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateGoalPayload define a estrutura para criar uma meta de economia
+type CreateGoalPayload struct {
+	Name         string  `json:"name" binding:"required"`
+	TargetAmount float64 `json:"targetAmount" binding:"required,gt=0"`
+	Deadline     string  `json:"deadline" binding:"required"` // YYYY-MM-DD
+	HouseholdID  *uint   `json:"householdId"`                 // Opcional, cria a meta no domicílio
+}
+
+// GoalMovementPayload define a estrutura de um aporte ou de uma retirada de uma meta
+type GoalMovementPayload struct {
+	Amount float64 `json:"amount" binding:"required,gt=0"`
+	Note   string  `json:"note"` // Opcional
+}
+
+// GoalResponse é a representação JSON de uma meta de economia, com o andamento calculado no momento da consulta
+type GoalResponse struct {
+	ID           uint                 `json:"id"`
+	UserID       uint                 `json:"userId"`
+	HouseholdID  *uint                `json:"householdId"`
+	Name         string               `json:"name"`
+	TargetAmount float64              `json:"targetAmount"`
+	Deadline     string               `json:"deadline"` // YYYY-MM-DD
+	Progress     finance.GoalProgress `json:"progress"`
+	CreatedAt    time.Time            `json:"createdAt"`
+	UpdatedAt    time.Time            `json:"updatedAt"`
+}
+
+// GoalContributionResponse é a representação JSON de um aporte (valor positivo) ou de uma retirada (valor negativo)
+type GoalContributionResponse struct {
+	ID        uint      `json:"id"`
+	GoalID    uint      `json:"goalId"`
+	UserID    uint      `json:"userId"`
+	Amount    float64   `json:"amount"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// GoalDetailResponse é a resposta de GET /goals/:id: a meta e o histórico de aportes e retiradas
+type GoalDetailResponse struct {
+	GoalResponse
+	Contributions []GoalContributionResponse `json:"contributions"`
+}
+
+// toGoalResponse converte o modelo em sua representação JSON, calculando o andamento até now.
+func toGoalResponse(goal models.Goal, contributions []models.GoalContribution, now time.Time) GoalResponse {
+	return GoalResponse{
+		ID:           goal.ID,
+		UserID:       goal.UserID,
+		HouseholdID:  goal.HouseholdID,
+		Name:         goal.Name,
+		TargetAmount: goal.TargetAmount,
+		Deadline:     goal.Deadline.Format("2006-01-02"),
+		Progress:     finance.ComputeGoalProgress(goal, contributions, finance.FixedClock(now)),
+		CreatedAt:    goal.CreatedAt,
+		UpdatedAt:    goal.UpdatedAt,
+	}
+}
+
+// toGoalContributionResponse converte o modelo em sua representação JSON.
+func toGoalContributionResponse(contribution models.GoalContribution) GoalContributionResponse {
+	return GoalContributionResponse{
+		ID:        contribution.ID,
+		GoalID:    contribution.GoalID,
+		UserID:    contribution.UserID,
+		Amount:    contribution.Amount,
+		Note:      contribution.Note,
+		CreatedAt: contribution.CreatedAt,
+	}
+}
+
+// ListGoalsHandler lista as metas de economia do usuário (ou de um domicílio, com ?householdId=),
+// da mais próxima do prazo para a mais distante.
+func ListGoalsHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	householdID, ok := householdIDFromQuery(c)
+	if !ok {
+		return
+	}
+	scope, ok := resolveScope(c, userID, householdID, models.HouseholdRoleViewer)
+	if !ok {
+		return
+	}
+
+	var goals []models.Goal
+	if err := scope.apply(database.DB.Preload("Contributions")).Order("deadline, id").Find(&goals).Error; err != nil {
+		log.Printf("Error listing goals for user %d: %v", userID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+
+	now := time.Now()
+	response := make([]GoalResponse, 0, len(goals))
+	for _, goal := range goals {
+		response = append(response, toGoalResponse(goal, goal.Contributions, now))
+	}
+	c.JSON(http.StatusOK, gin.H{"goals": response})
+}
+
+// PostGoalHandler cria uma meta de economia com valor-alvo e prazo
+func PostGoalHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var payload CreateGoalPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		apierrors.RespondInvalid(c, err)
+		return
+	}
+	deadline, err := time.ParseInLocation("2006-01-02", payload.Deadline, time.Local)
+	if err != nil {
+		apierrors.Respond(c, http.StatusBadRequest, apierrors.InvalidDate, "deadline")
+		return
+	}
+	now := time.Now()
+	if deadline.Before(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)) {
+		apierrors.Respond(c, http.StatusBadRequest, apierrors.GoalDeadlinePassed)
+		return
+	}
+
+	if _, ok := resolveScope(c, userID, payload.HouseholdID, models.HouseholdRoleEditor); !ok {
+		return
+	}
+
+	goal := models.Goal{
+		UserID:       userID,
+		HouseholdID:  payload.HouseholdID,
+		Name:         payload.Name,
+		TargetAmount: payload.TargetAmount,
+		Deadline:     deadline,
+	}
+	if err := database.DB.Create(&goal).Error; err != nil {
+		log.Printf("Error creating goal for user %d: %v", userID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Goal created successfully", "goal": toGoalResponse(goal, nil, now)})
+}
+
+// loadGoal busca a meta da rota, com seus aportes, e verifica se o usuário tem o papel exigido.
+// Em caso de falha, já responde à requisição e retorna false.
+func loadGoal(c *gin.Context, minRole string) (models.Goal, bool) {
+	var goal models.Goal
+	userID, ok := getUserID(c)
+	if !ok {
+		return goal, false
+	}
+	goalID, ok := parseIDParam(c, "id")
+	if !ok {
+		return goal, false
+	}
+
+	err := database.DB.Preload("Contributions", func(db *gorm.DB) *gorm.DB { return db.Order("created_at, id") }).
+		First(&goal, goalID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			apierrors.Respond(c, http.StatusNotFound, apierrors.GoalNotFound)
+		} else {
+			log.Printf("Error fetching goal %d: %v", goalID, err)
+			apierrors.RespondInternal(c)
+		}
+		return goal, false
+	}
+
+	if err := authorizeRecord(userID, goal.UserID, goal.HouseholdID, minRole); err != nil {
+		respondAuthorizationError(c, err, apierrors.GoalNotFound)
+		return goal, false
+	}
+	return goal, true
+}
+
+// GetGoalHandler retorna uma meta de economia, seu andamento e o histórico de aportes e retiradas
+func GetGoalHandler(c *gin.Context) {
+	goal, ok := loadGoal(c, models.HouseholdRoleViewer)
+	if !ok {
+		return
+	}
+
+	contributions := make([]GoalContributionResponse, 0, len(goal.Contributions))
+	for _, contribution := range goal.Contributions {
+		contributions = append(contributions, toGoalContributionResponse(contribution))
+	}
+	c.JSON(http.StatusOK, GoalDetailResponse{
+		GoalResponse:  toGoalResponse(goal, goal.Contributions, time.Now()),
+		Contributions: contributions,
+	})
+}
+
+// DeleteGoalHandler remove uma meta de economia e seus aportes. O valor guardado volta ao saldo dos
+// meses em que foi aportado.
+func DeleteGoalHandler(c *gin.Context) {
+	goal, ok := loadGoal(c, models.HouseholdRoleEditor)
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("goal_id = ?", goal.ID).Delete(&models.GoalContribution{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&models.Goal{}, goal.ID).Error; err != nil {
+			return err
+		}
+		return notifyGoalChange(tx, goal)
+	})
+	if err != nil {
+		log.Printf("Error deleting goal %d: %v", goal.ID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+	go checkBalanceEvents(ownerScope{UserID: goal.UserID, HouseholdID: goal.HouseholdID})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Goal deleted successfully"})
+}
+
+// PostGoalContributionHandler registra um aporte na meta, descontado do saldo do mês corrente
+func PostGoalContributionHandler(c *gin.Context) {
+	moveGoalFunds(c, 1)
+}
+
+// PostGoalWithdrawalHandler registra uma retirada da meta, até o valor guardado; o valor volta ao
+// saldo do mês corrente
+func PostGoalWithdrawalHandler(c *gin.Context) {
+	moveGoalFunds(c, -1)
+}
+
+// moveGoalFunds registra um aporte (sign 1) ou uma retirada (sign -1) na meta da rota e responde
+// com a meta atualizada. A meta fica bloqueada durante a gravação, para que retiradas simultâneas
+// não ultrapassem o valor guardado.
+func moveGoalFunds(c *gin.Context, sign float64) {
+	goal, ok := loadGoal(c, models.HouseholdRoleEditor)
+	if !ok {
+		return
+	}
+	userID, _ := getUserID(c)
+
+	var payload GoalMovementPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		apierrors.RespondInvalid(c, err)
+		return
+	}
+
+	contribution := models.GoalContribution{GoalID: goal.ID, UserID: userID, Amount: sign * payload.Amount, Note: payload.Note}
+	var contributions []models.GoalContribution
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&goal, goal.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("goal_id = ?", goal.ID).Order("created_at, id").Find(&contributions).Error; err != nil {
+			return err
+		}
+		if saved := finance.GoalSaved(contributions); saved+contribution.Amount < 0 {
+			return apierrors.New(http.StatusConflict, apierrors.InsufficientGoalFunds, payload.Amount, saved)
+		}
+		if err := tx.Create(&contribution).Error; err != nil {
+			return err
+		}
+		return notifyGoalChange(tx, goal)
+	})
+	var apiErr *apierrors.Error
+	switch {
+	case errors.As(err, &apiErr):
+		apierrors.RespondError(c, apiErr)
+		return
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		apierrors.Respond(c, http.StatusNotFound, apierrors.GoalNotFound)
+		return
+	case err != nil:
+		log.Printf("Error saving contribution to goal %d: %v", goal.ID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+
+	go checkBalanceEvents(ownerScope{UserID: goal.UserID, HouseholdID: goal.HouseholdID})
+
+	contributions = append(contributions, contribution)
+	c.JSON(http.StatusCreated, gin.H{
+		"message":      "Goal updated successfully",
+		"goal":         toGoalResponse(goal, contributions, time.Now()),
+		"contribution": toGoalContributionResponse(contribution),
+	})
+}
+
+// notifyGoalChange publica a alteração do saldo do escopo da meta (ver notifyBalanceChange), após o
+// commit da transação. Aportes em metas não passam pelos triggers de change_logs: não são sincronizados.
+func notifyGoalChange(tx *gorm.DB, goal models.Goal) error {
+	return database.NotifyChange(tx, database.Change{Entity: models.ChangeEntityGoal, UserID: goal.UserID, HouseholdID: goal.HouseholdID})
+}
+
+// goalContributionsBetween retorna os aportes e retiradas feitos de from a to nas metas do escopo.
+func goalContributionsBetween(scope ownerScope, from, to time.Time) ([]models.GoalContribution, error) {
+	var contributions []models.GoalContribution
+	goals := scope.apply(database.DB.Model(&models.Goal{})).Select("id")
+	err := database.DB.Where("goal_id IN (?) AND created_at >= ? AND created_at <= ?", goals, from, to).Find(&contributions).Error
+	return contributions, err
+}
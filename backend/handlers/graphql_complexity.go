@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// Limites padrão das consultas GraphQL, quando GRAPHQL_MAX_COMPLEXITY e GRAPHQL_MAX_DEPTH não estão definidas.
+const (
+	defaultGraphQLMaxComplexity = 1000
+	defaultGraphQLMaxDepth      = 8
+)
+
+// graphqlLimit lê um limite positivo de uma variável de ambiente.
+func graphqlLimit(envVar string, fallback int) int {
+	if raw := os.Getenv(envVar); raw != "" {
+		if limit, err := strconv.Atoi(raw); err == nil && limit > 0 {
+			return limit
+		}
+		log.Printf("Warning: invalid %s %q, using %d", envVar, raw, fallback)
+	}
+	return fallback
+}
+
+// graphqlCost calcula a complexidade e a profundidade de uma operação antes da execução.
+// Cada campo custa 1; o custo dos campos de uma lista é multiplicado pelo tamanho esperado
+// da lista (o argumento "first" ou defaultGraphQLListSize).
+type graphqlCost struct {
+	schema    graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	visiting  map[string]bool // Fragmentos sendo percorridos, para não entrar em ciclos
+}
+
+// analyzeGraphQLOperation retorna a complexidade e a profundidade da operação escolhida
+// (operationName, ou a única do documento). Operações inexistentes têm custo zero e são
+// rejeitadas depois, na validação da própria execução.
+func analyzeGraphQLOperation(schema graphql.Schema, document *ast.Document, operationName string, variables map[string]interface{}) (complexity, depth int) {
+	cost := graphqlCost{
+		schema:    schema,
+		fragments: make(map[string]*ast.FragmentDefinition),
+		variables: variables,
+		visiting:  make(map[string]bool),
+	}
+	var operation *ast.OperationDefinition
+	for _, definition := range document.Definitions {
+		switch definition := definition.(type) {
+		case *ast.FragmentDefinition:
+			cost.fragments[definition.Name.Value] = definition
+		case *ast.OperationDefinition:
+			if operationName == "" || (definition.Name != nil && definition.Name.Value == operationName) {
+				operation = definition
+			}
+		}
+	}
+	if operation == nil {
+		return 0, 0
+	}
+
+	root := schema.QueryType()
+	if operation.Operation == ast.OperationTypeMutation {
+		root = schema.MutationType()
+	}
+	return cost.selectionSet(operation.SelectionSet, root)
+}
+
+// selectionSet soma o custo dos campos selecionados em um objeto do tipo parent.
+func (cost *graphqlCost) selectionSet(set *ast.SelectionSet, parent graphql.Type) (complexity, depth int) {
+	if set == nil {
+		return 0, 0
+	}
+	for _, selection := range set.Selections {
+		var c, d int
+		switch selection := selection.(type) {
+		case *ast.Field:
+			c, d = cost.field(selection, parent)
+		case *ast.InlineFragment:
+			c, d = cost.selectionSet(selection.SelectionSet, parent)
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			fragment, found := cost.fragments[name]
+			if !found || cost.visiting[name] {
+				continue
+			}
+			cost.visiting[name] = true
+			c, d = cost.selectionSet(fragment.SelectionSet, parent)
+			cost.visiting[name] = false
+		}
+		complexity += c
+		if d > depth {
+			depth = d
+		}
+	}
+	return complexity, depth
+}
+
+// field calcula o custo de um campo e de seus subcampos.
+func (cost *graphqlCost) field(field *ast.Field, parent graphql.Type) (complexity, depth int) {
+	var fieldType graphql.Type
+	if object, ok := parent.(*graphql.Object); ok {
+		if definition, found := object.Fields()[field.Name.Value]; found {
+			fieldType = definition.Type
+		}
+	}
+
+	multiplier := 1
+	for unwrapping := true; unwrapping; {
+		switch wrapped := fieldType.(type) {
+		case *graphql.NonNull:
+			fieldType = wrapped.OfType
+		case *graphql.List:
+			multiplier = cost.listSize(field)
+			fieldType = wrapped.OfType
+		default:
+			unwrapping = false
+		}
+	}
+
+	childComplexity, childDepth := cost.selectionSet(field.SelectionSet, fieldType)
+	return 1 + multiplier*childComplexity, 1 + childDepth
+}
+
+// listSize é o tamanho esperado de uma lista: o argumento "first" (literal ou variável) ou o padrão.
+func (cost *graphqlCost) listSize(field *ast.Field) int {
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != "first" {
+			continue
+		}
+		var raw string
+		switch value := argument.Value.(type) {
+		case *ast.IntValue:
+			raw = value.Value
+		case *ast.Variable:
+			raw = fmt.Sprint(cost.variables[value.Name.Value])
+		}
+		if size, err := strconv.Atoi(raw); err == nil && size > 0 {
+			if size > maxExpensePageSize {
+				return maxExpensePageSize
+			}
+			return size
+		}
+	}
+	return defaultGraphQLListSize
+}
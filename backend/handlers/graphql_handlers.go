@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// GraphQLRequest é o corpo de uma requisição GraphQL
+type GraphQLRequest struct {
+	Query         string                 `json:"query" binding:"required"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// graphqlErrorResponse responde no formato de erro do GraphQL ({"errors": [{"message": ...}]}).
+func graphqlErrorResponse(c *gin.Context, status int, message string) {
+	c.JSON(status, gin.H{"errors": []gin.H{{"message": message}}})
+}
+
+// GraphQLHandler executa consultas e mutações GraphQL sobre os dados do usuário autenticado
+// (perfil, renda, despesas fixas e variáveis, saldo e projeção). Consultas acima dos limites de
+// complexidade (GRAPHQL_MAX_COMPLEXITY) ou profundidade (GRAPHQL_MAX_DEPTH) são rejeitadas com 400
+// antes da execução.
+func GraphQLHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var request GraphQLRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		graphqlErrorResponse(c, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	document, err := parser.Parse(parser.ParseParams{Source: request.Query})
+	if err != nil {
+		graphqlErrorResponse(c, http.StatusBadRequest, "Invalid query: "+err.Error())
+		return
+	}
+	complexity, depth := analyzeGraphQLOperation(graphqlSchema, document, request.OperationName, request.Variables)
+	if maxDepth := graphqlLimit("GRAPHQL_MAX_DEPTH", defaultGraphQLMaxDepth); depth > maxDepth {
+		graphqlErrorResponse(c, http.StatusBadRequest, "Query depth "+strconv.Itoa(depth)+" exceeds the maximum of "+strconv.Itoa(maxDepth))
+		return
+	}
+	if maxComplexity := graphqlLimit("GRAPHQL_MAX_COMPLEXITY", defaultGraphQLMaxComplexity); complexity > maxComplexity {
+		graphqlErrorResponse(c, http.StatusBadRequest, "Query complexity "+strconv.Itoa(complexity)+" exceeds the maximum of "+strconv.Itoa(maxComplexity))
+		return
+	}
+
+	ctx := context.WithValue(c.Request.Context(), graphqlUserIDKey, userID)
+	result := graphql.Do(graphql.Params{
+		Schema:         graphqlSchema,
+		RequestString:  request.Query,
+		VariableValues: request.Variables,
+		OperationName:  request.OperationName,
+		Context:        ctx,
+	})
+	if len(result.Errors) > 0 {
+		log.Printf("GraphQL request from user %d finished with %d error(s): %v", userID, len(result.Errors), result.Errors[0].Message)
+	}
+	c.JSON(http.StatusOK, result)
+}
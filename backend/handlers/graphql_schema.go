@@ -0,0 +1,588 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"personal-finance-app/backend/apierrors"
+	"personal-finance-app/backend/database"
+	"personal-finance-app/backend/models"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/graphql-go/graphql"
+	"gorm.io/gorm"
+)
+
+// defaultGraphQLListSize é a quantidade de itens retornada por listas sem o argumento "first".
+const defaultGraphQLListSize = 20
+
+// graphqlContextKey é o tipo das chaves guardadas no context.Context das consultas GraphQL.
+type graphqlContextKey string
+
+// graphqlUserIDKey guarda o ID do usuário autenticado (definido pelo AuthMiddleware).
+const graphqlUserIDKey graphqlContextKey = "userID"
+
+// errGraphQLInternal é a mensagem exposta ao cliente para erros inesperados (o detalhe vai para o log).
+var errGraphQLInternal = errors.New("internal server error")
+
+// graphqlUser é a representação do usuário autenticado na API GraphQL
+type graphqlUser struct {
+	ID    uint   `json:"id"`
+	Email string `json:"email"`
+}
+
+// CategoryTotal é o total gasto no mês em uma categoria
+type CategoryTotal struct {
+	Category string  `json:"category"`
+	Total    float64 `json:"total"`
+}
+
+// graphqlUserID lê o usuário autenticado do contexto da consulta.
+func graphqlUserID(ctx context.Context) uint {
+	userID, _ := ctx.Value(graphqlUserIDKey).(uint)
+	return userID
+}
+
+// parseGraphQLID converte um argumento do tipo ID (string ou número) em ID do banco.
+func parseGraphQLID(value interface{}) (uint, error) {
+	id, err := strconv.ParseUint(fmt.Sprint(value), 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ID %q", fmt.Sprint(value))
+	}
+	return uint(id), nil
+}
+
+// graphqlAuthorizationError traduz erros de autorização em erros GraphQL, como respondAuthorizationError.
+func graphqlAuthorizationError(err error, notFoundMessage string) error {
+	switch {
+	case errors.Is(err, errNotHouseholdMember), errors.Is(err, errNotRecordOwner):
+		return errors.New(notFoundMessage)
+	case errors.Is(err, errInsufficientRole):
+		return errors.New("your household role does not allow this action")
+	default:
+		log.Printf("Error checking household membership: %v", err)
+		return errGraphQLInternal
+	}
+}
+
+// graphqlServiceResult adapta o retorno das funções compartilhadas com a API REST: erros de
+// negócio (*apierrors.Error) são expostos ao cliente, com o código e os detalhes em "extensions";
+// os demais vão para o log.
+func graphqlServiceResult(result interface{}, err error) (interface{}, error) {
+	if err == nil {
+		return result, nil
+	}
+	var serviceErr *apierrors.Error
+	if errors.As(err, &serviceErr) {
+		return nil, err
+	}
+	log.Printf("Error resolving GraphQL field: %v", err)
+	return nil, errGraphQLInternal
+}
+
+// graphqlScope monta o escopo da consulta a partir do argumento opcional householdId.
+func graphqlScope(p graphql.ResolveParams, minRole string) (ownerScope, error) {
+	scope := ownerScope{UserID: graphqlUserID(p.Context)}
+	raw, ok := p.Args["householdId"]
+	if !ok || raw == nil {
+		return scope, nil
+	}
+	householdID, err := parseGraphQLID(raw)
+	if err != nil {
+		return scope, err
+	}
+	if _, err := authorizeHousehold(scope.UserID, householdID, minRole); err != nil {
+		return scope, graphqlAuthorizationError(err, "household not found or you are not a member")
+	}
+	scope.HouseholdID = &householdID
+	return scope, nil
+}
+
+// graphqlListSize lê o argumento "first", limitado ao tamanho máximo de página da API REST.
+func graphqlListSize(p graphql.ResolveParams) int {
+	first, ok := p.Args["first"].(int)
+	if !ok || first < 1 {
+		return defaultGraphQLListSize
+	}
+	if first > maxExpensePageSize {
+		return maxExpensePageSize
+	}
+	return first
+}
+
+// householdIDField resolve o campo householdId (ponteiro) dos tipos de despesa.
+var householdIDField = &graphql.Field{
+	Type: graphql.ID,
+	Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		var householdID *uint
+		switch source := p.Source.(type) {
+		case ExpenseResponse:
+			householdID = source.HouseholdID
+		case FixedExpenseResponse:
+			householdID = source.HouseholdID
+		}
+		if householdID == nil {
+			return nil, nil
+		}
+		return *householdID, nil
+	},
+}
+
+var expenseSplitType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "ExpenseSplit",
+	Fields: graphql.Fields{
+		"category": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"value":    &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+		"note":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+	},
+})
+
+var variableExpenseType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "VariableExpense",
+	Description: "Despesa variável, opcionalmente dividida entre categorias",
+	Fields: graphql.Fields{
+		"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+		"userId":      &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+		"householdId": householdIDField,
+		"value":       &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+		"category":    &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"description": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"date":        &graphql.Field{Type: graphql.NewNonNull(graphql.String), Description: "Formato YYYY-MM-DD"},
+		"splits":      &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(expenseSplitType)))},
+		"version":     &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"clientId":    &graphql.Field{Type: graphql.String},
+		"createdAt":   &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+		"updatedAt":   &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+	},
+})
+
+var fixedExpenseType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "FixedExpense",
+	Description: "Despesa fixa mensal",
+	Fields: graphql.Fields{
+		"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+		"userId":      &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+		"householdId": householdIDField,
+		"name":        &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"value":       &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+		"dueDay":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Description: "0 se não informado"},
+		"category":    &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"active":      &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+		"version":     &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"createdAt":   &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+		"updatedAt":   &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+	},
+})
+
+var incomeType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "Income",
+	Description: "Renda mensal do usuário",
+	Fields: graphql.Fields{
+		"id":            &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+		"monthlyIncome": &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+		"version":       &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"createdAt":     &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+		"updatedAt":     &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+	},
+})
+
+var projectionType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "Projection",
+	Description: "Projeção de fim de mês (ausente sem dados suficientes para o modelo de previsão)",
+	Fields: graphql.Fields{
+		"endOfMonthBalance":         &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+		"projectedVariableExpenses": &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+		"projectedTotalExpenses":    &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+		"yellowAlertDay":            &graphql.Field{Type: graphql.String},
+		"redAlertDay":               &graphql.Field{Type: graphql.String},
+		"gmdVariableExpenses":       &graphql.Field{Type: graphql.NewNonNull(graphql.Float), Description: "Gasto médio diário de despesas variáveis"},
+	},
+})
+
+var categoryTotalType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "CategoryTotal",
+	Fields: graphql.Fields{
+		"category": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"total":    &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+	},
+})
+
+var budgetStatusType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "BudgetStatus",
+	Fields: graphql.Fields{
+		"category":    &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"limit":       &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+		"spent":       &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+		"remaining":   &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+		"percentUsed": &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+	},
+})
+
+var balanceType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "Balance",
+	Description: "Saldo atual e projeção do mês, os mesmos dados de GET /balance",
+	Fields: graphql.Fields{
+		"currentBalance":             &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+		"totalIncome":                &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+		"totalFixedExpenses":         &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+		"totalVariableExpensesMonth": &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+		"projection":                 &graphql.Field{Type: projectionType},
+		"financialHealthStatus":      &graphql.Field{Type: graphql.NewNonNull(graphql.String), Description: "verde, amarelo ou vermelho"},
+		"healthPercentage":           &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+		"categoryTotals": &graphql.Field{
+			Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(categoryTotalType))),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				balance := p.Source.(BalanceResponse)
+				totals := make([]CategoryTotal, 0, len(balance.CategoryTotals))
+				for category, total := range balance.CategoryTotals {
+					totals = append(totals, CategoryTotal{Category: category, Total: total})
+				}
+				sort.Slice(totals, func(i, j int) bool { return totals[i].Total > totals[j].Total })
+				return totals, nil
+			},
+		},
+		"budgets": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(budgetStatusType)))},
+	},
+})
+
+// expenseListArgs são os filtros aceitos pelas listas de despesas variáveis.
+var expenseListArgs = graphql.FieldConfigArgument{
+	"from":     &graphql.ArgumentConfig{Type: graphql.String, Description: "YYYY-MM-DD, inclusivo"},
+	"to":       &graphql.ArgumentConfig{Type: graphql.String, Description: "YYYY-MM-DD, inclusivo"},
+	"category": &graphql.ArgumentConfig{Type: graphql.String, Description: "Considera as linhas de divisão"},
+	"first":    &graphql.ArgumentConfig{Type: graphql.Int, Description: "Máximo de itens (padrão 20, até 200)"},
+}
+
+// resolveExpenses lista as despesas variáveis mais recentes do escopo, com os filtros de expenseListArgs.
+func resolveExpenses(p graphql.ResolveParams, scope ownerScope) (interface{}, error) {
+	query := scope.apply(database.DB.Preload("Splits"))
+	if from, ok := p.Args["from"].(string); ok && from != "" {
+		fromDate, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			return nil, errors.New("invalid 'from' date format, use YYYY-MM-DD")
+		}
+		query = query.Where("date >= ?", fromDate)
+	}
+	if to, ok := p.Args["to"].(string); ok && to != "" {
+		toDate, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			return nil, errors.New("invalid 'to' date format, use YYYY-MM-DD")
+		}
+		query = query.Where("date < ?", toDate.AddDate(0, 0, 1))
+	}
+	if category, ok := p.Args["category"].(string); ok && category != "" {
+		query = query.Where("category = ? OR id IN (SELECT variable_expense_id FROM expense_splits WHERE category = ?)", category, category)
+	}
+
+	var expenses []models.VariableExpense
+	if err := query.Order("date desc, id desc").Limit(graphqlListSize(p)).Find(&expenses).Error; err != nil {
+		log.Printf("Error listing expenses via GraphQL for user %d: %v", scope.UserID, err)
+		return nil, errGraphQLInternal
+	}
+	response := make([]ExpenseResponse, 0, len(expenses))
+	for _, expense := range expenses {
+		response = append(response, toExpenseResponse(expense))
+	}
+	return response, nil
+}
+
+// resolveFixedExpenses lista as despesas fixas do escopo, opcionalmente filtradas por "active".
+func resolveFixedExpenses(p graphql.ResolveParams, scope ownerScope) (interface{}, error) {
+	query := scope.apply(database.DB)
+	if active, ok := p.Args["active"].(bool); ok {
+		query = query.Where("active = ?", active)
+	}
+	var fixedExpenses []models.FixedExpense
+	if err := query.Order("due_day, id").Find(&fixedExpenses).Error; err != nil {
+		log.Printf("Error listing fixed expenses via GraphQL for user %d: %v", scope.UserID, err)
+		return nil, errGraphQLInternal
+	}
+	response := make([]FixedExpenseResponse, 0, len(fixedExpenses))
+	for _, fe := range fixedExpenses {
+		response = append(response, toFixedExpenseResponse(fe))
+	}
+	return response, nil
+}
+
+// resolveIncome retorna a renda mensal do usuário, ou null se ainda não cadastrada.
+func resolveIncome(userID uint) (interface{}, error) {
+	var income models.Income
+	if err := database.DB.Where("user_id = ?", userID).First(&income).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		log.Printf("Error fetching income via GraphQL for user %d: %v", userID, err)
+		return nil, errGraphQLInternal
+	}
+	return toIncomeResponse(income), nil
+}
+
+var userType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "User",
+	Description: "Usuário autenticado e seus dados pessoais",
+	Fields: graphql.Fields{
+		"id":    &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+		"email": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"income": &graphql.Field{
+			Type: incomeType,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return resolveIncome(p.Source.(graphqlUser).ID)
+			},
+		},
+		"fixedExpenses": &graphql.Field{
+			Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(fixedExpenseType))),
+			Args: graphql.FieldConfigArgument{"active": &graphql.ArgumentConfig{Type: graphql.Boolean}},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return resolveFixedExpenses(p, ownerScope{UserID: p.Source.(graphqlUser).ID})
+			},
+		},
+		"variableExpenses": &graphql.Field{
+			Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(variableExpenseType))),
+			Args: expenseListArgs,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return resolveExpenses(p, ownerScope{UserID: p.Source.(graphqlUser).ID})
+			},
+		},
+	},
+})
+
+// householdArg é o argumento opcional que troca o escopo pessoal pelo de um domicílio.
+var householdArg = &graphql.ArgumentConfig{Type: graphql.ID, Description: "Consulta os dados de um domicílio do qual o usuário é membro"}
+
+var queryType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Query",
+	Fields: graphql.Fields{
+		"me": &graphql.Field{
+			Type: graphql.NewNonNull(userType),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				var user models.User
+				if err := database.DB.First(&user, graphqlUserID(p.Context)).Error; err != nil {
+					if errors.Is(err, gorm.ErrRecordNotFound) {
+						return nil, errors.New("user not found")
+					}
+					log.Printf("Error fetching user via GraphQL: %v", err)
+					return nil, errGraphQLInternal
+				}
+				return graphqlUser{ID: user.ID, Email: user.Email}, nil
+			},
+		},
+		"balance": &graphql.Field{
+			Type: balanceType,
+			Args: graphql.FieldConfigArgument{"householdId": householdArg},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				var householdID *uint
+				if raw, ok := p.Args["householdId"]; ok && raw != nil {
+					id, err := parseGraphQLID(raw)
+					if err != nil {
+						return nil, err
+					}
+					householdID = &id
+				}
+				return graphqlServiceResult(GetBalance(graphqlUserID(p.Context), householdID))
+			},
+		},
+		"expenses": &graphql.Field{
+			Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(variableExpenseType))),
+			Args: graphql.FieldConfigArgument{
+				"householdId": householdArg,
+				"from":        expenseListArgs["from"],
+				"to":          expenseListArgs["to"],
+				"category":    expenseListArgs["category"],
+				"first":       expenseListArgs["first"],
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				scope, err := graphqlScope(p, models.HouseholdRoleViewer)
+				if err != nil {
+					return nil, err
+				}
+				return resolveExpenses(p, scope)
+			},
+		},
+		"fixedExpenses": &graphql.Field{
+			Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(fixedExpenseType))),
+			Args: graphql.FieldConfigArgument{
+				"householdId": householdArg,
+				"active":      &graphql.ArgumentConfig{Type: graphql.Boolean},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				scope, err := graphqlScope(p, models.HouseholdRoleViewer)
+				if err != nil {
+					return nil, err
+				}
+				return resolveFixedExpenses(p, scope)
+			},
+		},
+	},
+})
+
+var expenseSplitInputType = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name: "ExpenseSplitInput",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"category": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		"value":    &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Float)},
+		"note":     &graphql.InputObjectFieldConfig{Type: graphql.String},
+	},
+})
+
+var expenseInputType = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name:        "ExpenseInput",
+	Description: "Mesmos campos do corpo de POST /expenses",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"value":       &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Float)},
+		"category":    &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"description": &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"date":        &graphql.InputObjectFieldConfig{Type: graphql.String, Description: "YYYY-MM-DD; padrão hoje"},
+		"splits":      &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(expenseSplitInputType))},
+		"householdId": &graphql.InputObjectFieldConfig{Type: graphql.ID},
+	},
+})
+
+// expensePayloadFromInput converte o argumento "input" em CreateExpensePayload, com as mesmas
+// validações e o mesmo erro (INVALID_REQUEST_BODY, com os detalhes por campo) da API REST.
+func expensePayloadFromInput(input map[string]interface{}) (CreateExpensePayload, error) {
+	var payload CreateExpensePayload
+	if raw, ok := input["householdId"]; ok && raw != nil {
+		householdID, err := parseGraphQLID(raw)
+		if err != nil {
+			return payload, err
+		}
+		input["householdId"] = householdID
+	}
+	encoded, err := json.Marshal(input)
+	if err != nil {
+		return payload, err
+	}
+	if err := json.Unmarshal(encoded, &payload); err != nil {
+		return payload, apierrors.Invalid(err)
+	}
+	if err := binding.Validator.ValidateStruct(&payload); err != nil {
+		return payload, apierrors.Invalid(err)
+	}
+	return payload, nil
+}
+
+// loadGraphQLEditableExpense busca a despesa pelo argumento "id" e verifica se o usuário pode alterá-la.
+func loadGraphQLEditableExpense(p graphql.ResolveParams) (models.VariableExpense, error) {
+	var expense models.VariableExpense
+	expenseID, err := parseGraphQLID(p.Args["id"])
+	if err != nil {
+		return expense, err
+	}
+	if err := database.DB.Preload("Splits").First(&expense, expenseID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return expense, errors.New("expense not found")
+		}
+		log.Printf("Error fetching expense %d via GraphQL: %v", expenseID, err)
+		return expense, errGraphQLInternal
+	}
+	if err := authorizeRecord(graphqlUserID(p.Context), expense.UserID, expense.HouseholdID, models.HouseholdRoleEditor); err != nil {
+		return expense, graphqlAuthorizationError(err, "expense not found")
+	}
+	return expense, nil
+}
+
+// staleExpenseError informa a versão atual de uma despesa alterada por outra requisição.
+func staleExpenseError(expenseID uint) error {
+	var current models.VariableExpense
+	database.DB.Select("version").First(&current, expenseID)
+	return fmt.Errorf("the expense was modified by another request (current version %d)", current.Version)
+}
+
+var mutationType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Mutation",
+	Fields: graphql.Fields{
+		"createExpense": &graphql.Field{
+			Type: graphql.NewNonNull(variableExpenseType),
+			Args: graphql.FieldConfigArgument{"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(expenseInputType)}},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				payload, err := expensePayloadFromInput(p.Args["input"].(map[string]interface{}))
+				if err != nil {
+					return nil, err
+				}
+				return graphqlServiceResult(CreateExpense(graphqlUserID(p.Context), payload))
+			},
+		},
+		"updateExpense": &graphql.Field{
+			Type:        graphql.NewNonNull(variableExpenseType),
+			Description: "Substitui os dados da despesa (como PUT /expenses/{id}) se a versão informada ainda for a atual",
+			Args: graphql.FieldConfigArgument{
+				"id":      &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				"version": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				"input":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(expenseInputType)},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				expense, err := loadGraphQLEditableExpense(p)
+				if err != nil {
+					return nil, err
+				}
+				payload, err := expensePayloadFromInput(p.Args["input"].(map[string]interface{}))
+				if err != nil {
+					return nil, err
+				}
+				if payload.HouseholdID != nil && (expense.HouseholdID == nil || *payload.HouseholdID != *expense.HouseholdID) {
+					return nil, errors.New("moving an expense to another household is not supported")
+				}
+				if err := applyExpensePayload(&expense, payload, expense.Date); err != nil {
+					return nil, err
+				}
+
+				readVersion := uint(p.Args["version"].(int))
+				err = database.DB.Transaction(func(tx *gorm.DB) error {
+					return writeExpenseUpdate(tx, &expense, readVersion)
+				})
+				if errors.Is(err, errStaleVersion) {
+					return nil, staleExpenseError(expense.ID)
+				}
+				if err != nil {
+					log.Printf("Error updating expense %d via GraphQL: %v", expense.ID, err)
+					return nil, errGraphQLInternal
+				}
+				return toExpenseResponse(expense), nil
+			},
+		},
+		"deleteExpense": &graphql.Field{
+			Type:        graphql.NewNonNull(graphql.Boolean),
+			Description: "Envia a despesa para a lixeira; com version, somente se ela ainda for a atual",
+			Args: graphql.FieldConfigArgument{
+				"id":      &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				"version": &graphql.ArgumentConfig{Type: graphql.Int},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				expense, err := loadGraphQLEditableExpense(p)
+				if err != nil {
+					return nil, err
+				}
+				query := database.DB
+				if version, ok := p.Args["version"].(int); ok {
+					query = query.Where("version = ?", version)
+				}
+				result := query.Delete(&expense)
+				if result.Error != nil {
+					log.Printf("Error deleting expense %d via GraphQL: %v", expense.ID, result.Error)
+					return nil, errGraphQLInternal
+				}
+				if result.RowsAffected == 0 {
+					return nil, staleExpenseError(expense.ID)
+				}
+				publishDeletedExpense(expense)
+				return true, nil
+			},
+		},
+	},
+})
+
+// graphqlSchema é o schema da API GraphQL (POST /graphql).
+var graphqlSchema = mustGraphQLSchema()
+
+func mustGraphQLSchema() graphql.Schema {
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{Query: queryType, Mutation: mutationType})
+	if err != nil {
+		panic("invalid GraphQL schema: " + err.Error())
+	}
+	return schema
+}
@@ -0,0 +1,468 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"personal-finance-app/backend/apierrors"
+	"personal-finance-app/backend/database"
+	"personal-finance-app/backend/models"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// invitationTTL é o prazo de validade de um convite para um domicílio.
+const invitationTTL = 7 * 24 * time.Hour
+
+// CreateHouseholdPayload define a estrutura para criar um domicílio
+type CreateHouseholdPayload struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// InviteMemberPayload define a estrutura para convidar um membro por e-mail
+type InviteMemberPayload struct {
+	Email string `json:"email" binding:"required"`
+	Role  string `json:"role" binding:"required,oneof=editor viewer"`
+}
+
+// UpdateMemberRolePayload define a estrutura para alterar o papel de um membro
+type UpdateMemberRolePayload struct {
+	Role string `json:"role" binding:"required,oneof=owner editor viewer"`
+}
+
+// HouseholdResponse representa um domicílio do ponto de vista do usuário autenticado
+type HouseholdResponse struct {
+	ID      uint                      `json:"id"`
+	Name    string                    `json:"name"`
+	OwnerID uint                      `json:"ownerId"`
+	Role    string                    `json:"role"` // Papel do usuário autenticado
+	Members []HouseholdMemberResponse `json:"members,omitempty"`
+}
+
+// HouseholdMemberResponse representa um membro de um domicílio
+type HouseholdMemberResponse struct {
+	UserID uint   `json:"userId"`
+	Email  string `json:"email"`
+	Role   string `json:"role"`
+}
+
+// CreateHouseholdHandler cria um domicílio tendo o usuário autenticado como dono
+func CreateHouseholdHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var payload CreateHouseholdPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		apierrors.RespondInvalid(c, err)
+		return
+	}
+
+	household := models.Household{Name: payload.Name, OwnerID: userID}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&household).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.HouseholdMember{
+			HouseholdID: household.ID,
+			UserID:      userID,
+			Role:        models.HouseholdRoleOwner,
+		}).Error
+	})
+	if err != nil {
+		log.Printf("Error creating household for user %d: %v", userID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Household created successfully", "household": HouseholdResponse{
+		ID:      household.ID,
+		Name:    household.Name,
+		OwnerID: household.OwnerID,
+		Role:    models.HouseholdRoleOwner,
+	}})
+}
+
+// ListHouseholdsHandler lista os domicílios dos quais o usuário autenticado é membro
+func ListHouseholdsHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var memberships []models.HouseholdMember
+	if err := database.DB.Where("user_id = ?", userID).Find(&memberships).Error; err != nil {
+		log.Printf("Error listing households for user %d: %v", userID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+
+	roles := make(map[uint]string)
+	var householdIDs []uint
+	for _, m := range memberships {
+		roles[m.HouseholdID] = m.Role
+		householdIDs = append(householdIDs, m.HouseholdID)
+	}
+
+	var households []models.Household
+	if len(householdIDs) > 0 {
+		if err := database.DB.Where("id IN ?", householdIDs).Order("id").Find(&households).Error; err != nil {
+			log.Printf("Error listing households for user %d: %v", userID, err)
+			apierrors.RespondInternal(c)
+			return
+		}
+	}
+
+	response := make([]HouseholdResponse, 0, len(households))
+	for _, h := range households {
+		response = append(response, HouseholdResponse{ID: h.ID, Name: h.Name, OwnerID: h.OwnerID, Role: roles[h.ID]})
+	}
+	c.JSON(http.StatusOK, gin.H{"households": response})
+}
+
+// GetHouseholdHandler retorna um domicílio e seus membros
+func GetHouseholdHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	householdID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	member, err := authorizeHousehold(userID, householdID, models.HouseholdRoleViewer)
+	if err != nil {
+		respondAuthorizationError(c, err, apierrors.HouseholdNotFound)
+		return
+	}
+
+	var household models.Household
+	if err := database.DB.Preload("Members.User").First(&household, householdID).Error; err != nil {
+		log.Printf("Error fetching household %d: %v", householdID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+
+	response := HouseholdResponse{ID: household.ID, Name: household.Name, OwnerID: household.OwnerID, Role: member.Role}
+	for _, m := range household.Members {
+		response.Members = append(response.Members, HouseholdMemberResponse{UserID: m.UserID, Email: m.User.Email, Role: m.Role})
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteHouseholdHandler remove um domicílio (apenas o dono).
+// As despesas do domicílio permanecem no banco, mas deixam de ser acessíveis, e os convites pendentes
+// deixam de poder ser aceitos.
+func DeleteHouseholdHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	householdID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if _, err := authorizeHousehold(userID, householdID, models.HouseholdRoleOwner); err != nil {
+		respondAuthorizationError(c, err, apierrors.HouseholdNotFound)
+		return
+	}
+
+	if err := database.DB.Delete(&models.Household{}, householdID).Error; err != nil {
+		log.Printf("Error deleting household %d: %v", householdID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Household deleted successfully"})
+}
+
+// InviteHouseholdMemberHandler convida um usuário por e-mail para o domicílio (apenas o dono)
+func InviteHouseholdMemberHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	householdID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var payload InviteMemberPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		apierrors.RespondInvalid(c, err)
+		return
+	}
+	email := strings.ToLower(strings.TrimSpace(payload.Email))
+	if !EmailRegex.MatchString(email) {
+		apierrors.Respond(c, http.StatusBadRequest, apierrors.InvalidEmail)
+		return
+	}
+
+	if _, err := authorizeHousehold(userID, householdID, models.HouseholdRoleOwner); err != nil {
+		respondAuthorizationError(c, err, apierrors.HouseholdNotFound)
+		return
+	}
+
+	// Não convidar quem já é membro
+	var existing int64
+	database.DB.Model(&models.HouseholdMember{}).
+		Joins("JOIN users ON users.id = household_members.user_id").
+		Where("household_members.household_id = ? AND LOWER(users.email) = ?", householdID, email).
+		Count(&existing)
+	if existing > 0 {
+		apierrors.Respond(c, http.StatusConflict, apierrors.AlreadyHouseholdMember)
+		return
+	}
+
+	invitation := models.HouseholdInvitation{
+		HouseholdID: householdID,
+		Email:       email,
+		Role:        payload.Role,
+		InvitedByID: userID,
+		ExpiresAt:   time.Now().Add(invitationTTL),
+	}
+	if err := database.DB.Create(&invitation).Error; err != nil {
+		log.Printf("Error creating invitation for household %d: %v", householdID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+
+	// Simular envio de e-mail (log por enquanto)
+	log.Printf("Household invitation %d for %s to household %d (Simulated email send)", invitation.ID, email, householdID)
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Invitation sent (simulated).", "invitation": invitationDTO(c, invitation)})
+}
+
+// ListInvitationsHandler lista os convites pendentes enviados para o e-mail do usuário autenticado
+func ListInvitationsHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		apierrors.Respond(c, http.StatusNotFound, apierrors.UserNotFound)
+		return
+	}
+
+	var invitations []models.HouseholdInvitation
+	if err := pendingInvitations().Where("LOWER(household_invitations.email) = ? AND household_invitations.expires_at > ?", strings.ToLower(user.Email), time.Now()).
+		Order("household_invitations.created_at desc").Find(&invitations).Error; err != nil {
+		log.Printf("Error listing invitations for user %d: %v", userID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"invitations": invitationsDTO(c, invitations)})
+}
+
+// pendingInvitations consulta os convites ainda não aceitos de domicílios que não foram removidos.
+func pendingInvitations() *gorm.DB {
+	return database.DB.Joins("JOIN households ON households.id = household_invitations.household_id AND households.deleted_at IS NULL").
+		Where("household_invitations.accepted_at IS NULL")
+}
+
+// findPendingInvitation busca um convite pendente endereçado ao e-mail do usuário.
+func findPendingInvitation(userID, invitationID uint) (*models.HouseholdInvitation, error) {
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	var invitation models.HouseholdInvitation
+	err := pendingInvitations().Where("household_invitations.id = ? AND LOWER(household_invitations.email) = ?", invitationID, strings.ToLower(user.Email)).
+		First(&invitation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// AcceptInvitationHandler aceita um convite, tornando o usuário membro do domicílio
+func AcceptInvitationHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	invitationID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	invitation, err := findPendingInvitation(userID, invitationID)
+	if err != nil {
+		apierrors.Respond(c, http.StatusNotFound, apierrors.InvitationNotFound)
+		return
+	}
+	if time.Now().After(invitation.ExpiresAt) {
+		apierrors.Respond(c, http.StatusGone, apierrors.InvitationExpired)
+		return
+	}
+
+	member := models.HouseholdMember{HouseholdID: invitation.HouseholdID, UserID: userID, Role: invitation.Role}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(invitation).Update("accepted_at", &now).Error; err != nil {
+			return err
+		}
+		// Se o usuário já for membro (ex: convite duplicado), mantém o vínculo existente
+		return tx.Where("household_id = ? AND user_id = ?", invitation.HouseholdID, userID).FirstOrCreate(&member).Error
+	})
+	if err != nil {
+		log.Printf("Error accepting invitation %d for user %d: %v", invitationID, userID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation accepted", "householdId": member.HouseholdID, "role": member.Role})
+}
+
+// DeclineInvitationHandler recusa (remove) um convite pendente
+func DeclineInvitationHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	invitationID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	invitation, err := findPendingInvitation(userID, invitationID)
+	if err != nil {
+		apierrors.Respond(c, http.StatusNotFound, apierrors.InvitationNotFound)
+		return
+	}
+	if err := database.DB.Delete(invitation).Error; err != nil {
+		log.Printf("Error declining invitation %d: %v", invitationID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation declined"})
+}
+
+// countOwners retorna quantos donos o domicílio possui.
+func countOwners(tx *gorm.DB, householdID uint) (int64, error) {
+	var owners int64
+	err := tx.Model(&models.HouseholdMember{}).
+		Where("household_id = ? AND role = ?", householdID, models.HouseholdRoleOwner).
+		Count(&owners).Error
+	return owners, err
+}
+
+// errLastOwner indica que a operação deixaria o domicílio sem dono.
+var errLastOwner = errors.New("household must keep at least one owner")
+
+// UpdateHouseholdMemberHandler altera o papel de um membro (apenas o dono)
+func UpdateHouseholdMemberHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	householdID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	memberUserID, ok := parseIDParam(c, "userId")
+	if !ok {
+		return
+	}
+
+	var payload UpdateMemberRolePayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		apierrors.RespondInvalid(c, err)
+		return
+	}
+
+	if _, err := authorizeHousehold(userID, householdID, models.HouseholdRoleOwner); err != nil {
+		respondAuthorizationError(c, err, apierrors.HouseholdNotFound)
+		return
+	}
+
+	var member models.HouseholdMember
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("household_id = ? AND user_id = ?", householdID, memberUserID).First(&member).Error; err != nil {
+			return err
+		}
+		if member.Role == models.HouseholdRoleOwner && payload.Role != models.HouseholdRoleOwner {
+			owners, err := countOwners(tx, householdID)
+			if err != nil {
+				return err
+			}
+			if owners <= 1 {
+				return errLastOwner
+			}
+		}
+		member.Role = payload.Role
+		return tx.Save(&member).Error
+	})
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		apierrors.Respond(c, http.StatusNotFound, apierrors.MemberNotFound)
+	case errors.Is(err, errLastOwner):
+		apierrors.Respond(c, http.StatusConflict, apierrors.LastHouseholdOwner)
+	case err != nil:
+		log.Printf("Error updating member %d of household %d: %v", memberUserID, householdID, err)
+		apierrors.RespondInternal(c)
+	default:
+		c.JSON(http.StatusOK, gin.H{"message": "Member updated successfully", "member": HouseholdMemberResponse{UserID: member.UserID, Role: member.Role}})
+	}
+}
+
+// RemoveHouseholdMemberHandler remove um membro do domicílio.
+// O dono pode remover qualquer membro; os demais podem apenas sair (remover a si mesmos).
+func RemoveHouseholdMemberHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	householdID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	memberUserID, ok := parseIDParam(c, "userId")
+	if !ok {
+		return
+	}
+
+	minRole := models.HouseholdRoleOwner
+	if memberUserID == userID {
+		minRole = models.HouseholdRoleViewer
+	}
+	if _, err := authorizeHousehold(userID, householdID, minRole); err != nil {
+		respondAuthorizationError(c, err, apierrors.HouseholdNotFound)
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var member models.HouseholdMember
+		if err := tx.Where("household_id = ? AND user_id = ?", householdID, memberUserID).First(&member).Error; err != nil {
+			return err
+		}
+		if member.Role == models.HouseholdRoleOwner {
+			owners, err := countOwners(tx, householdID)
+			if err != nil {
+				return err
+			}
+			if owners <= 1 {
+				return errLastOwner
+			}
+		}
+		return tx.Delete(&member).Error
+	})
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		apierrors.Respond(c, http.StatusNotFound, apierrors.MemberNotFound)
+	case errors.Is(err, errLastOwner):
+		apierrors.Respond(c, http.StatusConflict, apierrors.LastHouseholdOwner)
+	case err != nil:
+		log.Printf("Error removing member %d of household %d: %v", memberUserID, householdID, err)
+		apierrors.RespondInternal(c)
+	default:
+		c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+	}
+}
@@ -3,22 +3,30 @@ package handlers
 import (
 	"log"
 	"net/http"
+	"personal-finance-app/backend/apierrors"
 	"personal-finance-app/backend/database"
 	"personal-finance-app/backend/models"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
 )
 
 // IncomePayload define a estrutura para receber a renda mensal
 type IncomePayload struct {
 	MonthlyIncome float64 `json:"rendaMensal" binding:"required,gte=0"`
+	PayDay        int     `json:"diaPagamento" binding:"omitempty,min=1,max=31"` // Opcional, dia do pagamento
 }
 
 // FixedExpensePayload define a estrutura para uma despesa fixa individual
 type FixedExpensePayload struct {
-	Name  string  `json:"nome" binding:"required"`
-	Value float64 `json:"valor" binding:"required,gt=0"`
+	Name     string  `json:"nome" binding:"required"`
+	Value    float64 `json:"valor" binding:"required,gt=0"`
+	Category string  `json:"categoria"`                                      // Opcional (ex: vindo das sugestões do perfil)
+	DueDay   int     `json:"diaVencimento" binding:"omitempty,min=1,max=31"` // Opcional
 }
 
 // FixedExpensesPayload define a estrutura para receber a lista de despesas fixas
@@ -26,29 +34,355 @@ type FixedExpensesPayload struct {
 	Expenses []FixedExpensePayload `json:"despesasFixas" binding:"required,dive"`
 }
 
+// HouseholdProfilePayload define a estrutura da etapa de perfil do domicílio
+type HouseholdProfilePayload struct {
+	HouseholdProfile string `json:"householdProfile" binding:"required,oneof=solo casal familia compartilhado"`
+}
+
+// CategoriesPayload define a estrutura da etapa de categorias
+type CategoriesPayload struct {
+	Categories []string `json:"categories" binding:"required,min=1,dive,required"`
+}
+
+// BudgetPayload define o limite mensal de uma categoria
+type BudgetPayload struct {
+	Category string  `json:"category" binding:"required"`
+	Amount   float64 `json:"amount" binding:"required,gt=0"`
+}
+
+// FirstBudgetPayload define a estrutura da etapa do primeiro orçamento
+type FirstBudgetPayload struct {
+	Budgets []BudgetPayload `json:"budgets" binding:"required,min=1,dive"`
+}
+
+// SkipStepPayload define a etapa a ser pulada (opcional; padrão: etapa atual)
+type SkipStepPayload struct {
+	Step string `json:"step"`
+}
+
+// OnboardingStepStatus descreve a situação de uma etapa do onboarding
+type OnboardingStepStatus struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"` // "completed", "skipped" ou "pending"
+	Current   bool   `json:"current"`
+	Skippable bool   `json:"skippable"`
+}
+
+// OnboardingStatusResponse é o estado do onboarding retornado ao cliente.
+// Sugestões são incluídas de acordo com a etapa atual.
+type OnboardingStatusResponse struct {
+	CurrentStep           string                 `json:"currentStep"` // Vazio quando o onboarding foi concluído
+	Completed             bool                   `json:"completed"`
+	CompletedAt           *time.Time             `json:"completedAt"`
+	HouseholdProfile      string                 `json:"householdProfile"`
+	CanGoBack             bool                   `json:"canGoBack"`
+	Steps                 []OnboardingStepStatus `json:"steps"`
+	FixedExpenseTemplates []FixedExpenseTemplate `json:"fixedExpenseTemplates,omitempty"`
+	SuggestedCategories   []string               `json:"suggestedCategories,omitempty"`
+}
+
+// toOnboardingStatusResponse monta a resposta de status a partir do estado persistido.
+func toOnboardingStatusResponse(state *models.OnboardingState) OnboardingStatusResponse {
+	completed, skipped := stepSet(state.CompletedSteps), stepSet(state.SkippedSteps)
+	response := OnboardingStatusResponse{
+		CurrentStep:      state.CurrentStep,
+		Completed:        state.CurrentStep == "",
+		CompletedAt:      state.CompletedAt,
+		HouseholdProfile: state.HouseholdProfile,
+		CanGoBack:        state.CurrentStep != onboardingSteps[0],
+	}
+	for _, step := range onboardingSteps {
+		status := "pending"
+		if completed[step] {
+			status = "completed"
+		} else if skipped[step] {
+			status = "skipped"
+		}
+		response.Steps = append(response.Steps, OnboardingStepStatus{
+			Name:      step,
+			Status:    status,
+			Current:   step == state.CurrentStep,
+			Skippable: skippableOnboardingSteps[step],
+		})
+	}
+
+	switch state.CurrentStep {
+	case models.OnboardingStepFixedExpenses:
+		response.FixedExpenseTemplates = fixedExpenseTemplates[state.HouseholdProfile]
+	case models.OnboardingStepCategories:
+		response.SuggestedCategories = defaultCategories
+	}
+	return response
+}
+
+// GetOnboardingStatusHandler retorna em que etapa do onboarding o usuário está
+func GetOnboardingStatusHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	state, err := loadOnboardingState(database.DB, userID)
+	if err != nil {
+		log.Printf("Error loading onboarding state for user %d: %v", userID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+	c.JSON(http.StatusOK, toOnboardingStatusResponse(state))
+}
+
+// SaveProfileHandler lida com a etapa de perfil do domicílio (usado para sugerir despesas fixas)
+func SaveProfileHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var payload HouseholdProfilePayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		apierrors.RespondInvalid(c, err)
+		return
+	}
+
+	var state *models.OnboardingState
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if state, err = completeOnboardingStep(tx, userID, models.OnboardingStepProfile); err != nil {
+			return err
+		}
+		state.HouseholdProfile = payload.HouseholdProfile
+		return tx.Save(state).Error
+	})
+	if err != nil {
+		log.Printf("Error saving household profile for user %d: %v", userID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+	c.JSON(http.StatusOK, toOnboardingStatusResponse(state))
+}
+
+// SaveCategoriesHandler lida com a etapa de categorias, criando as que ainda não existem
+func SaveCategoriesHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var payload CategoriesPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		apierrors.RespondInvalid(c, err)
+		return
+	}
+	// Valida de novo sem os espaços: um nome só de espaços seria gravado vazio
+	for i := range payload.Categories {
+		payload.Categories[i] = strings.TrimSpace(payload.Categories[i])
+	}
+	if err := binding.Validator.ValidateStruct(&payload); err != nil {
+		apierrors.RespondInvalid(c, err)
+		return
+	}
+
+	var state *models.OnboardingState
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, name := range payload.Categories {
+			category := models.Category{UserID: userID, Name: name}
+			if err := tx.Where("user_id = ? AND name = ?", userID, category.Name).FirstOrCreate(&category).Error; err != nil {
+				return err
+			}
+		}
+		var err error
+		state, err = completeOnboardingStep(tx, userID, models.OnboardingStepCategories)
+		return err
+	})
+	if err != nil {
+		log.Printf("Error saving categories for user %d: %v", userID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+	c.JSON(http.StatusOK, toOnboardingStatusResponse(state))
+}
+
+// SaveFirstBudgetHandler lida com a etapa do primeiro orçamento (limites mensais por categoria)
+func SaveFirstBudgetHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var payload FirstBudgetPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		apierrors.RespondInvalid(c, err)
+		return
+	}
+	for i := range payload.Budgets {
+		payload.Budgets[i].Category = strings.TrimSpace(payload.Budgets[i].Category)
+	}
+	if err := binding.Validator.ValidateStruct(&payload); err != nil {
+		apierrors.RespondInvalid(c, err)
+		return
+	}
+
+	var state *models.OnboardingState
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, item := range payload.Budgets {
+			budget := models.Budget{UserID: userID, Category: item.Category}
+			if err := tx.Where("user_id = ? AND category = ?", userID, budget.Category).
+				Assign(models.Budget{Amount: item.Amount}).FirstOrCreate(&budget).Error; err != nil {
+				return err
+			}
+		}
+		var err error
+		state, err = completeOnboardingStep(tx, userID, models.OnboardingStepFirstBudget)
+		return err
+	})
+	if err != nil {
+		log.Printf("Error saving first budget for user %d: %v", userID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+	c.JSON(http.StatusOK, toOnboardingStatusResponse(state))
+}
+
+// SkipOnboardingStepHandler pula uma etapa opcional (por padrão, a etapa atual)
+func SkipOnboardingStepHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var payload SkipStepPayload
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			apierrors.RespondInvalid(c, err)
+			return
+		}
+	}
+
+	state, err := loadOnboardingState(database.DB, userID)
+	if err != nil {
+		log.Printf("Error loading onboarding state for user %d: %v", userID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+
+	step := payload.Step
+	if step == "" {
+		step = state.CurrentStep
+	}
+	if !isOnboardingStep(step) {
+		apierrors.Respond(c, http.StatusBadRequest, apierrors.UnknownOnboardingStep)
+		return
+	}
+	if !skippableOnboardingSteps[step] {
+		apierrors.Respond(c, http.StatusConflict, apierrors.OnboardingStepRequired)
+		return
+	}
+
+	completed, skipped := stepSet(state.CompletedSteps), stepSet(state.SkippedSteps)
+	if !completed[step] {
+		skipped[step] = true
+	}
+	state.SkippedSteps = joinSteps(skipped)
+	refreshCurrentStep(state)
+	if err := database.DB.Save(state).Error; err != nil {
+		log.Printf("Error skipping onboarding step for user %d: %v", userID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+	c.JSON(http.StatusOK, toOnboardingStatusResponse(state))
+}
+
+// OnboardingBackHandler volta para a etapa anterior à atual, para que o usuário revise seus dados.
+// Os dados já salvos são mantidos; ao reenviar a etapa, o onboarding segue para a próxima pendente.
+func OnboardingBackHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	state, err := loadOnboardingState(database.DB, userID)
+	if err != nil {
+		log.Printf("Error loading onboarding state for user %d: %v", userID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+
+	previous := onboardingSteps[len(onboardingSteps)-1] // Onboarding concluído: volta para a última etapa
+	for i, step := range onboardingSteps {
+		if step == state.CurrentStep {
+			if i == 0 {
+				apierrors.Respond(c, http.StatusConflict, apierrors.OnboardingAtFirstStep)
+				return
+			}
+			previous = onboardingSteps[i-1]
+		}
+	}
+
+	// A etapa anterior volta a ficar pendente para que seja exibida novamente
+	completed, skipped := stepSet(state.CompletedSteps), stepSet(state.SkippedSteps)
+	delete(completed, previous)
+	delete(skipped, previous)
+	state.CompletedSteps, state.SkippedSteps = joinSteps(completed), joinSteps(skipped)
+	state.CurrentStep = previous
+	state.CompletedAt = nil
+	if err := database.DB.Save(state).Error; err != nil {
+		log.Printf("Error moving onboarding back for user %d: %v", userID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+	c.JSON(http.StatusOK, toOnboardingStatusResponse(state))
+}
+
+// GetFixedExpenseTemplatesHandler retorna as despesas fixas sugeridas para um perfil de domicílio.
+// Sem ?profile=, usa o perfil informado pelo usuário no onboarding.
+func GetFixedExpenseTemplatesHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	profile := c.Query("profile")
+	if profile == "" {
+		state, err := loadOnboardingState(database.DB, userID)
+		if err != nil {
+			log.Printf("Error loading onboarding state for user %d: %v", userID, err)
+			apierrors.RespondInternal(c)
+			return
+		}
+		profile = state.HouseholdProfile
+	}
+
+	templates, known := fixedExpenseTemplates[profile]
+	if !known {
+		apierrors.Respond(c, http.StatusBadRequest, apierrors.UnknownHouseholdProfile)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"householdProfile": profile, "fixedExpenseTemplates": templates})
+}
+
 // SaveIncomeHandler lida com o salvamento da renda mensal do usuário
 func SaveIncomeHandler(c *gin.Context) {
 	userIDStr, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		apierrors.Respond(c, http.StatusUnauthorized, apierrors.Unauthenticated)
 		return
 	}
 	userID, err := strconv.ParseUint(userIDStr.(string), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
+		log.Printf("Invalid user ID format in token: %q", userIDStr)
+		apierrors.RespondInternal(c)
 		return
 	}
 
-	var payload IncomePayload
-	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+	payload, ok := bindIncomePayload(c)
+	if !ok {
 		return
 	}
 
 	// Verificar se o usuário existe (embora o token JWT já deva garantir isso)
 	var user models.User
 	if result := database.DB.First(&user, uint(userID)); result.Error != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		apierrors.Respond(c, http.StatusNotFound, apierrors.UserNotFound)
 		return
 	}
 
@@ -58,6 +392,8 @@ func SaveIncomeHandler(c *gin.Context) {
 	income := models.Income{
 		UserID:        uint(userID),
 		MonthlyIncome: payload.MonthlyIncome,
+		PayDay:        payload.PayDay,
+		Version:       1,
 	}
 
 	// Tentamos encontrar uma renda existente para este usuário
@@ -66,45 +402,52 @@ func SaveIncomeHandler(c *gin.Context) {
 
 	if result.Error == nil { // Renda existe, então atualizamos
 		existingIncome.MonthlyIncome = payload.MonthlyIncome
+		existingIncome.PayDay = payload.PayDay
+		existingIncome.Version++
 		if err := database.DB.Save(&existingIncome).Error; err != nil {
 			log.Printf("Error updating income for user %d: %v", userID, err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save income"})
+			apierrors.RespondInternal(c)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"message": "Income updated successfully", "income": existingIncome})
+		advanceOnboarding(uint(userID), models.OnboardingStepIncome)
+		c.JSON(http.StatusOK, gin.H{"message": "Income updated successfully", "income": incomeDTO(c, existingIncome)})
 	} else { // Renda não existe, criamos uma nova
 		if err := database.DB.Create(&income).Error; err != nil {
 			log.Printf("Error creating income for user %d: %v", userID, err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save income"})
+			apierrors.RespondInternal(c)
 			return
 		}
-		c.JSON(http.StatusCreated, gin.H{"message": "Income saved successfully", "income": income})
+		advanceOnboarding(uint(userID), models.OnboardingStepIncome)
+		c.JSON(http.StatusCreated, gin.H{"message": "Income saved successfully", "income": incomeDTO(c, income)})
 	}
 }
 
-// SaveFixedExpensesHandler lida com o salvamento das despesas fixas do usuário
+// SaveFixedExpensesHandler lida com o salvamento das despesas fixas do usuário.
+// Com ?householdId=, substitui as despesas fixas do domicílio (exige papel de editor).
 func SaveFixedExpensesHandler(c *gin.Context) {
-	userIDStr, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+	userID, ok := getUserID(c)
+	if !ok {
 		return
 	}
-	userID, err := strconv.ParseUint(userIDStr.(string), 10, 32)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
+	householdID, ok := householdIDFromQuery(c)
+	if !ok {
 		return
 	}
 
-	var payload FixedExpensesPayload
-	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+	payload, ok := bindFixedExpensesPayload(c)
+	if !ok {
 		return
 	}
 
 	// Verificar se o usuário existe
 	var user models.User
-	if result := database.DB.First(&user, uint(userID)); result.Error != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	if result := database.DB.First(&user, userID); result.Error != nil {
+		apierrors.Respond(c, http.StatusNotFound, apierrors.UserNotFound)
+		return
+	}
+
+	scope, ok := resolveScope(c, userID, householdID, models.HouseholdRoleEditor)
+	if !ok {
 		return
 	}
 
@@ -117,15 +460,15 @@ func SaveFixedExpensesHandler(c *gin.Context) {
 	tx := database.DB.Begin()
 	if tx.Error != nil {
 		log.Printf("Error starting transaction for user %d: %v", userID, tx.Error)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start database transaction"})
+		apierrors.RespondInternal(c)
 		return
 	}
 
-	// Deletar despesas fixas antigas para este usuário
-	if err := tx.Where("user_id = ?", uint(userID)).Delete(&models.FixedExpense{}).Error; err != nil {
+	// Deletar despesas fixas antigas do escopo (usuário ou domicílio)
+	if err := scope.apply(tx).Delete(&models.FixedExpense{}).Error; err != nil {
 		tx.Rollback()
 		log.Printf("Error deleting old fixed expenses for user %d: %v", userID, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update fixed expenses (delete step)"})
+		apierrors.RespondInternal(c)
 		return
 	}
 
@@ -133,9 +476,12 @@ func SaveFixedExpensesHandler(c *gin.Context) {
 	var newExpenses []models.FixedExpense
 	for _, expensePayload := range payload.Expenses {
 		newExpenses = append(newExpenses, models.FixedExpense{
-			UserID: uint(userID),
-			Name:   expensePayload.Name,
-			Value:  expensePayload.Value,
+			UserID:      userID,
+			HouseholdID: householdID,
+			Name:        expensePayload.Name,
+			Value:       expensePayload.Value,
+			Category:    expensePayload.Category,
+			DueDay:      expensePayload.DueDay,
 		})
 	}
 
@@ -143,16 +489,17 @@ func SaveFixedExpensesHandler(c *gin.Context) {
 		if err := tx.Create(&newExpenses).Error; err != nil {
 			tx.Rollback()
 			log.Printf("Error creating new fixed expenses for user %d: %v", userID, err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save new fixed expenses"})
+			apierrors.RespondInternal(c)
 			return
 		}
 	}
 
 	if err := tx.Commit().Error; err != nil {
-		log.Printf("Error committing transaction for user %d: %v", userID, err.Error)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit fixed expenses"})
+		log.Printf("Error committing transaction for user %d: %v", userID, err)
+		apierrors.RespondInternal(c)
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "Fixed expenses saved successfully", "fixedExpenses": newExpenses})
+	advanceOnboarding(userID, models.OnboardingStepFixedExpenses)
+	c.JSON(http.StatusCreated, gin.H{"message": "Fixed expenses saved successfully", "fixedExpenses": fixedExpensesDTO(c, newExpenses)})
 }
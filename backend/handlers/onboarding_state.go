@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"personal-finance-app/backend/database"
+	"personal-finance-app/backend/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// onboardingSteps lista as etapas do onboarding na ordem em que são apresentadas.
+var onboardingSteps = []string{
+	models.OnboardingStepProfile,
+	models.OnboardingStepIncome,
+	models.OnboardingStepFixedExpenses,
+	models.OnboardingStepCategories,
+	models.OnboardingStepFirstBudget,
+}
+
+// skippableOnboardingSteps indica as etapas que podem ser puladas.
+// Perfil e renda são obrigatórios: o cálculo do saldo depende da renda.
+var skippableOnboardingSteps = map[string]bool{
+	models.OnboardingStepFixedExpenses: true,
+	models.OnboardingStepCategories:    true,
+	models.OnboardingStepFirstBudget:   true,
+}
+
+// FixedExpenseTemplate é uma sugestão de despesa fixa para o perfil do domicílio
+type FixedExpenseTemplate struct {
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	DueDay   int    `json:"dueDay"` // Dia de vencimento mais comum; 0 se variável
+}
+
+// fixedExpenseTemplates sugere despesas fixas comuns para cada perfil de domicílio.
+var fixedExpenseTemplates = map[string][]FixedExpenseTemplate{
+	"solo": {
+		{Name: "Aluguel", Category: "moradia", DueDay: 5},
+		{Name: "Energia elétrica", Category: "moradia", DueDay: 10},
+		{Name: "Internet", Category: "moradia", DueDay: 15},
+		{Name: "Celular", Category: "comunicação", DueDay: 15},
+		{Name: "Transporte", Category: "transporte"},
+		{Name: "Academia", Category: "saúde", DueDay: 10},
+	},
+	"casal": {
+		{Name: "Aluguel", Category: "moradia", DueDay: 5},
+		{Name: "Condomínio", Category: "moradia", DueDay: 10},
+		{Name: "Energia elétrica", Category: "moradia", DueDay: 10},
+		{Name: "Água", Category: "moradia", DueDay: 10},
+		{Name: "Internet", Category: "moradia", DueDay: 15},
+		{Name: "Celulares", Category: "comunicação", DueDay: 15},
+		{Name: "Plano de saúde", Category: "saúde", DueDay: 5},
+		{Name: "Streaming", Category: "lazer", DueDay: 20},
+	},
+	"familia": {
+		{Name: "Aluguel", Category: "moradia", DueDay: 5},
+		{Name: "Condomínio", Category: "moradia", DueDay: 10},
+		{Name: "Energia elétrica", Category: "moradia", DueDay: 10},
+		{Name: "Água", Category: "moradia", DueDay: 10},
+		{Name: "Gás", Category: "moradia", DueDay: 15},
+		{Name: "Internet", Category: "moradia", DueDay: 15},
+		{Name: "Plano de saúde", Category: "saúde", DueDay: 5},
+		{Name: "Escola", Category: "educação", DueDay: 5},
+		{Name: "Transporte escolar", Category: "educação", DueDay: 5},
+		{Name: "Seguro do carro", Category: "transporte", DueDay: 20},
+	},
+	"compartilhado": {
+		{Name: "Aluguel (sua parte)", Category: "moradia", DueDay: 5},
+		{Name: "Contas da casa (sua parte)", Category: "moradia", DueDay: 10},
+		{Name: "Internet (sua parte)", Category: "moradia", DueDay: 15},
+		{Name: "Celular", Category: "comunicação", DueDay: 15},
+		{Name: "Transporte", Category: "transporte"},
+	},
+}
+
+// defaultCategories são as categorias sugeridas na etapa de categorias.
+var defaultCategories = []string{"mercado", "restaurantes", "transporte", "saúde", "lazer", "compras", "casa", "educação", "outros"}
+
+// stepSet converte a lista de etapas separadas por vírgula em um conjunto.
+func stepSet(steps string) map[string]bool {
+	set := make(map[string]bool)
+	for _, step := range strings.Split(steps, ",") {
+		if step != "" {
+			set[step] = true
+		}
+	}
+	return set
+}
+
+// joinSteps converte um conjunto de etapas em lista separada por vírgula, na ordem do onboarding.
+func joinSteps(set map[string]bool) string {
+	var steps []string
+	for _, step := range onboardingSteps {
+		if set[step] {
+			steps = append(steps, step)
+		}
+	}
+	return strings.Join(steps, ",")
+}
+
+// isOnboardingStep indica se o nome corresponde a uma etapa conhecida.
+func isOnboardingStep(name string) bool {
+	for _, step := range onboardingSteps {
+		if step == name {
+			return true
+		}
+	}
+	return false
+}
+
+// refreshCurrentStep aponta a etapa atual para a primeira etapa ainda pendente
+// (nem concluída nem pulada) e marca o onboarding como concluído quando não houver nenhuma.
+func refreshCurrentStep(state *models.OnboardingState) {
+	completed, skipped := stepSet(state.CompletedSteps), stepSet(state.SkippedSteps)
+	for _, step := range onboardingSteps {
+		if !completed[step] && !skipped[step] {
+			state.CurrentStep = step
+			state.CompletedAt = nil
+			return
+		}
+	}
+	state.CurrentStep = ""
+	if state.CompletedAt == nil {
+		now := time.Now()
+		state.CompletedAt = &now
+	}
+}
+
+// loadOnboardingState busca o progresso do usuário. Na primeira consulta, o estado é criado
+// a partir dos dados já cadastrados, para que usuários antigos não refaçam etapas concluídas.
+func loadOnboardingState(tx *gorm.DB, userID uint) (*models.OnboardingState, error) {
+	var state models.OnboardingState
+	err := tx.Where("user_id = ?", userID).First(&state).Error
+	if err == nil {
+		return &state, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	completed := make(map[string]bool)
+	checks := map[string]interface{}{
+		models.OnboardingStepIncome:        &models.Income{},
+		models.OnboardingStepFixedExpenses: &models.FixedExpense{},
+		models.OnboardingStepCategories:    &models.Category{},
+		models.OnboardingStepFirstBudget:   &models.Budget{},
+	}
+	for step, model := range checks {
+		var count int64
+		if err := tx.Model(model).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		completed[step] = count > 0
+		// O perfil não deixa registros: quem já concluiu uma etapa seguinte passou por ele
+		if count > 0 {
+			completed[models.OnboardingStepProfile] = true
+		}
+	}
+
+	state = models.OnboardingState{UserID: userID, CompletedSteps: joinSteps(completed)}
+	refreshCurrentStep(&state)
+	if err := tx.Create(&state).Error; err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// completeOnboardingStep marca uma etapa como concluída e avança para a próxima pendente.
+func completeOnboardingStep(tx *gorm.DB, userID uint, step string) (*models.OnboardingState, error) {
+	state, err := loadOnboardingState(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	completed, skipped := stepSet(state.CompletedSteps), stepSet(state.SkippedSteps)
+	completed[step] = true
+	delete(skipped, step)
+	state.CompletedSteps, state.SkippedSteps = joinSteps(completed), joinSteps(skipped)
+	refreshCurrentStep(state)
+	return state, tx.Save(state).Error
+}
+
+// advanceOnboarding registra a conclusão de uma etapa a partir dos handlers de cadastro.
+// Falhas são apenas registradas no log: o dado principal já foi salvo com sucesso.
+func advanceOnboarding(userID uint, step string) {
+	if _, err := completeOnboardingStep(database.DB, userID, step); err != nil {
+		log.Printf("Error updating onboarding step %s for user %d: %v", step, userID, err)
+	}
+}
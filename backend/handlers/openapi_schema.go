@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gorm.io/gorm"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	deletedAtType  = reflect.TypeOf(gorm.DeletedAt{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemaKey identifica um schema gerado: o mesmo tipo pode gerar schemas diferentes
+// como corpo de requisição e como corpo de resposta.
+type schemaKey struct {
+	t       reflect.Type
+	request bool
+}
+
+// schemaGenerator gera os schemas OpenAPI a partir das structs usadas pelos handlers, para que a
+// especificação acompanhe os payloads e respostas reais. Structs nomeadas vão para
+// components/schemas e são referenciadas.
+//
+// Em requisições, os campos obrigatórios e as restrições vêm da tag binding (a mesma validada pelo
+// Gin) e campos desconhecidos são aceitos (o Gin os ignora). Em respostas, todo campo sem omitempty é
+// obrigatório, campos desconhecidos não são permitidos e listas e mapas podem ser null (slices nil).
+type schemaGenerator struct {
+	schemas openapi3.Schemas
+	names   map[schemaKey]string
+	used    map[string]bool
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{schemas: openapi3.Schemas{}, names: map[schemaKey]string{}, used: map[string]bool{}}
+}
+
+// schemaRef retorna o schema do tipo Go (ou a referência ao schema do componente).
+func (g *schemaGenerator) schemaRef(t reflect.Type, request bool) *openapi3.SchemaRef {
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		nullable = true
+	}
+
+	var schema *openapi3.Schema
+	switch {
+	case t == timeType:
+		schema = openapi3.NewDateTimeSchema()
+	case t == deletedAtType:
+		schema = openapi3.NewDateTimeSchema()
+		nullable = true
+	case t == rawMessageType, t.Kind() == reflect.Interface:
+		return openapi3.NewSchema().NewRef() // Qualquer valor JSON
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		name := g.component(t, request)
+		ref := openapi3.NewSchemaRef("#/components/schemas/"+name, g.schemas[name].Value)
+		if !nullable {
+			return ref
+		}
+		// $ref ignora "nullable" no OpenAPI 3.0; o schema nulo envolve a referência
+		return (&openapi3.Schema{Nullable: true, AllOf: openapi3.SchemaRefs{ref}}).NewRef()
+	default:
+		schema = g.inlineSchema(t, request)
+	}
+	schema.Nullable = schema.Nullable || nullable
+	return schema.NewRef()
+}
+
+// inlineSchema gera o schema de tipos que não viram componentes (primitivos, listas, mapas e
+// structs anônimas).
+func (g *schemaGenerator) inlineSchema(t reflect.Type, request bool) *openapi3.Schema {
+	switch t.Kind() {
+	case reflect.Bool:
+		return openapi3.NewBoolSchema()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return openapi3.NewIntegerSchema()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return openapi3.NewIntegerSchema().WithMin(0)
+	case reflect.Float32, reflect.Float64:
+		return openapi3.NewFloat64Schema()
+	case reflect.String:
+		return openapi3.NewStringSchema()
+	case reflect.Slice, reflect.Array:
+		schema := openapi3.NewArraySchema()
+		schema.Items = g.schemaRef(t.Elem(), request)
+		schema.Nullable = !request
+		return schema
+	case reflect.Map:
+		schema := openapi3.NewObjectSchema()
+		schema.AdditionalProperties = openapi3.AdditionalProperties{Schema: g.schemaRef(t.Elem(), request)}
+		schema.Nullable = !request
+		return schema
+	case reflect.Struct:
+		return g.structSchema(t, request)
+	}
+	panic("openapi: unsupported type " + t.String())
+}
+
+// component registra a struct em components/schemas e retorna o nome do schema.
+func (g *schemaGenerator) component(t reflect.Type, request bool) string {
+	key := schemaKey{t: t, request: request}
+	if name, known := g.names[key]; known {
+		return name
+	}
+
+	name := t.Name()
+	if g.used[name] {
+		if request {
+			name += "Input"
+		} else {
+			name += "Output"
+		}
+	}
+	g.names[key] = name
+	g.used[name] = true
+	schema := openapi3.NewObjectSchema()
+	g.schemas[name] = schema.NewRef() // Registrado antes dos campos, para tipos recursivos
+	*schema = *g.structSchema(t, request)
+	return name
+}
+
+// structSchema gera o schema de objeto de uma struct, seguindo as tags json e binding dos campos.
+// Structs embutidas sem nome JSON têm seus campos incorporados, como faz o encoding/json.
+func (g *schemaGenerator) structSchema(t reflect.Type, request bool) *openapi3.Schema {
+	schema := openapi3.NewObjectSchema()
+	if !request {
+		schema.AdditionalProperties = openapi3.AdditionalProperties{Has: new(bool)}
+	}
+	g.addFields(schema, t, request)
+	return schema
+}
+
+func (g *schemaGenerator) addFields(schema *openapi3.Schema, t reflect.Type, request bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			g.addFields(schema, field.Type, request)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		omitEmpty := strings.Contains(","+options+",", ",omitempty,")
+
+		property := g.schemaRef(field.Type, request)
+		required := !omitEmpty
+		if request {
+			required = applyBindingRules(property, field.Type, field.Tag.Get("binding"))
+		}
+		if required {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = property
+	}
+}
+
+// applyBindingRules traduz as regras da tag binding (validator) usadas no projeto em restrições do
+// schema e informa se o campo é obrigatório. Regras depois de "dive" valem para os itens da lista.
+// Com omitempty, o valor zero de campos que não são ponteiros continua válido.
+func applyBindingRules(ref *openapi3.SchemaRef, t reflect.Type, tag string) bool {
+	if tag == "" {
+		return false
+	}
+	rules := strings.Split(tag, ",")
+	required, omitEmpty := false, false
+	schema := ref.Value
+	for i, rule := range rules {
+		name, arg, _ := strings.Cut(rule, "=")
+		if name == "dive" {
+			if schema != nil && schema.Items != nil && t.Kind() == reflect.Slice {
+				applyBindingRules(schema.Items, t.Elem(), strings.Join(rules[i+1:], ","))
+			}
+			break
+		}
+		switch name {
+		case "required":
+			required = true
+			if schema != nil && schema.Type == openapi3.TypeString {
+				schema.MinLength = 1 // "required" recusa strings vazias
+			}
+		case "omitempty":
+			omitEmpty = true
+		}
+		if schema == nil || ref.Ref != "" {
+			continue
+		}
+		value, _ := strconv.ParseFloat(arg, 64)
+		switch {
+		case name == "oneof":
+			for _, option := range strings.Fields(arg) {
+				schema.Enum = append(schema.Enum, option)
+			}
+		case name == "gt" || name == "gte" || name == "min":
+			switch schema.Type {
+			case openapi3.TypeString:
+				schema.MinLength = uint64(value)
+			case openapi3.TypeArray:
+				schema.MinItems = uint64(value)
+			default:
+				schema.Min = &value
+				schema.ExclusiveMin = name == "gt"
+			}
+		case name == "max":
+			limit := uint64(value)
+			switch schema.Type {
+			case openapi3.TypeString:
+				schema.MaxLength = &limit
+			case openapi3.TypeArray:
+				schema.MaxItems = &limit
+			default:
+				schema.Max = &value
+			}
+		}
+	}
+
+	if omitEmpty && t.Kind() != reflect.Pointer && schema != nil {
+		schema.MinLength, schema.MinItems = 0, 0
+		if schema.Min != nil && (*schema.Min > 0 || (*schema.Min == 0 && schema.ExclusiveMin)) {
+			schema.Min, schema.ExclusiveMin = nil, false
+		}
+		if len(schema.Enum) > 0 && schema.Type == openapi3.TypeString {
+			schema.Enum = append(schema.Enum, "")
+		}
+	}
+	return required
+}
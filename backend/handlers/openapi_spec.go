@@ -0,0 +1,522 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"personal-finance-app/backend/apierrors"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"personal-finance-app/backend/models"
+)
+
+// apiOperation descreve uma rota da API na especificação OpenAPI. Os corpos são exemplos (valores
+// zero) dos próprios tipos usados pelos handlers; os schemas são gerados a partir deles.
+//
+// Rotas versionadas são documentadas em /v1, /v2 e sem prefixo (obsoletas, formato da v1). Body e
+// Responses são os corpos da v1; V2Body e V2Responses, os da v2 quando o formato mudou.
+type apiOperation struct {
+	Method      string
+	Path        string // Caminho no formato do Gin, sem a versão (ex: /expenses/:id)
+	Tag         string
+	Summary     string
+	Public      bool                  // Rota sem autenticação JWT
+	Unversioned bool                  // Rota sem prefixo de versão (ex: /graphql)
+	Params      []*openapi3.Parameter // Parâmetros de query e cabeçalhos (os de caminho vêm de Path)
+	Body        interface{}           // Corpo da requisição; nil se não houver
+	Optional    bool                  // O corpo da requisição pode ser omitido
+	Responses   map[int]interface{}   // Corpo de cada resposta de sucesso
+	Stream      bool                  // Resposta em Server-Sent Events; Responses descreve os dados de cada evento
+	V2Body      interface{}
+	V2Responses map[int]interface{}
+}
+
+// messageResponse é a resposta das rotas que só confirmam a operação.
+type messageResponse struct {
+	Message string `json:"message"`
+}
+
+// goalMovementResponse é a resposta de um aporte ou de uma retirada de uma meta
+type goalMovementResponse struct {
+	Message      string                   `json:"message"`
+	Goal         GoalResponse             `json:"goal"`
+	Contribution GoalContributionResponse `json:"contribution"`
+}
+
+// batchSummary conta os itens de um lote por situação.
+type batchSummary map[string]int
+
+func queryParam(name, description string, schema *openapi3.Schema) *openapi3.Parameter {
+	return openapi3.NewQueryParameter(name).WithDescription(description).WithSchema(schema)
+}
+
+func headerParam(name, description string, schema *openapi3.Schema) *openapi3.Parameter {
+	return openapi3.NewHeaderParameter(name).WithDescription(description).WithSchema(schema)
+}
+
+var (
+	householdIDParam    = queryParam("householdId", "Domicílio compartilhado; sem ele, os dados pessoais do usuário", openapi3.NewIntegerSchema().WithMin(0))
+	idempotencyKeyParam = headerParam("Idempotency-Key", "Repete a resposta da primeira requisição com a mesma chave (24 horas)", openapi3.NewStringSchema().WithMaxLength(255))
+	monthSchema         = openapi3.NewStringSchema().WithPattern(`^\d{4}-\d{2}$`)
+	ifMatchParam        = headerParam("If-Match", "ETag da versão lida; responde 412 se o registro tiver sido alterado", openapi3.NewStringSchema())
+)
+
+// apiOperations são as rotas registradas em main.go. Toda rota nova deve ser descrita aqui:
+// a inicialização compara esta lista com as rotas do Gin.
+var apiOperations = []apiOperation{
+	{Method: http.MethodGet, Path: "/", Tag: "meta", Summary: "Mensagem de boas-vindas", Public: true, Unversioned: true,
+		Responses: map[int]interface{}{http.StatusOK: messageResponse{}}},
+	{Method: http.MethodGet, Path: "/openapi.json", Tag: "meta", Summary: "Esta especificação OpenAPI", Public: true, Unversioned: true,
+		Responses: map[int]interface{}{http.StatusOK: map[string]interface{}{}}},
+
+	// Autenticação
+	{Method: http.MethodPost, Path: "/auth/request-code", Tag: "auth", Summary: "Envia um código de acesso por e-mail", Public: true,
+		Body: RequestCodeBody{}, Responses: map[int]interface{}{http.StatusOK: messageResponse{}}},
+	{Method: http.MethodPost, Path: "/auth/verify-code", Tag: "auth", Summary: "Troca o código de acesso por um token JWT", Public: true,
+		Body: VerifyCodeBody{}, Responses: map[int]interface{}{http.StatusOK: struct {
+			Message string `json:"message"`
+			Token   string `json:"token"`
+			UserID  uint   `json:"userId"`
+			Email   string `json:"email"`
+		}{}}},
+
+	// Onboarding
+	{Method: http.MethodGet, Path: "/onboarding/status", Tag: "onboarding", Summary: "Situação do onboarding",
+		Responses: map[int]interface{}{http.StatusOK: OnboardingStatusResponse{}}},
+	{Method: http.MethodPost, Path: "/onboarding/profile", Tag: "onboarding", Summary: "Salva o perfil do domicílio",
+		Body: HouseholdProfilePayload{}, Responses: map[int]interface{}{http.StatusOK: OnboardingStatusResponse{}}},
+	{Method: http.MethodPost, Path: "/onboarding/income", Tag: "onboarding", Summary: "Salva a renda mensal",
+		Body: IncomePayload{}, Responses: map[int]interface{}{
+			http.StatusOK: struct {
+				Message string        `json:"message"`
+				Income  models.Income `json:"income"`
+			}{},
+			http.StatusCreated: struct {
+				Message string        `json:"message"`
+				Income  models.Income `json:"income"`
+			}{},
+		},
+		V2Body: IncomePayloadV2{}, V2Responses: map[int]interface{}{
+			http.StatusOK: struct {
+				Message string         `json:"message"`
+				Income  IncomeResponse `json:"income"`
+			}{},
+			http.StatusCreated: struct {
+				Message string         `json:"message"`
+				Income  IncomeResponse `json:"income"`
+			}{},
+		}},
+	{Method: http.MethodPost, Path: "/onboarding/fixed-expenses", Tag: "onboarding", Summary: "Cadastra as despesas fixas em lote",
+		Params: []*openapi3.Parameter{householdIDParam},
+		Body:   FixedExpensesPayload{}, Responses: map[int]interface{}{http.StatusCreated: struct {
+			Message       string                `json:"message"`
+			FixedExpenses []models.FixedExpense `json:"fixedExpenses"`
+		}{}},
+		V2Body: FixedExpensesPayloadV2{}, V2Responses: map[int]interface{}{http.StatusCreated: struct {
+			Message       string                 `json:"message"`
+			FixedExpenses []FixedExpenseResponse `json:"fixedExpenses"`
+		}{}}},
+	{Method: http.MethodGet, Path: "/onboarding/fixed-expense-templates", Tag: "onboarding", Summary: "Sugestões de despesas fixas para o perfil",
+		Params: []*openapi3.Parameter{queryParam("profile", "Perfil do domicílio; padrão: o perfil salvo no onboarding", openapi3.NewStringSchema())},
+		Responses: map[int]interface{}{http.StatusOK: struct {
+			HouseholdProfile      string                 `json:"householdProfile"`
+			FixedExpenseTemplates []FixedExpenseTemplate `json:"fixedExpenseTemplates"`
+		}{}}},
+	{Method: http.MethodPost, Path: "/onboarding/categories", Tag: "onboarding", Summary: "Salva as categorias de despesa",
+		Body: CategoriesPayload{}, Responses: map[int]interface{}{http.StatusOK: OnboardingStatusResponse{}}},
+	{Method: http.MethodPost, Path: "/onboarding/budget", Tag: "onboarding", Summary: "Salva o primeiro orçamento",
+		Body: FirstBudgetPayload{}, Responses: map[int]interface{}{http.StatusOK: OnboardingStatusResponse{}}},
+	{Method: http.MethodPost, Path: "/onboarding/skip", Tag: "onboarding", Summary: "Pula a etapa atual (ou a informada)",
+		Body: SkipStepPayload{}, Optional: true, Responses: map[int]interface{}{http.StatusOK: OnboardingStatusResponse{}}},
+	{Method: http.MethodPost, Path: "/onboarding/back", Tag: "onboarding", Summary: "Volta para a etapa anterior",
+		Responses: map[int]interface{}{http.StatusOK: OnboardingStatusResponse{}}},
+
+	// Despesas fixas
+	{Method: http.MethodGet, Path: "/fixed-expenses", Tag: "fixed-expenses", Summary: "Lista as despesas fixas",
+		Params: []*openapi3.Parameter{householdIDParam, queryParam("active", "Filtra por despesas ativas ou inativas", openapi3.NewBoolSchema())},
+		Responses: map[int]interface{}{http.StatusOK: struct {
+			FixedExpenses []FixedExpenseResponse `json:"fixedExpenses"`
+		}{}}},
+	{Method: http.MethodPost, Path: "/fixed-expenses", Tag: "fixed-expenses", Summary: "Cadastra uma despesa fixa",
+		Body: CreateFixedExpensePayload{}, Responses: map[int]interface{}{http.StatusCreated: struct {
+			Message      string               `json:"message"`
+			FixedExpense FixedExpenseResponse `json:"fixedExpense"`
+		}{}}},
+	{Method: http.MethodGet, Path: "/fixed-expenses/:id", Tag: "fixed-expenses", Summary: "Consulta uma despesa fixa",
+		Responses: map[int]interface{}{http.StatusOK: FixedExpenseResponse{}}},
+	{Method: http.MethodPatch, Path: "/fixed-expenses/:id", Tag: "fixed-expenses", Summary: "Altera os campos informados de uma despesa fixa",
+		Params: []*openapi3.Parameter{ifMatchParam},
+		Body:   PatchFixedExpensePayload{}, Responses: map[int]interface{}{http.StatusOK: struct {
+			Message      string               `json:"message"`
+			FixedExpense FixedExpenseResponse `json:"fixedExpense"`
+		}{}}},
+	{Method: http.MethodDelete, Path: "/fixed-expenses/:id", Tag: "fixed-expenses", Summary: "Remove uma despesa fixa",
+		Params:    []*openapi3.Parameter{ifMatchParam},
+		Responses: map[int]interface{}{http.StatusOK: messageResponse{}}},
+
+	// Metas de economia
+	{Method: http.MethodGet, Path: "/goals", Tag: "goals", Summary: "Lista as metas de economia com o andamento",
+		Params: []*openapi3.Parameter{householdIDParam},
+		Responses: map[int]interface{}{http.StatusOK: struct {
+			Goals []GoalResponse `json:"goals"`
+		}{}}},
+	{Method: http.MethodPost, Path: "/goals", Tag: "goals", Summary: "Cria uma meta de economia",
+		Body: CreateGoalPayload{}, Responses: map[int]interface{}{http.StatusCreated: struct {
+			Message string       `json:"message"`
+			Goal    GoalResponse `json:"goal"`
+		}{}}},
+	{Method: http.MethodGet, Path: "/goals/:id", Tag: "goals", Summary: "Consulta uma meta, o andamento e os aportes",
+		Responses: map[int]interface{}{http.StatusOK: GoalDetailResponse{}}},
+	{Method: http.MethodDelete, Path: "/goals/:id", Tag: "goals", Summary: "Remove uma meta e seus aportes",
+		Responses: map[int]interface{}{http.StatusOK: messageResponse{}}},
+	{Method: http.MethodPost, Path: "/goals/:id/contributions", Tag: "goals", Summary: "Aporta um valor na meta (descontado do saldo)",
+		Body: GoalMovementPayload{}, Responses: map[int]interface{}{http.StatusCreated: goalMovementResponse{}}},
+	{Method: http.MethodPost, Path: "/goals/:id/withdrawals", Tag: "goals", Summary: "Retira um valor da meta (devolvido ao saldo)",
+		Body: GoalMovementPayload{}, Responses: map[int]interface{}{http.StatusCreated: goalMovementResponse{}}},
+
+	// Transações recorrentes
+	{Method: http.MethodGet, Path: "/recurring-transactions", Tag: "recurring-transactions", Summary: "Lista as entradas e saídas recorrentes",
+		Params: []*openapi3.Parameter{householdIDParam},
+		Responses: map[int]interface{}{http.StatusOK: struct {
+			RecurringTransactions []RecurringTransactionResponse `json:"recurringTransactions"`
+		}{}}},
+	{Method: http.MethodPost, Path: "/recurring-transactions", Tag: "recurring-transactions", Summary: "Cadastra uma entrada ou saída recorrente (considerada no fluxo de caixa e na previsão)",
+		Body: CreateRecurringTransactionPayload{}, Responses: map[int]interface{}{http.StatusCreated: struct {
+			Message              string                       `json:"message"`
+			RecurringTransaction RecurringTransactionResponse `json:"recurringTransaction"`
+		}{}}},
+	{Method: http.MethodGet, Path: "/recurring-transactions/:id", Tag: "recurring-transactions", Summary: "Consulta uma transação recorrente",
+		Responses: map[int]interface{}{http.StatusOK: RecurringTransactionResponse{}}},
+	{Method: http.MethodDelete, Path: "/recurring-transactions/:id", Tag: "recurring-transactions", Summary: "Remove uma transação recorrente",
+		Responses: map[int]interface{}{http.StatusOK: messageResponse{}}},
+
+	// Despesas variáveis
+	{Method: http.MethodGet, Path: "/expenses", Tag: "expenses", Summary: "Lista as despesas variáveis com filtros e paginação por cursor",
+		Params: []*openapi3.Parameter{
+			householdIDParam,
+			queryParam("from", "Data inicial (YYYY-MM-DD)", openapi3.NewStringSchema().WithFormat("date")),
+			queryParam("to", "Data final (YYYY-MM-DD)", openapi3.NewStringSchema().WithFormat("date")),
+			queryParam("category", "Categorias separadas por vírgula", openapi3.NewStringSchema()),
+			queryParam("minValue", "Valor mínimo", openapi3.NewFloat64Schema()),
+			queryParam("maxValue", "Valor máximo", openapi3.NewFloat64Schema()),
+			queryParam("q", "Busca na descrição", openapi3.NewStringSchema()),
+			queryParam("sort", "Ordenação (date, -date, value, -value)", openapi3.NewStringSchema()),
+			queryParam("limit", "Tamanho da página", openapi3.NewIntegerSchema().WithMin(1).WithMax(maxExpensePageSize)),
+			queryParam("cursor", "Cursor da próxima página (nextCursor)", openapi3.NewStringSchema()),
+		},
+		Responses: map[int]interface{}{http.StatusOK: ExpenseListResponse{}}},
+	{Method: http.MethodPost, Path: "/expenses", Tag: "expenses", Summary: "Registra uma despesa variável",
+		Body: CreateExpensePayload{}, Responses: map[int]interface{}{http.StatusCreated: struct {
+			Message string          `json:"message"`
+			Expense ExpenseResponse `json:"expense"`
+		}{}}},
+	{Method: http.MethodPost, Path: "/expenses/batch", Tag: "expenses", Summary: "Registra várias despesas, com resultado por item",
+		Body: BatchExpensesPayload{}, Responses: map[int]interface{}{http.StatusOK: struct {
+			Results []BatchItemResult `json:"results"`
+			Summary batchSummary      `json:"summary"`
+		}{}}},
+	{Method: http.MethodGet, Path: "/expenses/trash", Tag: "expenses", Summary: "Lista as despesas na lixeira",
+		Params: []*openapi3.Parameter{householdIDParam},
+		Responses: map[int]interface{}{http.StatusOK: struct {
+			Expenses      []TrashedExpenseResponse `json:"expenses"`
+			RetentionDays int                      `json:"retentionDays"`
+		}{}}},
+	{Method: http.MethodDelete, Path: "/expenses/trash", Tag: "expenses", Summary: "Esvazia a lixeira",
+		Params: []*openapi3.Parameter{householdIDParam},
+		Responses: map[int]interface{}{http.StatusOK: struct {
+			Message string `json:"message"`
+			Purged  int64  `json:"purged"`
+		}{}}},
+	{Method: http.MethodGet, Path: "/expenses/:id", Tag: "expenses", Summary: "Consulta uma despesa variável",
+		Responses: map[int]interface{}{http.StatusOK: ExpenseResponse{}}},
+	{Method: http.MethodPut, Path: "/expenses/:id", Tag: "expenses", Summary: "Substitui os dados de uma despesa variável",
+		Params: []*openapi3.Parameter{ifMatchParam},
+		Body:   CreateExpensePayload{}, Responses: map[int]interface{}{http.StatusOK: struct {
+			Message string          `json:"message"`
+			Expense ExpenseResponse `json:"expense"`
+		}{}}},
+	{Method: http.MethodPatch, Path: "/expenses/:id", Tag: "expenses", Summary: "Altera os campos informados de uma despesa variável",
+		Params: []*openapi3.Parameter{ifMatchParam},
+		Body:   PatchExpensePayload{}, Responses: map[int]interface{}{http.StatusOK: struct {
+			Message string          `json:"message"`
+			Expense ExpenseResponse `json:"expense"`
+		}{}}},
+	{Method: http.MethodDelete, Path: "/expenses/:id", Tag: "expenses", Summary: "Envia uma despesa variável para a lixeira",
+		Responses: map[int]interface{}{http.StatusOK: messageResponse{}}},
+	{Method: http.MethodPost, Path: "/expenses/:id/restore", Tag: "expenses", Summary: "Restaura uma despesa da lixeira",
+		Responses: map[int]interface{}{http.StatusOK: struct {
+			Message string          `json:"message"`
+			Expense ExpenseResponse `json:"expense"`
+		}{}}},
+
+	// Domicílios e convites
+	{Method: http.MethodPost, Path: "/households", Tag: "households", Summary: "Cria um domicílio compartilhado",
+		Body: CreateHouseholdPayload{}, Responses: map[int]interface{}{http.StatusCreated: struct {
+			Message   string            `json:"message"`
+			Household HouseholdResponse `json:"household"`
+		}{}}},
+	{Method: http.MethodGet, Path: "/households", Tag: "households", Summary: "Lista os domicílios do usuário",
+		Responses: map[int]interface{}{http.StatusOK: struct {
+			Households []HouseholdResponse `json:"households"`
+		}{}}},
+	{Method: http.MethodGet, Path: "/households/:id", Tag: "households", Summary: "Consulta um domicílio e seus membros",
+		Responses: map[int]interface{}{http.StatusOK: HouseholdResponse{}}},
+	{Method: http.MethodDelete, Path: "/households/:id", Tag: "households", Summary: "Remove um domicílio",
+		Responses: map[int]interface{}{http.StatusOK: messageResponse{}}},
+	{Method: http.MethodPost, Path: "/households/:id/invitations", Tag: "households", Summary: "Convida um membro por e-mail",
+		Body: InviteMemberPayload{}, Responses: map[int]interface{}{http.StatusCreated: struct {
+			Message    string                     `json:"message"`
+			Invitation models.HouseholdInvitation `json:"invitation"`
+		}{}},
+		V2Responses: map[int]interface{}{http.StatusCreated: struct {
+			Message    string             `json:"message"`
+			Invitation InvitationResponse `json:"invitation"`
+		}{}}},
+	{Method: http.MethodPatch, Path: "/households/:id/members/:userId", Tag: "households", Summary: "Altera o papel de um membro",
+		Body: UpdateMemberRolePayload{}, Responses: map[int]interface{}{http.StatusOK: struct {
+			Message string                  `json:"message"`
+			Member  HouseholdMemberResponse `json:"member"`
+		}{}}},
+	{Method: http.MethodDelete, Path: "/households/:id/members/:userId", Tag: "households", Summary: "Remove um membro",
+		Responses: map[int]interface{}{http.StatusOK: messageResponse{}}},
+	{Method: http.MethodGet, Path: "/invitations", Tag: "households", Summary: "Lista os convites pendentes do usuário",
+		Responses: map[int]interface{}{http.StatusOK: struct {
+			Invitations []models.HouseholdInvitation `json:"invitations"`
+		}{}},
+		V2Responses: map[int]interface{}{http.StatusOK: struct {
+			Invitations []InvitationResponse `json:"invitations"`
+		}{}}},
+	{Method: http.MethodPost, Path: "/invitations/:id/accept", Tag: "households", Summary: "Aceita um convite",
+		Responses: map[int]interface{}{http.StatusOK: struct {
+			Message     string `json:"message"`
+			HouseholdID uint   `json:"householdId"`
+			Role        string `json:"role"`
+		}{}}},
+	{Method: http.MethodDelete, Path: "/invitations/:id", Tag: "households", Summary: "Recusa um convite",
+		Responses: map[int]interface{}{http.StatusOK: messageResponse{}}},
+
+	// Sincronização
+	{Method: http.MethodGet, Path: "/sync", Tag: "sync", Summary: "Alterações desde o cursor (sincronização incremental)",
+		Params: []*openapi3.Parameter{
+			queryParam("since", "Cursor da última sincronização; vazio para sincronização completa", openapi3.NewStringSchema()),
+			queryParam("limit", "Quantidade máxima de alterações", openapi3.NewIntegerSchema().WithMin(1).WithMax(maxSyncPageSize)),
+		},
+		Responses: map[int]interface{}{http.StatusOK: SyncFeedResponse{}}},
+	{Method: http.MethodPost, Path: "/sync", Tag: "sync", Summary: "Envia as alterações feitas offline, com resultado por item",
+		Body: SyncPushPayload{}, Responses: map[int]interface{}{http.StatusOK: struct {
+			Results []SyncPushResult `json:"results"`
+			Summary batchSummary     `json:"summary"`
+		}{}}},
+
+	// Webhooks
+	{Method: http.MethodPost, Path: "/webhooks", Tag: "webhooks", Summary: "Cadastra um webhook (a resposta traz o segredo das assinaturas)",
+		Body: CreateWebhookPayload{}, Responses: map[int]interface{}{http.StatusCreated: WebhookResponse{}}},
+	{Method: http.MethodGet, Path: "/webhooks", Tag: "webhooks", Summary: "Lista os webhooks do usuário",
+		Responses: map[int]interface{}{http.StatusOK: struct {
+			Webhooks []WebhookResponse `json:"webhooks"`
+		}{}}},
+	{Method: http.MethodGet, Path: "/webhooks/:id", Tag: "webhooks", Summary: "Consulta um webhook",
+		Responses: map[int]interface{}{http.StatusOK: WebhookResponse{}}},
+	{Method: http.MethodDelete, Path: "/webhooks/:id", Tag: "webhooks", Summary: "Remove um webhook e seu histórico de entregas",
+		Responses: map[int]interface{}{http.StatusOK: messageResponse{}}},
+	{Method: http.MethodGet, Path: "/webhooks/:id/deliveries", Tag: "webhooks", Summary: "Histórico de entregas de um webhook",
+		Responses: map[int]interface{}{http.StatusOK: struct {
+			Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+		}{}}},
+	{Method: http.MethodPost, Path: "/webhooks/:id/deliveries/:deliveryId/redeliver", Tag: "webhooks", Summary: "Reenvia uma entrega",
+		Responses: map[int]interface{}{http.StatusAccepted: WebhookDeliveryResponse{}}},
+
+	// GraphQL e saldo
+	{Method: http.MethodPost, Path: "/graphql", Tag: "graphql", Summary: "Consultas e mutações GraphQL", Unversioned: true,
+		Body: GraphQLRequest{}, Responses: map[int]interface{}{
+			http.StatusOK: graphql.Result{},
+			http.StatusBadRequest: struct {
+				Errors []struct {
+					Message string `json:"message"`
+				} `json:"errors"`
+			}{},
+		}},
+	{Method: http.MethodGet, Path: "/balance", Tag: "balance", Summary: "Saldo, projeção e orçamentos do mês",
+		Params: []*openapi3.Parameter{householdIDParam,
+			queryParam("month", "Mês \"YYYY-MM\" (passado ou futuro); sem ele, o mês corrente", monthSchema),
+			queryParam("projection", "\"monte_carlo\" inclui a projeção probabilística em projection.monteCarlo", openapi3.NewStringSchema().WithEnum("deterministic", "monte_carlo")),
+			queryParam("seed", "Semente das simulações de Monte Carlo (padrão: 1)", openapi3.NewInt64Schema()),
+		},
+		Responses: map[int]interface{}{http.StatusOK: BalanceResponse{}}},
+	{Method: http.MethodGet, Path: "/balance/history", Tag: "balance", Summary: "Histórico mensal de renda, despesas e saúde financeira",
+		Params: []*openapi3.Parameter{householdIDParam,
+			queryParam("from", "Primeiro mês \"YYYY-MM\"; padrão: 11 meses antes de to", monthSchema),
+			queryParam("to", "Último mês \"YYYY-MM\"; padrão: o mês corrente", monthSchema),
+		},
+		Responses: map[int]interface{}{http.StatusOK: BalanceHistory{}}},
+	{Method: http.MethodGet, Path: "/balance/settings", Tag: "balance", Summary: "Regras da saúde financeira e modelo de previsão do usuário",
+		Responses: map[int]interface{}{http.StatusOK: BalanceSettingsResponse{}}},
+	{Method: http.MethodPut, Path: "/balance/settings", Tag: "balance", Summary: "Configura a saúde financeira e o modelo de previsão dos gastos",
+		Body: BalanceSettingsPayload{}, Responses: map[int]interface{}{http.StatusOK: BalanceSettingsResponse{}}},
+	{Method: http.MethodGet, Path: "/cashflow", Tag: "balance", Summary: "Saldo projetado dia a dia, com rendas no dia do pagamento, despesas fixas no vencimento e transações recorrentes",
+		Params: []*openapi3.Parameter{householdIDParam,
+			queryParam("from", "Data inicial (YYYY-MM-DD); padrão: o primeiro dia do mês corrente", openapi3.NewStringSchema().WithFormat("date")),
+			queryParam("to", "Data final (YYYY-MM-DD), até 366 dias depois de from; padrão: o último dia do mês corrente", openapi3.NewStringSchema().WithFormat("date")),
+		},
+		Responses: map[int]interface{}{http.StatusOK: CashFlowResponse{}}},
+	{Method: http.MethodGet, Path: "/forecast", Tag: "balance", Summary: "Previsão mês a mês de fluxo líquido e saldo acumulado",
+		Params: []*openapi3.Parameter{householdIDParam,
+			queryParam("months", "Meses a prever, a partir do mês corrente (1 a 24; padrão: 12)", openapi3.NewIntegerSchema().WithMin(1).WithMax(24)),
+		},
+		Responses: map[int]interface{}{http.StatusOK: ForecastResponse{}}},
+	{Method: http.MethodGet, Path: "/balance/stream", Tag: "balance", Summary: "Saldo em tempo real (Server-Sent Events: eventos \"balance\" a cada alteração)",
+		Params: []*openapi3.Parameter{householdIDParam}, Stream: true,
+		Responses: map[int]interface{}{http.StatusOK: BalanceResponse{}}},
+}
+
+// OpenAPIPath converte um caminho do Gin (/expenses/:id) para o formato OpenAPI (/expenses/{id}).
+func OpenAPIPath(ginPath string) string {
+	segments := strings.Split(ginPath, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// buildOpenAPISpec monta a especificação OpenAPI 3 a partir de apiOperations.
+func buildOpenAPISpec() (*openapi3.T, error) {
+	generator := newSchemaGenerator()
+	// Corpo das respostas de erro (apierrors.Body); alguns erros trazem campos extras
+	// (ex: currentVersion no 412)
+	codes := make([]interface{}, 0)
+	for _, code := range apierrors.Codes() {
+		codes = append(codes, string(code))
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i].(string) < codes[j].(string) })
+	fieldErrorSchema := openapi3.NewObjectSchema().
+		WithProperty("field", openapi3.NewStringSchema()).
+		WithProperty("code", openapi3.NewStringSchema()).
+		WithProperty("message", openapi3.NewStringSchema())
+	fieldErrorSchema.Required = []string{"code", "message"}
+	errorSchema := openapi3.NewObjectSchema().
+		WithProperty("error", openapi3.NewStringSchema()). // Mensagem no idioma do Accept-Language (en ou pt-BR)
+		WithProperty("code", openapi3.NewStringSchema().WithEnum(codes...)).
+		WithProperty("details", openapi3.NewArraySchema().WithItems(fieldErrorSchema)).
+		WithProperty("requestId", openapi3.NewStringSchema())
+	errorSchema.Required = []string{"error", "code"}
+	generator.schemas["Error"] = errorSchema.NewRef()
+	generator.used["Error"] = true
+
+	spec := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info: &openapi3.Info{
+			Title:       "Personal Finance API",
+			Description: "API do app de finanças pessoais. Rotas protegidas exigem o cabeçalho Authorization: Bearer <token> (POST /auth/verify-code).",
+			Version:     "2.0.0",
+		},
+		Paths: openapi3.NewPaths(),
+		Components: &openapi3.Components{
+			SecuritySchemes: openapi3.SecuritySchemes{
+				"bearerAuth": &openapi3.SecuritySchemeRef{Value: openapi3.NewJWTSecurityScheme()},
+			},
+		},
+	}
+
+	for _, op := range apiOperations {
+		if op.Unversioned {
+			spec.AddOperation(OpenAPIPath(op.Path), op.Method, generator.operation(op, "", op.Body, op.Responses, errorSchema))
+			continue
+		}
+		v2Body, v2Responses := op.Body, op.Responses
+		if op.V2Body != nil {
+			v2Body = op.V2Body
+		}
+		if op.V2Responses != nil {
+			v2Responses = op.V2Responses
+		}
+		spec.AddOperation("/v1"+OpenAPIPath(op.Path), op.Method, generator.operation(op, APIVersionV1, op.Body, op.Responses, errorSchema))
+		spec.AddOperation("/v2"+OpenAPIPath(op.Path), op.Method, generator.operation(op, APIVersionV2, v2Body, v2Responses, errorSchema))
+
+		legacy := generator.operation(op, "legacy", op.Body, op.Responses, errorSchema)
+		legacy.Deprecated = true
+		legacy.Description = "Obsoleta: use /v1" + OpenAPIPath(op.Path) + ". As respostas trazem os cabeçalhos Deprecation, Sunset e Link."
+		spec.AddOperation(OpenAPIPath(op.Path), op.Method, legacy)
+	}
+	spec.Components.Schemas = generator.schemas
+
+	if err := spec.Validate(context.Background()); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// operation monta a operação OpenAPI de uma rota com os corpos de uma versão. prefix distingue o
+// operationId das cópias da rota em cada versão.
+func (g *schemaGenerator) operation(op apiOperation, prefix string, body interface{}, responses map[int]interface{}, errorSchema *openapi3.Schema) *openapi3.Operation {
+	operation := openapi3.NewOperation()
+	operation.Summary = op.Summary
+	operation.Tags = []string{op.Tag}
+	operation.OperationID = strings.TrimPrefix(prefix+"_", "_") + strings.ToLower(op.Method) + strings.NewReplacer("/", "_", ":", "", "-", "_", ".", "_").Replace(op.Path)
+	if !op.Public {
+		operation.Security = openapi3.NewSecurityRequirements().With(openapi3.NewSecurityRequirement().Authenticate("bearerAuth"))
+	}
+
+	for _, segment := range strings.Split(op.Path, "/") {
+		if strings.HasPrefix(segment, ":") {
+			param := openapi3.NewPathParameter(segment[1:]).WithSchema(openapi3.NewIntegerSchema().WithMin(0))
+			operation.AddParameter(param)
+		}
+	}
+	for _, param := range op.Params {
+		operation.AddParameter(param)
+	}
+	if !op.Public && op.Method != http.MethodGet { // Rotas de escrita com IdempotencyMiddleware
+		operation.AddParameter(idempotencyKeyParam)
+	}
+
+	if body != nil {
+		requestBody := openapi3.NewRequestBody().WithRequired(!op.Optional).
+			WithJSONSchemaRef(g.schemaRef(reflect.TypeOf(body), true))
+		operation.RequestBody = &openapi3.RequestBodyRef{Value: requestBody}
+	}
+
+	statuses := make([]int, 0, len(responses))
+	for status := range responses {
+		statuses = append(statuses, status)
+	}
+	sort.Ints(statuses)
+	for _, status := range statuses {
+		response := openapi3.NewResponse().WithDescription(http.StatusText(status))
+		if op.Stream {
+			response.WithContent(openapi3.NewContentWithSchemaRef(g.schemaRef(reflect.TypeOf(responses[status]), false), []string{"text/event-stream"}))
+		} else {
+			response.WithJSONSchemaRef(g.schemaRef(reflect.TypeOf(responses[status]), false))
+		}
+		operation.AddResponse(status, response)
+	}
+	operation.Responses.Set("default", &openapi3.ResponseRef{Value: openapi3.NewResponse().
+		WithDescription("Erro").WithJSONSchemaRef(openapi3.NewSchemaRef("#/components/schemas/Error", errorSchema))})
+	return operation
+}
+
+// openAPISpec é a especificação OpenAPI da API HTTP (GET /openapi.json).
+var openAPISpec = mustOpenAPISpec()
+
+func mustOpenAPISpec() *openapi3.T {
+	spec, err := buildOpenAPISpec()
+	if err != nil {
+		panic("invalid OpenAPI specification: " + err.Error())
+	}
+	return spec
+}
+
+// OpenAPISpec retorna a especificação OpenAPI da API, usada para validar requisições e respostas.
+func OpenAPISpec() *openapi3.T {
+	return openAPISpec
+}
+
+// OpenAPIHandler serve a especificação OpenAPI em JSON.
+func OpenAPIHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, openAPISpec)
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"personal-finance-app/backend/apierrors"
+	"personal-finance-app/backend/database"
+	"personal-finance-app/backend/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CreateRecurringTransactionPayload define a estrutura para cadastrar uma transação recorrente
+type CreateRecurringTransactionPayload struct {
+	Description string  `json:"description" binding:"required"`
+	Kind        string  `json:"kind" binding:"required,oneof=income expense"`
+	Amount      float64 `json:"amount" binding:"required,gt=0"`
+	Cadence     string  `json:"cadence" binding:"required,oneof=weekly monthly yearly"`
+	StartDate   string  `json:"startDate" binding:"required"` // YYYY-MM-DD, primeira ocorrência
+	EndDate     *string `json:"endDate"`                      // Opcional, YYYY-MM-DD (inclusive)
+	HouseholdID *uint   `json:"householdId"`                  // Opcional, cadastra a transação no domicílio
+}
+
+// RecurringTransactionResponse é a representação JSON de uma transação recorrente
+type RecurringTransactionResponse struct {
+	ID          uint      `json:"id"`
+	UserID      uint      `json:"userId"`
+	HouseholdID *uint     `json:"householdId"`
+	Description string    `json:"description"`
+	Kind        string    `json:"kind"` // "income" ou "expense"
+	Amount      float64   `json:"amount"`
+	Cadence     string    `json:"cadence"`   // "weekly", "monthly" ou "yearly"
+	StartDate   string    `json:"startDate"` // YYYY-MM-DD
+	EndDate     *string   `json:"endDate"`   // YYYY-MM-DD; null se não tiver fim
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// toRecurringTransactionResponse converte o modelo em sua representação JSON.
+func toRecurringTransactionResponse(transaction models.RecurringTransaction) RecurringTransactionResponse {
+	response := RecurringTransactionResponse{
+		ID:          transaction.ID,
+		UserID:      transaction.UserID,
+		HouseholdID: transaction.HouseholdID,
+		Description: transaction.Description,
+		Kind:        transaction.Kind,
+		Amount:      transaction.Amount,
+		Cadence:     transaction.Cadence,
+		StartDate:   transaction.StartDate.Format("2006-01-02"),
+		CreatedAt:   transaction.CreatedAt,
+		UpdatedAt:   transaction.UpdatedAt,
+	}
+	if transaction.EndDate != nil {
+		endDate := transaction.EndDate.Format("2006-01-02")
+		response.EndDate = &endDate
+	}
+	return response
+}
+
+// ListRecurringTransactionsHandler lista as transações recorrentes do usuário (ou de um domicílio,
+// com ?householdId=), pela data da primeira ocorrência.
+func ListRecurringTransactionsHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	householdID, ok := householdIDFromQuery(c)
+	if !ok {
+		return
+	}
+	scope, ok := resolveScope(c, userID, householdID, models.HouseholdRoleViewer)
+	if !ok {
+		return
+	}
+
+	var transactions []models.RecurringTransaction
+	if err := scope.apply(database.DB).Order("start_date, id").Find(&transactions).Error; err != nil {
+		log.Printf("Error listing recurring transactions for user %d: %v", userID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+
+	response := make([]RecurringTransactionResponse, 0, len(transactions))
+	for _, transaction := range transactions {
+		response = append(response, toRecurringTransactionResponse(transaction))
+	}
+	c.JSON(http.StatusOK, gin.H{"recurringTransactions": response})
+}
+
+// PostRecurringTransactionHandler cadastra uma entrada ou saída recorrente, considerada no saldo, no
+// fluxo de caixa e na previsão dos próximos meses
+func PostRecurringTransactionHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var payload CreateRecurringTransactionPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		apierrors.RespondInvalid(c, err)
+		return
+	}
+	startDate, err := time.ParseInLocation("2006-01-02", payload.StartDate, time.Local)
+	if err != nil {
+		apierrors.Respond(c, http.StatusBadRequest, apierrors.InvalidDate, "startDate")
+		return
+	}
+	var endDate *time.Time
+	if payload.EndDate != nil {
+		parsed, err := time.ParseInLocation("2006-01-02", *payload.EndDate, time.Local)
+		if err != nil {
+			apierrors.Respond(c, http.StatusBadRequest, apierrors.InvalidDate, "endDate")
+			return
+		}
+		if parsed.Before(startDate) {
+			apierrors.Respond(c, http.StatusBadRequest, apierrors.RecurringEndBeforeStart)
+			return
+		}
+		endDate = &parsed
+	}
+
+	if _, ok := resolveScope(c, userID, payload.HouseholdID, models.HouseholdRoleEditor); !ok {
+		return
+	}
+
+	transaction := models.RecurringTransaction{
+		UserID:      userID,
+		HouseholdID: payload.HouseholdID,
+		Description: payload.Description,
+		Kind:        payload.Kind,
+		Amount:      payload.Amount,
+		Cadence:     payload.Cadence,
+		StartDate:   startDate,
+		EndDate:     endDate,
+	}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&transaction).Error; err != nil {
+			return err
+		}
+		return notifyRecurringChange(tx, transaction)
+	})
+	if err != nil {
+		log.Printf("Error creating recurring transaction for user %d: %v", userID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+	go checkBalanceEvents(ownerScope{UserID: transaction.UserID, HouseholdID: transaction.HouseholdID})
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Recurring transaction created successfully", "recurringTransaction": toRecurringTransactionResponse(transaction)})
+}
+
+// loadRecurringTransaction busca a transação recorrente da rota e verifica se o usuário tem o papel
+// exigido. Em caso de falha, já responde à requisição e retorna false.
+func loadRecurringTransaction(c *gin.Context, minRole string) (models.RecurringTransaction, bool) {
+	var transaction models.RecurringTransaction
+	userID, ok := getUserID(c)
+	if !ok {
+		return transaction, false
+	}
+	transactionID, ok := parseIDParam(c, "id")
+	if !ok {
+		return transaction, false
+	}
+
+	if err := database.DB.First(&transaction, transactionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			apierrors.Respond(c, http.StatusNotFound, apierrors.RecurringNotFound)
+		} else {
+			log.Printf("Error fetching recurring transaction %d: %v", transactionID, err)
+			apierrors.RespondInternal(c)
+		}
+		return transaction, false
+	}
+
+	if err := authorizeRecord(userID, transaction.UserID, transaction.HouseholdID, minRole); err != nil {
+		respondAuthorizationError(c, err, apierrors.RecurringNotFound)
+		return transaction, false
+	}
+	return transaction, true
+}
+
+// GetRecurringTransactionHandler retorna uma transação recorrente
+func GetRecurringTransactionHandler(c *gin.Context) {
+	transaction, ok := loadRecurringTransaction(c, models.HouseholdRoleViewer)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, toRecurringTransactionResponse(transaction))
+}
+
+// DeleteRecurringTransactionHandler remove uma transação recorrente, que deixa de entrar no saldo, no
+// fluxo de caixa e na previsão
+func DeleteRecurringTransactionHandler(c *gin.Context) {
+	transaction, ok := loadRecurringTransaction(c, models.HouseholdRoleEditor)
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.RecurringTransaction{}, transaction.ID).Error; err != nil {
+			return err
+		}
+		return notifyRecurringChange(tx, transaction)
+	})
+	if err != nil {
+		log.Printf("Error deleting recurring transaction %d: %v", transaction.ID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+	go checkBalanceEvents(ownerScope{UserID: transaction.UserID, HouseholdID: transaction.HouseholdID})
+	c.JSON(http.StatusOK, gin.H{"message": "Recurring transaction deleted successfully"})
+}
+
+// notifyRecurringChange publica a alteração do saldo do escopo da transação (ver
+// notifyBalanceChange), após o commit da transação do banco.
+func notifyRecurringChange(tx *gorm.DB, transaction models.RecurringTransaction) error {
+	return database.NotifyChange(tx, database.Change{Entity: models.ChangeEntityRecurring, UserID: transaction.UserID, HouseholdID: transaction.HouseholdID})
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"personal-finance-app/backend/apierrors"
+	"personal-finance-app/backend/database"
+	"personal-finance-app/backend/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var (
+	errNotHouseholdMember = errors.New("user is not a member of the household")
+	errInsufficientRole   = errors.New("household role does not allow this action")
+	errNotRecordOwner     = errors.New("record belongs to another user")
+)
+
+// householdRoleRank ordena os papéis de domicílio do menos para o mais privilegiado.
+var householdRoleRank = map[string]int{
+	models.HouseholdRoleViewer: 1,
+	models.HouseholdRoleEditor: 2,
+	models.HouseholdRoleOwner:  3,
+}
+
+// ownerScope identifica a quem pertencem os dados consultados: ao usuário (dados pessoais)
+// ou a um domicílio compartilhado.
+type ownerScope struct {
+	UserID      uint
+	HouseholdID *uint
+}
+
+// apply restringe uma consulta de despesas ao escopo.
+func (s ownerScope) apply(db *gorm.DB) *gorm.DB {
+	if s.HouseholdID != nil {
+		return db.Where("household_id = ?", *s.HouseholdID)
+	}
+	return db.Where("user_id = ? AND household_id IS NULL", s.UserID)
+}
+
+// getUserID extrai o ID do usuário autenticado, armazenado no contexto pelo AuthMiddleware.
+// Em caso de falha, já responde à requisição e retorna false.
+func getUserID(c *gin.Context) (uint, bool) {
+	userIDStr, exists := c.Get("userID")
+	if !exists {
+		apierrors.Respond(c, http.StatusUnauthorized, apierrors.Unauthenticated)
+		return 0, false
+	}
+	userID, err := strconv.ParseUint(userIDStr.(string), 10, 32)
+	if err != nil {
+		log.Printf("Invalid user ID format in token: %q", userIDStr)
+		apierrors.RespondInternal(c)
+		return 0, false
+	}
+	return uint(userID), true
+}
+
+// parseIDParam lê um parâmetro de rota numérico (ex: /expenses/:id).
+// Em caso de falha, já responde à requisição e retorna false.
+func parseIDParam(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil {
+		apierrors.Respond(c, http.StatusBadRequest, apierrors.InvalidParameter, name)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// authorizeHousehold verifica se o usuário é membro do domicílio com, no mínimo, o papel informado.
+func authorizeHousehold(userID, householdID uint, minRole string) (*models.HouseholdMember, error) {
+	var member models.HouseholdMember
+	err := database.DB.Joins("JOIN households ON households.id = household_members.household_id AND households.deleted_at IS NULL").
+		Where("household_members.household_id = ? AND household_members.user_id = ?", householdID, userID).
+		First(&member).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errNotHouseholdMember
+	}
+	if err != nil {
+		return nil, err
+	}
+	if householdRoleRank[member.Role] < householdRoleRank[minRole] {
+		return &member, errInsufficientRole
+	}
+	return &member, nil
+}
+
+// authorizeRecord verifica se o usuário pode acessar um registro: registros de domicílio exigem
+// o papel mínimo no domicílio, registros pessoais exigem que o usuário seja o dono.
+func authorizeRecord(userID, recordUserID uint, householdID *uint, minRole string) error {
+	if householdID != nil {
+		_, err := authorizeHousehold(userID, *householdID, minRole)
+		return err
+	}
+	if recordUserID != userID {
+		return errNotRecordOwner
+	}
+	return nil
+}
+
+// respondAuthorizationError traduz erros de autorização em respostas HTTP.
+// notFoundCode é usado quando o registro não deve ser revelado ao usuário.
+func respondAuthorizationError(c *gin.Context, err error, notFoundCode apierrors.Code) {
+	switch {
+	case errors.Is(err, errNotHouseholdMember), errors.Is(err, errNotRecordOwner):
+		apierrors.Respond(c, http.StatusNotFound, notFoundCode)
+	case errors.Is(err, errInsufficientRole):
+		apierrors.Respond(c, http.StatusForbidden, apierrors.ForbiddenRole)
+	default:
+		log.Printf("Error checking household membership: %v", err)
+		apierrors.RespondInternal(c)
+	}
+}
+
+// resolveScope monta o escopo da requisição: pessoal, ou o domicílio informado caso o usuário
+// tenha nele ao menos o papel exigido. Em caso de falha, já responde à requisição e retorna false.
+func resolveScope(c *gin.Context, userID uint, householdID *uint, minRole string) (ownerScope, bool) {
+	scope := ownerScope{UserID: userID, HouseholdID: householdID}
+	if householdID == nil {
+		return scope, true
+	}
+	if _, err := authorizeHousehold(userID, *householdID, minRole); err != nil {
+		respondAuthorizationError(c, err, apierrors.HouseholdNotFound)
+		return scope, false
+	}
+	return scope, true
+}
+
+// householdIDFromQuery lê o parâmetro opcional ?householdId=.
+// Em caso de formato inválido, já responde à requisição e retorna false.
+func householdIDFromQuery(c *gin.Context) (*uint, bool) {
+	raw := c.Query("householdId")
+	if raw == "" {
+		return nil, true
+	}
+	id, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil {
+		apierrors.Respond(c, http.StatusBadRequest, apierrors.InvalidParameter, "householdId")
+		return nil, false
+	}
+	householdID := uint(id)
+	return &householdID, true
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"personal-finance-app/backend/apierrors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newServiceError cria um erro de regra de negócio das funções compartilhadas entre as APIs
+// (HTTP, gRPC e GraphQL): um *apierrors.Error com o status HTTP e o código do erro.
+func newServiceError(status int, code apierrors.Code, args ...interface{}) *apierrors.Error {
+	return apierrors.New(status, code, args...)
+}
+
+// authorizationServiceError traduz erros de autorização em erros de regra de negócio, como
+// respondAuthorizationError.
+func authorizationServiceError(err error, notFoundCode apierrors.Code) error {
+	switch {
+	case errors.Is(err, errNotHouseholdMember), errors.Is(err, errNotRecordOwner):
+		return newServiceError(http.StatusNotFound, notFoundCode)
+	case errors.Is(err, errInsufficientRole):
+		return newServiceError(http.StatusForbidden, apierrors.ForbiddenRole)
+	}
+	return err
+}
+
+// respondServiceError responde com o status e o código de um *apierrors.Error. Outros erros são
+// registrados no log (com internalMessage) e respondidos com 500, sem o texto do erro.
+func respondServiceError(c *gin.Context, err error, internalMessage string) {
+	var serviceErr *apierrors.Error
+	if errors.As(err, &serviceErr) {
+		apierrors.RespondError(c, serviceErr)
+		return
+	}
+	log.Printf("%s: %v", internalMessage, err)
+	apierrors.RespondInternal(c)
+}
@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"log"
+	"net/http"
+	"personal-finance-app/backend/apierrors"
+	"personal-finance-app/backend/database"
+	"personal-finance-app/backend/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultSyncPageSize = 500
+	maxSyncPageSize     = 1000
+)
+
+// syncCursor é o conteúdo (codificado em base64) do cursor do feed de alterações: a posição
+// (transação, sequência) da última entrada entregue ao cliente.
+type syncCursor struct {
+	TxID int64  `json:"t"`
+	Seq  uint64 `json:"s"`
+}
+
+// encode serializa o cursor em uma string opaca para o cliente.
+func (cur syncCursor) encode() string {
+	raw, _ := json.Marshal(cur)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeSyncCursor interpreta o cursor recebido em ?since=. Vazio indica sincronização completa.
+func decodeSyncCursor(encoded string) (syncCursor, bool) {
+	var cur syncCursor
+	if encoded == "" {
+		return cur, true
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cur, false
+	}
+	if err := json.Unmarshal(raw, &cur); err != nil {
+		return cur, false
+	}
+	return cur, true
+}
+
+// IncomeResponse é a representação JSON da renda mensal no feed de sincronização
+type IncomeResponse struct {
+	ID            uint      `json:"id"`
+	UserID        uint      `json:"userId"`
+	MonthlyIncome float64   `json:"monthlyIncome"`
+	PayDay        int       `json:"payDay"` // 0 se não informado
+	Version       uint      `json:"version"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// toIncomeResponse converte o modelo de renda para sua representação JSON
+func toIncomeResponse(income models.Income) IncomeResponse {
+	return IncomeResponse{
+		ID:            income.ID,
+		UserID:        income.UserID,
+		MonthlyIncome: income.MonthlyIncome,
+		PayDay:        income.PayDay,
+		Version:       income.Version,
+		CreatedAt:     income.CreatedAt,
+		UpdatedAt:     income.UpdatedAt,
+	}
+}
+
+// CategoryResponse é a representação JSON de uma categoria no feed de sincronização
+type CategoryResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Version   uint      `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// SyncChange é uma alteração de registro: "upsert" traz o estado atual em Data;
+// "delete" (tombstone) traz apenas a entidade e o ID.
+type SyncChange struct {
+	Entity    string      `json:"entity"` // income, fixed_expense, variable_expense ou category
+	ID        uint        `json:"id"`
+	Operation string      `json:"operation"`         // "upsert" ou "delete"
+	Version   uint        `json:"version,omitempty"` // Versão atual do registro (apenas upsert)
+	Data      interface{} `json:"data,omitempty"`
+}
+
+// SyncFeedResponse é uma página do feed de alterações
+type SyncFeedResponse struct {
+	Changes []SyncChange `json:"changes"`
+	Cursor  string       `json:"cursor"` // Enviar em ?since= na próxima sincronização
+	HasMore bool         `json:"hasMore"`
+}
+
+// loadSyncRecords busca o estado atual dos registros de uma entidade, como alterações "upsert".
+// Registros que não existem mais (ou estão na lixeira) ficam de fora do mapa.
+func loadSyncRecords(entity string, ids []uint) (map[uint]SyncChange, error) {
+	records := make(map[uint]SyncChange, len(ids))
+	upsert := func(id, version uint, data interface{}) {
+		records[id] = SyncChange{Entity: entity, ID: id, Operation: models.ChangeOperationUpsert, Version: version, Data: data}
+	}
+
+	switch entity {
+	case models.SyncEntityIncome:
+		var incomes []models.Income
+		if err := database.DB.Where("id IN ?", ids).Find(&incomes).Error; err != nil {
+			return nil, err
+		}
+		for _, income := range incomes {
+			upsert(income.ID, income.Version, toIncomeResponse(income))
+		}
+	case models.SyncEntityFixedExpense:
+		var fixedExpenses []models.FixedExpense
+		if err := database.DB.Where("id IN ?", ids).Find(&fixedExpenses).Error; err != nil {
+			return nil, err
+		}
+		for _, fe := range fixedExpenses {
+			upsert(fe.ID, fe.Version, toFixedExpenseResponse(fe))
+		}
+	case models.SyncEntityVariableExpense:
+		var expenses []models.VariableExpense
+		if err := database.DB.Preload("Splits").Where("id IN ?", ids).Find(&expenses).Error; err != nil {
+			return nil, err
+		}
+		for _, expense := range expenses {
+			upsert(expense.ID, expense.Version, toExpenseResponse(expense))
+		}
+	case models.SyncEntityCategory:
+		var categories []models.Category
+		if err := database.DB.Where("id IN ?", ids).Find(&categories).Error; err != nil {
+			return nil, err
+		}
+		for _, category := range categories {
+			upsert(category.ID, category.Version, CategoryResponse{
+				ID:        category.ID,
+				Name:      category.Name,
+				Version:   category.Version,
+				CreatedAt: category.CreatedAt,
+				UpdatedAt: category.UpdatedAt,
+			})
+		}
+	}
+	return records, nil
+}
+
+// currentSyncState retorna o estado atual de um registro, ou um tombstone se ele não existir mais.
+func currentSyncState(entity string, id uint) (SyncChange, error) {
+	records, err := loadSyncRecords(entity, []uint{id})
+	if err != nil {
+		return SyncChange{}, err
+	}
+	if record, found := records[id]; found {
+		return record, nil
+	}
+	return SyncChange{Entity: entity, ID: id, Operation: models.ChangeOperationDelete}, nil
+}
+
+// GetSyncHandler retorna as alterações (upserts e tombstones) em rendas, despesas fixas, despesas
+// variáveis e categorias visíveis ao usuário (pessoais e dos domicílios de que participa) desde o
+// cursor ?since=. Sem ?since=, retorna tudo (sincronização completa). Use ?limit= para o tamanho da
+// página e repita com o cursor retornado enquanto hasMore for true.
+//
+// As entradas são entregues na ordem das transações e somente quando nenhuma transação anterior
+// ainda está em andamento, de modo que uma alteração confirmada depois não fica para trás do cursor.
+// Cada registro aparece no máximo uma vez por página, com seu estado atual. Ao entrar em um
+// domicílio, o cliente deve fazer uma sincronização completa para receber os dados já existentes.
+func GetSyncHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	cursor, ok := decodeSyncCursor(c.Query("since"))
+	if !ok {
+		apierrors.Respond(c, http.StatusBadRequest, apierrors.InvalidCursor, "since")
+		return
+	}
+	limit := defaultSyncPageSize
+	if rawLimit := c.Query("limit"); rawLimit != "" {
+		parsed, err := strconv.Atoi(rawLimit)
+		if err != nil || parsed < 1 || parsed > maxSyncPageSize {
+			apierrors.Respond(c, http.StatusBadRequest, apierrors.InvalidLimit, maxSyncPageSize)
+			return
+		}
+		limit = parsed
+	}
+
+	memberships := database.DB.Model(&models.HouseholdMember{}).Select("household_id").Where("user_id = ?", userID)
+	var entries []models.ChangeLog
+	err := database.DB.
+		Where("(tx_id, seq) > (?, ?)", cursor.TxID, cursor.Seq).
+		// Transações anteriores ao xmin do snapshot já terminaram: nada mais será gravado antes delas
+		Where("tx_id < txid_snapshot_xmin(txid_current_snapshot())").
+		Where("(household_id IS NULL AND user_id = ?) OR household_id IN (?)", userID, memberships).
+		Order("tx_id, seq").
+		Limit(limit + 1).
+		Find(&entries).Error
+	if err != nil {
+		log.Printf("Error reading change feed for user %d: %v", userID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+
+	hasMore := len(entries) > limit
+	if hasMore {
+		entries = entries[:limit]
+	}
+	if len(entries) > 0 {
+		last := entries[len(entries)-1]
+		cursor = syncCursor{TxID: last.TxID, Seq: last.Seq}
+	}
+
+	// Apenas a última entrada de cada registro na página importa
+	type recordKey struct {
+		entity string
+		id     uint
+	}
+	lastEntry := make(map[recordKey]int, len(entries))
+	idsByEntity := make(map[string][]uint)
+	for i, entry := range entries {
+		key := recordKey{entry.Entity, entry.EntityID}
+		if _, seen := lastEntry[key]; !seen {
+			idsByEntity[entry.Entity] = append(idsByEntity[entry.Entity], entry.EntityID)
+		}
+		lastEntry[key] = i
+	}
+
+	current := make(map[string]map[uint]SyncChange, len(idsByEntity))
+	for entity, ids := range idsByEntity {
+		records, err := loadSyncRecords(entity, ids)
+		if err != nil {
+			log.Printf("Error loading %s records for sync of user %d: %v", entity, userID, err)
+			apierrors.RespondInternal(c)
+			return
+		}
+		current[entity] = records
+	}
+
+	changes := make([]SyncChange, 0, len(lastEntry))
+	for i, entry := range entries {
+		if lastEntry[recordKey{entry.Entity, entry.EntityID}] != i {
+			continue
+		}
+		// O estado atual prevalece sobre a operação registrada (ex: despesa restaurada da lixeira)
+		record, found := current[entry.Entity][entry.EntityID]
+		if !found {
+			record = SyncChange{Entity: entry.Entity, ID: entry.EntityID, Operation: models.ChangeOperationDelete}
+		}
+		changes = append(changes, record)
+	}
+
+	c.JSON(http.StatusOK, SyncFeedResponse{Changes: changes, Cursor: cursor.encode(), HasMore: hasMore})
+}
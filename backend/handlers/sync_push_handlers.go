@@ -0,0 +1,496 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"personal-finance-app/backend/apierrors"
+	"personal-finance-app/backend/database"
+	"personal-finance-app/backend/models"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Status possíveis de cada alteração enviada pelo cliente
+const (
+	syncPushApplied   = "applied"
+	syncPushConflict  = "conflict"  // A versão de base não é a atual; current traz o estado do servidor
+	syncPushDuplicate = "duplicate" // Criação já aplicada antes (mesmo clientId ou nome de categoria)
+	syncPushInvalid   = "invalid"
+	syncPushNotFound  = "not_found"
+)
+
+// SyncPushChange é uma alteração feita no cliente. Sem id, "upsert" cria o registro; com id,
+// altera o registro cuja versão atual deve ser baseVersion (a última versão recebida pelo cliente).
+type SyncPushChange struct {
+	Entity      string          `json:"entity" binding:"required,oneof=income fixed_expense variable_expense category"`
+	Operation   string          `json:"operation" binding:"required,oneof=upsert delete"`
+	ID          uint            `json:"id"`
+	BaseVersion uint            `json:"baseVersion"`
+	ClientID    string          `json:"clientId" binding:"omitempty,max=100"` // Evita duplicatas de despesas variáveis criadas offline
+	Data        json.RawMessage `json:"data"`                                 // Mesmos campos do registro no feed
+}
+
+// SyncPushPayload define a estrutura para enviar as alterações feitas offline.
+// As alterações são recebidas sem validação para que cada uma seja validada individualmente.
+type SyncPushPayload struct {
+	Changes []json.RawMessage `json:"changes" binding:"required,min=1"`
+}
+
+// SyncPushResult é o resultado de uma alteração enviada
+type SyncPushResult struct {
+	Index               int         `json:"index"`
+	Entity              string      `json:"entity,omitempty"`
+	ClientID            string      `json:"clientId,omitempty"`
+	Status              string      `json:"status"`
+	apierrors.ItemError             // Motivo, nos status "invalid", "not_found" e "conflict"
+	ID                  uint        `json:"id,omitempty"`      // Registro criado, alterado ou removido
+	Current             *SyncChange `json:"current,omitempty"` // Estado atual no servidor (em "applied", "conflict" e "duplicate")
+}
+
+// SyncIncomePayload define os dados da renda enviados na sincronização
+type SyncIncomePayload struct {
+	MonthlyIncome *float64 `json:"monthlyIncome" binding:"required,gte=0"`
+	PayDay        int      `json:"payDay" binding:"omitempty,min=1,max=31"`
+}
+
+// SyncCategoryPayload define os dados de uma categoria enviados na sincronização
+type SyncCategoryPayload struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// syncPushError é uma alteração rejeitada, com o status e o motivo (nil em "duplicate") informados ao cliente.
+type syncPushError struct {
+	status string
+	reason *apierrors.Error
+}
+
+func (e *syncPushError) Error() string {
+	if e.reason == nil {
+		return e.status
+	}
+	return e.reason.Error()
+}
+
+// invalidSyncChange rejeita uma alteração inválida com o motivo informado.
+func invalidSyncChange(reason *apierrors.Error) error {
+	return &syncPushError{status: syncPushInvalid, reason: reason}
+}
+
+// householdSyncError rejeita a criação de um registro em um domicílio que o usuário não pode editar.
+func householdSyncError(err error) error {
+	var reason *apierrors.Error
+	if !errors.As(authorizationServiceError(err, apierrors.HouseholdNotFound), &reason) {
+		reason = apierrors.New(http.StatusNotFound, apierrors.HouseholdNotFound)
+	}
+	return invalidSyncChange(reason)
+}
+
+var (
+	errSyncNotFound  = &syncPushError{status: syncPushNotFound, reason: apierrors.New(http.StatusNotFound, apierrors.SyncRecordNotFound)}
+	errSyncConflict  = &syncPushError{status: syncPushConflict, reason: apierrors.New(http.StatusConflict, apierrors.SyncConflict)}
+	errSyncDuplicate = &syncPushError{status: syncPushDuplicate}
+)
+
+// decodeSyncData interpreta e valida os dados de uma alteração.
+func decodeSyncData(data json.RawMessage, target interface{}) error {
+	if len(data) == 0 {
+		return invalidSyncChange(apierrors.New(http.StatusBadRequest, apierrors.SyncDataRequired))
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return invalidSyncChange(apierrors.Invalid(err))
+	}
+	if err := binding.Validator.ValidateStruct(target); err != nil {
+		return invalidSyncChange(apierrors.Invalid(err))
+	}
+	return nil
+}
+
+// requireBaseVersion garante que alterações e remoções informem a versão em que se basearam.
+func requireBaseVersion(change SyncPushChange) error {
+	if change.BaseVersion == 0 {
+		return invalidSyncChange(apierrors.New(http.StatusBadRequest, apierrors.BaseVersionRequired))
+	}
+	return nil
+}
+
+// authorizeSyncRecord aplica as regras de escrita das rotas REST (dono do registro pessoal ou
+// editor do domicílio). Registros inacessíveis são tratados como inexistentes.
+func authorizeSyncRecord(userID, recordUserID uint, householdID *uint) error {
+	err := authorizeRecord(userID, recordUserID, householdID, models.HouseholdRoleEditor)
+	if errors.Is(err, errNotHouseholdMember) || errors.Is(err, errInsufficientRole) || errors.Is(err, errNotRecordOwner) {
+		return errSyncNotFound
+	}
+	return err
+}
+
+// notFoundAsSync converte a ausência do registro no erro correspondente da sincronização.
+func notFoundAsSync(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errSyncNotFound
+	}
+	return err
+}
+
+// applySyncChange aplica uma alteração em uma transação e retorna o ID do registro afetado.
+func applySyncChange(userID uint, change SyncPushChange) (uint, error) {
+	var id uint
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		switch change.Entity {
+		case models.SyncEntityIncome:
+			id, err = pushIncome(tx, userID, change)
+		case models.SyncEntityFixedExpense:
+			id, err = pushFixedExpense(tx, userID, change)
+		case models.SyncEntityVariableExpense:
+			id, err = pushVariableExpense(tx, userID, change)
+		case models.SyncEntityCategory:
+			id, err = pushCategory(tx, userID, change)
+		}
+		return err
+	})
+	return id, err
+}
+
+// pushIncome cria ou altera a renda mensal do usuário. A renda não pode ser removida.
+func pushIncome(tx *gorm.DB, userID uint, change SyncPushChange) (uint, error) {
+	if change.Operation == models.ChangeOperationDelete {
+		return change.ID, invalidSyncChange(apierrors.New(http.StatusBadRequest, apierrors.IncomeNotDeletable))
+	}
+	var payload SyncIncomePayload
+	if err := decodeSyncData(change.Data, &payload); err != nil {
+		return change.ID, err
+	}
+
+	if change.ID == 0 {
+		// Cada usuário tem uma única renda: se já existir, o cliente precisa partir dela
+		income := models.Income{UserID: userID, MonthlyIncome: *payload.MonthlyIncome, PayDay: payload.PayDay, Version: 1}
+		var existing models.Income
+		if err := tx.Where("user_id = ?", userID).First(&existing).Error; err == nil {
+			return existing.ID, errSyncConflict
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, err
+		}
+		if err := tx.Create(&income).Error; err != nil {
+			return 0, err
+		}
+		return income.ID, nil
+	}
+
+	if err := requireBaseVersion(change); err != nil {
+		return change.ID, err
+	}
+	var income models.Income
+	if err := tx.Where("user_id = ?", userID).First(&income, change.ID).Error; err != nil {
+		return change.ID, notFoundAsSync(err)
+	}
+	result := tx.Model(&models.Income{}).
+		Where("id = ? AND version = ?", income.ID, change.BaseVersion).
+		Updates(map[string]interface{}{"monthly_income": *payload.MonthlyIncome, "pay_day": payload.PayDay, "version": gorm.Expr("version + 1")})
+	if result.Error == nil && result.RowsAffected == 0 {
+		return income.ID, errSyncConflict
+	}
+	return income.ID, result.Error
+}
+
+// pushFixedExpense cria, altera (campos informados, como no PATCH) ou remove uma despesa fixa.
+func pushFixedExpense(tx *gorm.DB, userID uint, change SyncPushChange) (uint, error) {
+	if change.Operation == models.ChangeOperationUpsert && change.ID == 0 {
+		var payload CreateFixedExpensePayload
+		if err := decodeSyncData(change.Data, &payload); err != nil {
+			return 0, err
+		}
+		if err := trimFixedExpenseName(&payload, &payload.Name); err != nil {
+			return 0, invalidSyncChange(apierrors.Invalid(err))
+		}
+		if payload.HouseholdID != nil {
+			if _, err := authorizeHousehold(userID, *payload.HouseholdID, models.HouseholdRoleEditor); err != nil {
+				return 0, householdSyncError(err)
+			}
+		}
+		fixedExpense := models.FixedExpense{
+			UserID:      userID,
+			HouseholdID: payload.HouseholdID,
+			Name:        payload.Name,
+			Value:       payload.Value,
+			DueDay:      payload.DueDay,
+			Category:    payload.Category,
+			Active:      payload.Active == nil || *payload.Active,
+			Version:     1,
+		}
+		if err := createFixedExpense(tx, &fixedExpense); err != nil {
+			return 0, err
+		}
+		return fixedExpense.ID, nil
+	}
+
+	if err := requireBaseVersion(change); err != nil {
+		return change.ID, err
+	}
+	var fixedExpense models.FixedExpense
+	if err := tx.First(&fixedExpense, change.ID).Error; err != nil {
+		return change.ID, notFoundAsSync(err)
+	}
+	if err := authorizeSyncRecord(userID, fixedExpense.UserID, fixedExpense.HouseholdID); err != nil {
+		return change.ID, err
+	}
+
+	var result *gorm.DB
+	if change.Operation == models.ChangeOperationDelete {
+		result = tx.Where("version = ?", change.BaseVersion).Delete(&fixedExpense)
+	} else {
+		var patch PatchFixedExpensePayload
+		if err := decodeSyncData(change.Data, &patch); err != nil {
+			return change.ID, err
+		}
+		if err := trimFixedExpenseName(&patch, patch.Name); err != nil {
+			return change.ID, invalidSyncChange(apierrors.Invalid(err))
+		}
+		updates := fixedExpensePatchUpdates(patch)
+		if updates == nil { // Nada a alterar: basta a versão ainda ser a atual
+			if fixedExpense.Version != change.BaseVersion {
+				return change.ID, errSyncConflict
+			}
+			return change.ID, nil
+		}
+		result = tx.Model(&models.FixedExpense{}).
+			Where("id = ? AND version = ?", fixedExpense.ID, change.BaseVersion).
+			Updates(updates)
+	}
+	if result.Error == nil && result.RowsAffected == 0 {
+		return change.ID, errSyncConflict
+	}
+	return change.ID, result.Error
+}
+
+// pushVariableExpense cria, substitui (como no PUT) ou move para a lixeira uma despesa variável.
+func pushVariableExpense(tx *gorm.DB, userID uint, change SyncPushChange) (uint, error) {
+	if change.Operation == models.ChangeOperationUpsert && change.ID == 0 {
+		var payload CreateExpensePayload
+		if err := decodeSyncData(change.Data, &payload); err != nil {
+			return 0, err
+		}
+		if payload.HouseholdID != nil {
+			if _, err := authorizeHousehold(userID, *payload.HouseholdID, models.HouseholdRoleEditor); err != nil {
+				return 0, householdSyncError(err)
+			}
+		}
+		expense := models.VariableExpense{UserID: userID, HouseholdID: payload.HouseholdID}
+		if err := applyExpensePayload(&expense, payload, time.Now()); err != nil {
+			return 0, invalidSyncChange(err)
+		}
+		if change.ClientID != "" {
+			clientRef := change.ClientID
+			expense.ClientRef = &clientRef
+		}
+
+		splits := expense.Splits
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Omit("Splits").Create(&expense)
+		if result.Error != nil {
+			return 0, result.Error
+		}
+		if result.RowsAffected == 0 && expense.ClientRef != nil {
+			// clientId já gravado: a criação foi aplicada em uma sincronização anterior
+			var existing models.VariableExpense
+			if err := tx.Unscoped().Select("id").Where("user_id = ? AND client_ref = ?", userID, *expense.ClientRef).First(&existing).Error; err != nil {
+				return 0, err
+			}
+			return existing.ID, errSyncDuplicate
+		}
+		for i := range splits {
+			splits[i].VariableExpenseID = expense.ID
+		}
+		if len(splits) > 0 {
+			if err := tx.Create(&splits).Error; err != nil {
+				return expense.ID, err
+			}
+		}
+		return expense.ID, nil
+	}
+
+	if err := requireBaseVersion(change); err != nil {
+		return change.ID, err
+	}
+	var expense models.VariableExpense
+	if err := tx.Preload("Splits").First(&expense, change.ID).Error; err != nil {
+		return change.ID, notFoundAsSync(err)
+	}
+	if err := authorizeSyncRecord(userID, expense.UserID, expense.HouseholdID); err != nil {
+		return change.ID, err
+	}
+
+	if change.Operation == models.ChangeOperationDelete {
+		result := tx.Where("version = ?", change.BaseVersion).Delete(&expense)
+		if result.Error == nil && result.RowsAffected == 0 {
+			return change.ID, errSyncConflict
+		}
+		return change.ID, result.Error
+	}
+
+	var payload CreateExpensePayload
+	if err := decodeSyncData(change.Data, &payload); err != nil {
+		return change.ID, err
+	}
+	if payload.HouseholdID != nil && (expense.HouseholdID == nil || *payload.HouseholdID != *expense.HouseholdID) {
+		return change.ID, invalidSyncChange(apierrors.New(http.StatusBadRequest, apierrors.HouseholdChangeDenied))
+	}
+	if err := applyExpensePayload(&expense, payload, expense.Date); err != nil {
+		return change.ID, invalidSyncChange(err)
+	}
+	if err := writeExpenseUpdate(tx, &expense, change.BaseVersion); err != nil {
+		if errors.Is(err, errStaleVersion) {
+			return change.ID, errSyncConflict
+		}
+		return change.ID, err
+	}
+	return change.ID, nil
+}
+
+// pushCategory cria, renomeia ou remove uma categoria do usuário.
+func pushCategory(tx *gorm.DB, userID uint, change SyncPushChange) (uint, error) {
+	var payload SyncCategoryPayload
+	if change.Operation == models.ChangeOperationUpsert {
+		if err := decodeSyncData(change.Data, &payload); err != nil {
+			return change.ID, err
+		}
+		payload.Name = strings.TrimSpace(payload.Name)
+		if err := binding.Validator.ValidateStruct(&payload); err != nil {
+			return change.ID, invalidSyncChange(apierrors.Invalid(err)) // Nome só de espaços
+		}
+	}
+
+	if change.Operation == models.ChangeOperationUpsert && change.ID == 0 {
+		category := models.Category{UserID: userID, Name: payload.Name, Version: 1}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&category)
+		if result.Error != nil {
+			return 0, result.Error
+		}
+		if result.RowsAffected == 0 {
+			var existing models.Category
+			if err := tx.Where("user_id = ? AND name = ?", userID, payload.Name).First(&existing).Error; err != nil {
+				return 0, err
+			}
+			return existing.ID, errSyncDuplicate
+		}
+		return category.ID, nil
+	}
+
+	if err := requireBaseVersion(change); err != nil {
+		return change.ID, err
+	}
+	var category models.Category
+	if err := tx.Where("user_id = ?", userID).First(&category, change.ID).Error; err != nil {
+		return change.ID, notFoundAsSync(err)
+	}
+
+	var result *gorm.DB
+	if change.Operation == models.ChangeOperationDelete {
+		result = tx.Where("version = ?", change.BaseVersion).Delete(&category)
+	} else {
+		var taken int64
+		if err := tx.Model(&models.Category{}).Where("user_id = ? AND name = ? AND id <> ?", userID, payload.Name, category.ID).Count(&taken).Error; err != nil {
+			return change.ID, err
+		}
+		if taken > 0 {
+			return change.ID, invalidSyncChange(apierrors.New(http.StatusConflict, apierrors.CategoryNameTaken))
+		}
+		result = tx.Model(&models.Category{}).
+			Where("id = ? AND version = ?", category.ID, change.BaseVersion).
+			Updates(map[string]interface{}{"name": payload.Name, "version": gorm.Expr("version + 1")})
+	}
+	if result.Error == nil && result.RowsAffected == 0 {
+		return change.ID, errSyncConflict
+	}
+	return change.ID, result.Error
+}
+
+// PostSyncHandler aplica as alterações feitas pelo cliente enquanto estava offline, na ordem do
+// envio e cada uma em sua própria transação. Alterações e remoções informam baseVersion, a versão
+// do registro em que o cliente se baseou; se o registro mudou no servidor desde então, a alteração
+// não é aplicada e o resultado "conflict" traz o estado atual para o cliente resolver.
+// Depois de enviar, o cliente deve buscar GET /sync para receber as versões gravadas.
+func PostSyncHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var payload SyncPushPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		apierrors.RespondInvalid(c, err)
+		return
+	}
+	// Mesmo limite do lote de despesas (EXPENSE_BATCH_MAX_SIZE)
+	if maxSize := expenseBatchMaxSize(); len(payload.Changes) > maxSize {
+		apierrors.Respond(c, http.StatusRequestEntityTooLarge, apierrors.TooManyItems, maxSize)
+		return
+	}
+
+	results := make([]SyncPushResult, len(payload.Changes))
+	summary := map[string]int{syncPushApplied: 0, syncPushConflict: 0, syncPushDuplicate: 0, syncPushInvalid: 0, syncPushNotFound: 0}
+	for i, raw := range payload.Changes {
+		results[i] = SyncPushResult{Index: i}
+
+		var change SyncPushChange
+		if err := json.Unmarshal(raw, &change); err != nil {
+			results[i].Status, results[i].ItemError = syncPushInvalid, apierrors.NewItemError(c, apierrors.Invalid(err))
+			summary[syncPushInvalid]++
+			continue
+		}
+		results[i].Entity, results[i].ClientID = change.Entity, change.ClientID
+		if err := binding.Validator.ValidateStruct(&change); err != nil {
+			results[i].Status, results[i].ItemError = syncPushInvalid, apierrors.NewItemError(c, apierrors.Invalid(err))
+			summary[syncPushInvalid]++
+			continue
+		}
+
+		id, err := applySyncChange(userID, change)
+		results[i].ID = id
+		var pushErr *syncPushError
+		switch {
+		case err == nil:
+			results[i].Status = syncPushApplied
+			if change.Entity == models.SyncEntityVariableExpense && change.Operation == models.ChangeOperationUpsert && change.ID == 0 {
+				var created models.VariableExpense
+				if database.DB.Preload("Splits").First(&created, id).Error == nil {
+					publishNewExpenses(created)
+				}
+			}
+			if change.Entity == models.SyncEntityVariableExpense && change.Operation == models.ChangeOperationDelete {
+				var deleted models.VariableExpense
+				if database.DB.Unscoped().Preload("Splits").First(&deleted, id).Error == nil {
+					publishDeletedExpense(deleted)
+				}
+			}
+		case errors.As(err, &pushErr):
+			results[i].Status, results[i].ItemError = pushErr.status, apierrors.NewItemError(c, pushErr.reason)
+		default:
+			log.Printf("Error applying sync change %d for user %d: %v", i, userID, err)
+			// As alterações anteriores já foram aplicadas: o cliente recebe seus resultados
+			c.JSON(http.StatusInternalServerError, struct {
+				apierrors.Body
+				Results []SyncPushResult `json:"results"`
+			}{apierrors.NewBody(c, apierrors.New(http.StatusInternalServerError, apierrors.InternalError)), results[:i]})
+			return
+		}
+		summary[results[i].Status]++
+
+		// Só registros a que o usuário tem acesso: em "not_found", o ID é o informado pelo cliente
+		if status := results[i].Status; (status == syncPushApplied || status == syncPushConflict || status == syncPushDuplicate) && id != 0 {
+			current, err := currentSyncState(change.Entity, id)
+			if err != nil {
+				log.Printf("Error loading %s %d after sync: %v", change.Entity, id, err)
+				continue
+			}
+			results[i].Current = &current
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": results, "summary": summary})
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"personal-finance-app/backend/apierrors"
+	"personal-finance-app/backend/database"
+	"personal-finance-app/backend/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	// trashRetentionDays é por quantos dias uma despesa removida pode ser restaurada.
+	trashRetentionDays = 30
+	// trashPurgeInterval é o intervalo entre as limpezas automáticas da lixeira.
+	trashPurgeInterval = time.Hour
+)
+
+// TrashedExpenseResponse é uma despesa na lixeira, com a data em que será removida definitivamente
+type TrashedExpenseResponse struct {
+	ExpenseResponse
+	DeletedAt time.Time `json:"deletedAt"`
+	PurgeAt   time.Time `json:"purgeAt"`
+}
+
+// trashedExpenses retorna uma consulta das despesas do escopo que estão na lixeira.
+func trashedExpenses(scope ownerScope) *gorm.DB {
+	return scope.apply(database.DB.Unscoped().Model(&models.VariableExpense{})).Where("deleted_at IS NOT NULL")
+}
+
+// ListTrashHandler lista as despesas na lixeira do usuário (ou de um domicílio, com ?householdId=)
+func ListTrashHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	householdID, ok := householdIDFromQuery(c)
+	if !ok {
+		return
+	}
+	scope, ok := resolveScope(c, userID, householdID, models.HouseholdRoleViewer)
+	if !ok {
+		return
+	}
+
+	var expenses []models.VariableExpense
+	if err := trashedExpenses(scope).Preload("Splits").Order("deleted_at desc, id desc").Find(&expenses).Error; err != nil {
+		log.Printf("Error listing trash for user %d: %v", userID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+
+	response := make([]TrashedExpenseResponse, 0, len(expenses))
+	for _, expense := range expenses {
+		response = append(response, TrashedExpenseResponse{
+			ExpenseResponse: toExpenseResponse(expense),
+			DeletedAt:       expense.DeletedAt.Time,
+			PurgeAt:         expense.DeletedAt.Time.AddDate(0, 0, trashRetentionDays),
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"expenses": response, "retentionDays": trashRetentionDays})
+}
+
+// RestoreExpenseHandler tira uma despesa da lixeira
+func RestoreExpenseHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	expenseID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var expense models.VariableExpense
+	if err := database.DB.Unscoped().Where("deleted_at IS NOT NULL").First(&expense, expenseID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			apierrors.Respond(c, http.StatusNotFound, apierrors.ExpenseNotInTrash)
+		} else {
+			log.Printf("Error fetching trashed expense %d: %v", expenseID, err)
+			apierrors.RespondInternal(c)
+		}
+		return
+	}
+
+	// Mesmas regras da remoção: dono da despesa pessoal ou editor do domicílio
+	if err := authorizeRecord(userID, expense.UserID, expense.HouseholdID, models.HouseholdRoleEditor); err != nil {
+		respondAuthorizationError(c, err, apierrors.ExpenseNotInTrash)
+		return
+	}
+
+	err := database.DB.Unscoped().Model(&models.VariableExpense{}).Where("id = ?", expense.ID).
+		Updates(map[string]interface{}{"deleted_at": nil, "version": gorm.Expr("version + 1")}).Error
+	if err != nil {
+		log.Printf("Error restoring expense %d: %v", expense.ID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+
+	if err := database.DB.Preload("Splits").First(&expense, expense.ID).Error; err != nil {
+		log.Printf("Error reloading expense %d: %v", expense.ID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+	setVersionETag(c, expense.Version)
+	c.JSON(http.StatusOK, gin.H{"message": "Expense restored successfully", "expense": toExpenseResponse(expense)})
+}
+
+// purgeExpenses remove definitivamente as despesas (e suas linhas de divisão) selecionadas pela consulta.
+func purgeExpenses(query *gorm.DB) (int64, error) {
+	var ids []uint
+	if err := query.Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	var purged int64
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("variable_expense_id IN ?", ids).Delete(&models.ExpenseSplit{}).Error; err != nil {
+			return err
+		}
+		result := tx.Unscoped().Where("id IN ?", ids).Delete(&models.VariableExpense{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	return purged, err
+}
+
+// EmptyTrashHandler remove definitivamente todas as despesas da lixeira do escopo
+func EmptyTrashHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	householdID, ok := householdIDFromQuery(c)
+	if !ok {
+		return
+	}
+	scope, ok := resolveScope(c, userID, householdID, models.HouseholdRoleEditor)
+	if !ok {
+		return
+	}
+
+	purged, err := purgeExpenses(trashedExpenses(scope))
+	if err != nil {
+		log.Printf("Error emptying trash for user %d: %v", userID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Trash emptied successfully", "purged": purged})
+}
+
+// StartTrashPurger inicia, em segundo plano, a remoção definitiva das despesas que estão
+// na lixeira há mais de trashRetentionDays dias.
+func StartTrashPurger() {
+	go func() {
+		for {
+			cutoff := time.Now().AddDate(0, 0, -trashRetentionDays)
+			query := database.DB.Unscoped().Model(&models.VariableExpense{}).Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff)
+			if purged, err := purgeExpenses(query); err != nil {
+				log.Printf("Error purging trashed expenses: %v", err)
+			} else if purged > 0 {
+				log.Printf("Purged %d expenses from trash", purged)
+			}
+			time.Sleep(trashPurgeInterval)
+		}
+	}()
+}
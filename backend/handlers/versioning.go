@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"personal-finance-app/backend/apierrors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// versionETag formata a versão de um registro como ETag (ex: "3").
+func versionETag(version uint) string {
+	return fmt.Sprintf("%q", strconv.FormatUint(uint64(version), 10))
+}
+
+// setVersionETag envia a versão atual do registro no cabeçalho ETag.
+func setVersionETag(c *gin.Context, version uint) {
+	c.Header("ETag", versionETag(version))
+}
+
+// checkIfMatch compara o cabeçalho If-Match da requisição com a versão atual do registro.
+// Sem If-Match (ou com "*"), a alteração é aceita. Se a versão do cliente estiver
+// desatualizada, já responde 412 Precondition Failed e retorna false.
+func checkIfMatch(c *gin.Context, currentVersion uint) bool {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return true
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == versionETag(currentVersion) {
+			return true
+		}
+	}
+	respondVersionConflict(c, currentVersion)
+	return false
+}
+
+// respondVersionConflict responde 412 Precondition Failed com a versão atual do registro, também
+// quando a gravação condicionada à versão lida não alterou nada, com ou sem If-Match.
+func respondVersionConflict(c *gin.Context, currentVersion uint) {
+	setVersionETag(c, currentVersion)
+	c.JSON(http.StatusPreconditionFailed, struct {
+		apierrors.Body
+		CurrentVersion uint `json:"currentVersion"`
+	}{apierrors.NewBody(c, apierrors.New(http.StatusPreconditionFailed, apierrors.VersionConflict)), currentVersion})
+}
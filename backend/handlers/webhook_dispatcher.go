@@ -0,0 +1,428 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"personal-finance-app/backend/database"
+	"personal-finance-app/backend/models"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Cabeçalhos enviados nas entregas de webhooks
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature" // "sha256=" + HMAC-SHA256 hex de "<timestamp>.<corpo>" com o segredo do webhook
+)
+
+const (
+	webhookMaxAttempts      = 8                // Tentativas antes de a entrega ser marcada como falha
+	webhookRetryBase        = 30 * time.Second // Espera após a 1ª falha; dobra a cada nova falha (30s, 1min, 2min, ... 32min)
+	webhookRequestTimeout   = 10 * time.Second
+	webhookDispatchInterval = 5 * time.Second
+	webhookDispatchBatch    = 20
+	webhookLease            = 2 * time.Minute // Adia a entrega enquanto ela é enviada, para outra instância não enviá-la também
+	balanceWatchInterval    = 15 * time.Minute
+)
+
+// errPrivateWebhookAddress recusa a conexão de uma entrega com um endereço interno.
+var errPrivateWebhookAddress = errors.New("webhook destination resolves to a private, loopback or link-local address")
+
+// webhookAllowPrivate informa se as entregas podem ir para endereços internos (loopback, rede
+// local, link-local), habilitado com WEBHOOK_ALLOW_PRIVATE=true em instalações próprias (ex:
+// automação residencial). Por padrão, são recusadas: qualquer usuário cadastra webhooks.
+func webhookAllowPrivate() bool {
+	allow, _ := strconv.ParseBool(os.Getenv("WEBHOOK_ALLOW_PRIVATE"))
+	return allow
+}
+
+// webhookDialControl recusa endereços internos no momento da conexão, já com o nome resolvido,
+// para que um DNS que mude de resposta depois do cadastro não alcance a rede interna.
+func webhookDialControl(network, address string, _ syscall.RawConn) error {
+	if webhookAllowPrivate() {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return errPrivateWebhookAddress
+	}
+	return nil
+}
+
+// webhookClient não segue redirecionamentos (um 3xx conta como falha da tentativa), não usa proxy
+// e só se conecta a endereços públicos (ver webhookDialControl).
+var webhookClient = &http.Client{
+	Timeout: webhookRequestTimeout,
+	Transport: &http.Transport{
+		DialContext:         (&net.Dialer{Timeout: webhookRequestTimeout, Control: webhookDialControl}).DialContext,
+		TLSHandshakeTimeout: webhookRequestTimeout,
+		MaxIdleConnsPerHost: 2,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// WebhookPayload é o corpo JSON enviado aos webhooks. ID identifica o evento e se repete nas
+// novas tentativas e reenvios, para que o destino possa ignorar duplicatas.
+type WebhookPayload struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Data      interface{} `json:"data"`
+}
+
+// HealthStatusChangedEvent são os dados do evento balance.health_status_changed.
+type HealthStatusChangedEvent struct {
+	HouseholdID      *uint   `json:"householdId"`
+	PreviousStatus   string  `json:"previousStatus"`
+	Status           string  `json:"status"` // "verde", "amarelo" ou "vermelho"
+	HealthPercentage float64 `json:"healthPercentage"`
+	CurrentBalance   float64 `json:"currentBalance"`
+}
+
+// AlertDayChangedEvent são os dados do evento balance.alert_day_changed. Dia vazio: sem alerta projetado.
+type AlertDayChangedEvent struct {
+	HouseholdID            *uint  `json:"householdId"`
+	PreviousYellowAlertDay string `json:"previousYellowAlertDay"`
+	YellowAlertDay         string `json:"yellowAlertDay"`
+	PreviousRedAlertDay    string `json:"previousRedAlertDay"`
+	RedAlertDay            string `json:"redAlertDay"`
+}
+
+// signWebhookPayload assina o corpo de uma entrega com o segredo do webhook. O timestamp entra na
+// assinatura para que o destino possa recusar entregas antigas reenviadas por terceiros.
+func signWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// randomHex gera um identificador aleatório com n bytes, em hexadecimal.
+func randomHex(n int) string {
+	random := make([]byte, n)
+	rand.Read(random)
+	return hex.EncodeToString(random)
+}
+
+// queueWebhookEvent registra uma entrega do evento para cada webhook. As entregas são enviadas em
+// segundo plano pelo StartWebhookDispatcher.
+func queueWebhookEvent(event string, webhooks []models.Webhook, data interface{}) {
+	if len(webhooks) == 0 {
+		return
+	}
+	payload := WebhookPayload{ID: randomHex(16), Event: event, CreatedAt: time.Now().UTC(), Data: data}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Error encoding webhook event %s: %v", event, err)
+		return
+	}
+
+	deliveries := make([]models.WebhookDelivery, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		deliveries = append(deliveries, models.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       payload.ID,
+			Event:         event,
+			Payload:       body,
+			Status:        models.WebhookDeliveryPending,
+			NextAttemptAt: payload.CreatedAt,
+		})
+	}
+	if err := database.DB.Create(&deliveries).Error; err != nil {
+		log.Printf("Error queueing webhook event %s: %v", event, err)
+	}
+}
+
+// queueExpenseWebhooks notifica um evento de despesa aos webhooks dos usuários que podem ver a
+// despesa (como em SubscribeNewExpenses): webhooks pessoais recebem as despesas pessoais do dono e as
+// dos domicílios de que ele participa; webhooks de domicílio, apenas as do domicílio. Em seguida,
+// verifica se a saúde financeira dos escopos afetados mudou.
+func queueExpenseWebhooks(event string, expenses []models.VariableExpense) {
+	scopes := make(map[string]ownerScope)
+	for _, expense := range expenses {
+		query := database.DB.Where("events LIKE ?", "%"+event+"%")
+		if expense.HouseholdID == nil {
+			query = query.Where("user_id = ? AND household_id IS NULL", expense.UserID)
+		} else {
+			members := database.DB.Model(&models.HouseholdMember{}).Select("user_id").Where("household_id = ?", *expense.HouseholdID)
+			query = query.Where("household_id = ? OR (household_id IS NULL AND user_id IN (?))", *expense.HouseholdID, members)
+		}
+		var candidates []models.Webhook
+		if err := query.Find(&candidates).Error; err != nil {
+			log.Printf("Error loading webhooks for expense %d: %v", expense.ID, err)
+			continue
+		}
+
+		var webhooks []models.Webhook
+		for _, webhook := range candidates {
+			if webhook.Subscribes(event) && authorizeRecord(webhook.UserID, expense.UserID, expense.HouseholdID, models.HouseholdRoleViewer) == nil {
+				webhooks = append(webhooks, webhook)
+			}
+		}
+		queueWebhookEvent(event, webhooks, toExpenseResponse(expense))
+
+		scope := ownerScope{UserID: expense.UserID, HouseholdID: expense.HouseholdID}
+		scopes[balanceScopeKey(scope)] = scope
+	}
+
+	for _, scope := range scopes {
+		go checkBalanceEvents(scope)
+	}
+}
+
+// balanceScopeKey identifica o escopo do saldo em models.BalanceWatch.
+func balanceScopeKey(scope ownerScope) string {
+	if scope.HouseholdID != nil {
+		return "household:" + strconv.FormatUint(uint64(*scope.HouseholdID), 10)
+	}
+	return "user:" + strconv.FormatUint(uint64(scope.UserID), 10)
+}
+
+// balanceWebhooks retorna os webhooks que assinam eventos de saldo do escopo e cujo dono ainda pode vê-lo.
+func balanceWebhooks(scope ownerScope) ([]models.Webhook, error) {
+	query := database.DB.Where("events LIKE ?", "%balance.%")
+	if scope.HouseholdID != nil {
+		query = query.Where("household_id = ?", *scope.HouseholdID)
+	} else {
+		query = query.Where("user_id = ? AND household_id IS NULL", scope.UserID)
+	}
+	var candidates []models.Webhook
+	if err := query.Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+
+	var webhooks []models.Webhook
+	for _, webhook := range candidates {
+		if scope.HouseholdID != nil {
+			if _, err := authorizeHousehold(webhook.UserID, *scope.HouseholdID, models.HouseholdRoleViewer); err != nil {
+				continue
+			}
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, nil
+}
+
+// subscribedTo filtra os webhooks que assinam o evento.
+func subscribedTo(webhooks []models.Webhook, event string) []models.Webhook {
+	var subscribed []models.Webhook
+	for _, webhook := range webhooks {
+		if webhook.Subscribes(event) {
+			subscribed = append(subscribed, webhook)
+		}
+	}
+	return subscribed
+}
+
+// balanceWatchMu serializa as verificações de saldo, para que uma mudança não seja notificada duas vezes.
+var balanceWatchMu sync.Mutex
+
+// checkBalanceEvents recalcula o saldo do escopo (como o GET /balance) e, se a saúde financeira ou os
+// dias de alerta da projeção mudaram desde a última verificação, notifica os webhooks do escopo.
+// A primeira verificação de um escopo apenas registra o estado atual.
+func checkBalanceEvents(scope ownerScope) {
+	balanceWatchMu.Lock()
+	defer balanceWatchMu.Unlock()
+
+	webhooks, err := balanceWebhooks(scope)
+	if err != nil {
+		log.Printf("Error loading balance webhooks for %s: %v", balanceScopeKey(scope), err)
+		return
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+
+	balance, err := computeBalance(scope)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) { // Sem renda cadastrada, não há saúde financeira
+			log.Printf("Error computing balance for %s: %v", balanceScopeKey(scope), err)
+		}
+		return
+	}
+	current := models.BalanceWatch{ScopeKey: balanceScopeKey(scope), HealthStatus: balance.FinancialHealthStatus}
+	if balance.Projection != nil {
+		current.YellowAlertDay, current.RedAlertDay = balance.Projection.YellowAlertDay, balance.Projection.RedAlertDay
+	}
+
+	var previous models.BalanceWatch
+	err = database.DB.Where("scope_key = ?", current.ScopeKey).First(&previous).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if err := database.DB.Create(&current).Error; err != nil {
+			log.Printf("Error saving balance watch for %s: %v", current.ScopeKey, err)
+		}
+		return
+	}
+	if err != nil {
+		log.Printf("Error loading balance watch for %s: %v", current.ScopeKey, err)
+		return
+	}
+
+	if previous.HealthStatus != current.HealthStatus {
+		queueWebhookEvent(models.WebhookEventHealthStatusChanged, subscribedTo(webhooks, models.WebhookEventHealthStatusChanged), HealthStatusChangedEvent{
+			HouseholdID:      scope.HouseholdID,
+			PreviousStatus:   previous.HealthStatus,
+			Status:           current.HealthStatus,
+			HealthPercentage: balance.HealthPercentage,
+			CurrentBalance:   balance.CurrentBalance,
+		})
+	}
+	if previous.YellowAlertDay != current.YellowAlertDay || previous.RedAlertDay != current.RedAlertDay {
+		queueWebhookEvent(models.WebhookEventAlertDayChanged, subscribedTo(webhooks, models.WebhookEventAlertDayChanged), AlertDayChangedEvent{
+			HouseholdID:            scope.HouseholdID,
+			PreviousYellowAlertDay: previous.YellowAlertDay,
+			YellowAlertDay:         current.YellowAlertDay,
+			PreviousRedAlertDay:    previous.RedAlertDay,
+			RedAlertDay:            current.RedAlertDay,
+		})
+	}
+
+	current.ID = previous.ID
+	if err := database.DB.Save(&current).Error; err != nil {
+		log.Printf("Error saving balance watch for %s: %v", current.ScopeKey, err)
+	}
+}
+
+// checkAllBalanceEvents verifica o saldo de todos os escopos com webhooks de eventos de saldo.
+// Cobre as mudanças que não passam por despesas variáveis (renda, despesas fixas e a passagem dos dias).
+func checkAllBalanceEvents() {
+	var webhooks []models.Webhook
+	if err := database.DB.Where("events LIKE ?", "%balance.%").Find(&webhooks).Error; err != nil {
+		log.Printf("Error loading balance webhooks: %v", err)
+		return
+	}
+	scopes := make(map[string]ownerScope)
+	for _, webhook := range webhooks {
+		scope := ownerScope{UserID: webhook.UserID, HouseholdID: webhook.HouseholdID}
+		scopes[balanceScopeKey(scope)] = scope
+	}
+	for _, scope := range scopes {
+		checkBalanceEvents(scope)
+	}
+}
+
+// webhookRetryDelay retorna a espera antes da próxima tentativa, dobrando a cada falha.
+func webhookRetryDelay(attempts int) time.Duration {
+	return webhookRetryBase << (attempts - 1)
+}
+
+// dispatchDueWebhooks envia as entregas pendentes cuja próxima tentativa já venceu. As entregas são
+// reservadas com SKIP LOCKED e adiadas por webhookLease, para que várias instâncias possam enviar.
+func dispatchDueWebhooks() {
+	var due []models.WebhookDelivery
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryPending, now).
+			Order("next_attempt_at").Limit(webhookDispatchBatch).Find(&due).Error
+		if err != nil || len(due) == 0 {
+			return err
+		}
+		ids := make([]uint, 0, len(due))
+		for _, delivery := range due {
+			ids = append(ids, delivery.ID)
+		}
+		return tx.Model(&models.WebhookDelivery{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(webhookLease)).Error
+	})
+	if err != nil {
+		log.Printf("Error loading due webhook deliveries: %v", err)
+		return
+	}
+	for _, delivery := range due {
+		deliverWebhook(delivery)
+	}
+}
+
+// deliverWebhook faz uma tentativa de entrega e registra o resultado: sucesso com resposta 2xx;
+// caso contrário, nova tentativa com espera exponencial até webhookMaxAttempts.
+func deliverWebhook(delivery models.WebhookDelivery) {
+	var webhook models.Webhook
+	if err := database.DB.First(&webhook, delivery.WebhookID).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("Error loading webhook %d: %v", delivery.WebhookID, err)
+			return // Nova tentativa quando a reserva expirar
+		}
+		delivery.Status, delivery.LastError = models.WebhookDeliveryFailed, "Webhook was deleted"
+		database.DB.Save(&delivery)
+		return
+	}
+
+	delivery.Attempts++
+	delivery.LastStatusCode, delivery.LastError = 0, ""
+	now := time.Now()
+	request, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err == nil {
+		timestamp := now.Unix()
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("User-Agent", "personal-finance-app-webhooks/1.0")
+		request.Header.Set(WebhookEventHeader, delivery.Event)
+		request.Header.Set(WebhookDeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+		request.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+		request.Header.Set(WebhookSignatureHeader, signWebhookPayload(webhook.Secret, timestamp, delivery.Payload))
+
+		var response *http.Response
+		response, err = webhookClient.Do(request)
+		if err == nil {
+			io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+			response.Body.Close()
+			delivery.LastStatusCode = response.StatusCode
+			if response.StatusCode < 200 || response.StatusCode > 299 {
+				err = errors.New("Unexpected response status " + response.Status)
+			}
+		}
+	}
+
+	switch {
+	case err == nil:
+		delivery.Status, delivery.DeliveredAt = models.WebhookDeliverySucceeded, &now
+	case delivery.Attempts >= webhookMaxAttempts:
+		delivery.Status, delivery.LastError = models.WebhookDeliveryFailed, err.Error()
+	default:
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = now.Add(webhookRetryDelay(delivery.Attempts))
+	}
+	if err := database.DB.Save(&delivery).Error; err != nil {
+		log.Printf("Error saving webhook delivery %d: %v", delivery.ID, err)
+	}
+}
+
+// StartWebhookDispatcher inicia, em segundo plano, o envio das entregas de webhooks pendentes e a
+// verificação periódica dos eventos de saldo.
+func StartWebhookDispatcher() {
+	go func() {
+		for {
+			dispatchDueWebhooks()
+			time.Sleep(webhookDispatchInterval)
+		}
+	}()
+	go func() {
+		for {
+			checkAllBalanceEvents()
+			time.Sleep(balanceWatchInterval)
+		}
+	}()
+}
@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"personal-finance-app/backend/apierrors"
+	"personal-finance-app/backend/database"
+	"personal-finance-app/backend/models"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const maxWebhookDeliveries = 100 // Entregas exibidas no histórico de um webhook (as mais recentes)
+
+// CreateWebhookPayload define o corpo de POST /webhooks
+type CreateWebhookPayload struct {
+	URL         string   `json:"url" binding:"required,max=2048"`
+	Events      []string `json:"events" binding:"required,min=1,dive,oneof=expense.created expense.deleted balance.health_status_changed balance.alert_day_changed"`
+	HouseholdID *uint    `json:"householdId"` // Opcional: recebe apenas os eventos do domicílio
+}
+
+// WebhookResponse é a representação JSON de um webhook. Secret só é enviado no cadastro.
+type WebhookResponse struct {
+	ID          uint      `json:"id"`
+	HouseholdID *uint     `json:"householdId"`
+	URL         string    `json:"url"`
+	Events      []string  `json:"events"`
+	Secret      string    `json:"secret,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// WebhookDeliveryResponse é a representação JSON de uma entrega no histórico de um webhook
+type WebhookDeliveryResponse struct {
+	ID             uint            `json:"id"`
+	WebhookID      uint            `json:"webhookId"`
+	EventID        string          `json:"eventId"`
+	Event          string          `json:"event"`
+	Status         string          `json:"status"` // "pending", "succeeded" ou "failed"
+	Attempts       int             `json:"attempts"`
+	LastStatusCode int             `json:"lastStatusCode,omitempty"`
+	LastError      string          `json:"lastError,omitempty"`
+	NextAttemptAt  *time.Time      `json:"nextAttemptAt,omitempty"` // Somente entregas pendentes
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	Payload        json.RawMessage `json:"payload"`
+}
+
+func toWebhookResponse(webhook models.Webhook) WebhookResponse {
+	return WebhookResponse{
+		ID:          webhook.ID,
+		HouseholdID: webhook.HouseholdID,
+		URL:         webhook.URL,
+		Events:      webhook.EventList(),
+		CreatedAt:   webhook.CreatedAt,
+	}
+}
+
+func toWebhookDeliveryResponse(delivery models.WebhookDelivery) WebhookDeliveryResponse {
+	response := WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		WebhookID:      delivery.WebhookID,
+		EventID:        delivery.EventID,
+		Event:          delivery.Event,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		DeliveredAt:    delivery.DeliveredAt,
+		CreatedAt:      delivery.CreatedAt,
+		Payload:        delivery.Payload,
+	}
+	if delivery.Status == models.WebhookDeliveryPending {
+		response.NextAttemptAt = &delivery.NextAttemptAt
+	}
+	return response
+}
+
+// validWebhookURL aceita apenas URLs absolutas http ou https. O destino é verificado a cada
+// entrega: endereços internos só são aceitos com WEBHOOK_ALLOW_PRIVATE (ver webhookDialControl).
+func validWebhookURL(raw string) bool {
+	parsed, err := url.Parse(raw)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+// loadOwnWebhook busca um webhook do usuário pelo parâmetro :id.
+// Em caso de falha, já responde à requisição e retorna false.
+func loadOwnWebhook(c *gin.Context, userID uint) (models.Webhook, bool) {
+	var webhook models.Webhook
+	webhookID, ok := parseIDParam(c, "id")
+	if !ok {
+		return webhook, false
+	}
+	err := database.DB.Where("id = ? AND user_id = ?", webhookID, userID).First(&webhook).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		apierrors.Respond(c, http.StatusNotFound, apierrors.WebhookNotFound)
+		return webhook, false
+	}
+	if err != nil {
+		log.Printf("Error fetching webhook %d: %v", webhookID, err)
+		apierrors.RespondInternal(c)
+		return webhook, false
+	}
+	return webhook, true
+}
+
+// CreateWebhookHandler cadastra um webhook do usuário. A resposta traz o segredo usado nas
+// assinaturas (cabeçalho X-Webhook-Signature), que não é exibido novamente.
+func CreateWebhookHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var payload CreateWebhookPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		apierrors.RespondInvalid(c, err)
+		return
+	}
+	if !validWebhookURL(payload.URL) {
+		apierrors.Respond(c, http.StatusBadRequest, apierrors.InvalidWebhookURL)
+		return
+	}
+	if _, ok := resolveScope(c, userID, payload.HouseholdID, models.HouseholdRoleViewer); !ok {
+		return
+	}
+
+	// Eventos sem repetição, na ordem informada
+	events := make([]string, 0, len(payload.Events))
+	seen := make(map[string]bool, len(payload.Events))
+	for _, event := range payload.Events {
+		if !seen[event] {
+			seen[event] = true
+			events = append(events, event)
+		}
+	}
+
+	webhook := models.Webhook{
+		UserID:      userID,
+		HouseholdID: payload.HouseholdID,
+		URL:         payload.URL,
+		Secret:      "whsec_" + randomHex(32),
+		Events:      strings.Join(events, ","),
+	}
+	if err := database.DB.Create(&webhook).Error; err != nil {
+		log.Printf("Error creating webhook for user %d: %v", userID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+
+	response := toWebhookResponse(webhook)
+	response.Secret = webhook.Secret
+	c.JSON(http.StatusCreated, response)
+}
+
+// ListWebhooksHandler lista os webhooks do usuário
+func ListWebhooksHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var webhooks []models.Webhook
+	if err := database.DB.Where("user_id = ?", userID).Order("id").Find(&webhooks).Error; err != nil {
+		log.Printf("Error listing webhooks for user %d: %v", userID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+	response := make([]WebhookResponse, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		response = append(response, toWebhookResponse(webhook))
+	}
+	c.JSON(http.StatusOK, gin.H{"webhooks": response})
+}
+
+// GetWebhookHandler retorna um webhook do usuário
+func GetWebhookHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	webhook, ok := loadOwnWebhook(c, userID)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, toWebhookResponse(webhook))
+}
+
+// DeleteWebhookHandler remove um webhook do usuário e seu histórico de entregas
+func DeleteWebhookHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	webhook, ok := loadOwnWebhook(c, userID)
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", webhook.ID).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&webhook).Error
+	})
+	if err != nil {
+		log.Printf("Error deleting webhook %d: %v", webhook.ID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+// ListWebhookDeliveriesHandler retorna o histórico de entregas de um webhook, das mais recentes
+// para as mais antigas
+func ListWebhookDeliveriesHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	webhook, ok := loadOwnWebhook(c, userID)
+	if !ok {
+		return
+	}
+
+	var deliveries []models.WebhookDelivery
+	err := database.DB.Where("webhook_id = ?", webhook.ID).Order("id DESC").Limit(maxWebhookDeliveries).Find(&deliveries).Error
+	if err != nil {
+		log.Printf("Error listing deliveries of webhook %d: %v", webhook.ID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+	response := make([]WebhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		response = append(response, toWebhookDeliveryResponse(delivery))
+	}
+	c.JSON(http.StatusOK, gin.H{"deliveries": response})
+}
+
+// RedeliverWebhookHandler agenda um novo envio de uma entrega (ex: depois de corrigir o destino).
+// O reenvio é uma nova entrega, com o mesmo evento e o mesmo corpo, enviada imediatamente.
+func RedeliverWebhookHandler(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	webhook, ok := loadOwnWebhook(c, userID)
+	if !ok {
+		return
+	}
+	deliveryID, ok := parseIDParam(c, "deliveryId")
+	if !ok {
+		return
+	}
+
+	var original models.WebhookDelivery
+	err := database.DB.Where("id = ? AND webhook_id = ?", deliveryID, webhook.ID).First(&original).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		apierrors.Respond(c, http.StatusNotFound, apierrors.DeliveryNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching webhook delivery %d: %v", deliveryID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+
+	redelivery := models.WebhookDelivery{
+		WebhookID:     webhook.ID,
+		EventID:       original.EventID,
+		Event:         original.Event,
+		Payload:       original.Payload,
+		Status:        models.WebhookDeliveryPending,
+		NextAttemptAt: time.Now(),
+	}
+	if err := database.DB.Create(&redelivery).Error; err != nil {
+		log.Printf("Error redelivering webhook delivery %d: %v", deliveryID, err)
+		apierrors.RespondInternal(c)
+		return
+	}
+	c.JSON(http.StatusAccepted, toWebhookDeliveryResponse(redelivery))
+}
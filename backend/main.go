@@ -5,9 +5,14 @@ import (
 	"log"
 	// "net/http" // Gin vai cuidar disso
 	"os"
+	"personal-finance-app/backend/apierrors"
 	"personal-finance-app/backend/database"
+	"personal-finance-app/backend/grpcserver"
 	"personal-finance-app/backend/handlers"
 	"personal-finance-app/backend/middleware" // Importa o pacote middleware
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -35,49 +40,222 @@ func main() {
 	setEnvIfNotExists("DB_NAME", "personalfinancedb")
 	setEnvIfNotExists("DB_PORT", "5432")
 
-
 	// Conectar ao banco de dados
 	database.ConnectDB()
 
+	// Remover definitivamente as despesas que estão na lixeira há mais de 30 dias
+	handlers.StartTrashPurger()
+
+	// Entregar os eventos dos webhooks (com novas tentativas) e observar mudanças no saldo
+	handlers.StartWebhookDispatcher()
+
+	// Atualizar os streams de saldo e de novas despesas abertos com as alterações feitas por
+	// qualquer instância
+	handlers.StartBalanceNotifications()
+	handlers.StartExpenseNotifications()
+
 	// Configurar o router Gin
 	router := gin.Default()
 
+	// Erros no corpo padrão (apierrors): ID da requisição (X-Request-ID) em todas as respostas,
+	// detalhes de validação com os nomes JSON dos campos e 404 também para rotas inexistentes
+	router.Use(middleware.RequestIDMiddleware())
+	apierrors.UseJSONFieldNames()
+	router.NoRoute(func(c *gin.Context) {
+		apierrors.Respond(c, http.StatusNotFound, apierrors.RouteNotFound)
+	})
+
+	// Validar as requisições contra a especificação OpenAPI (GET /openapi.json), nas rotas protegidas
+	// depois da autenticação: sem token, a resposta é 401 mesmo com o corpo inválido. Com
+	// OPENAPI_CONTRACT_MODE=true (testes de contrato), as respostas também são validadas.
+	contractMode, _ := strconv.ParseBool(os.Getenv("OPENAPI_CONTRACT_MODE"))
+	openAPIValidation := middleware.OpenAPIMiddleware(handlers.OpenAPISpec(), contractMode)
+
 	// Rota de exemplo
-	router.GET("/", helloHandler)
+	router.GET("/", openAPIValidation, helloHandler)
+
+	// Especificação OpenAPI da API
+	router.GET("/openapi.json", openAPIValidation, handlers.OpenAPIHandler)
 
+	// Rotas da API, com prefixo de versão. A v2 usa nomes de campos em inglês e camelCase em todas
+	// as rotas; as rotas sem prefixo continuam respondendo no formato da v1, mas estão obsoletas
+	// (cabeçalhos Deprecation e Sunset, com Link para a rota equivalente na /v1).
+	registerAPIRoutes(router.Group("/v1", middleware.APIVersionMiddleware(handlers.APIVersionV1)), openAPIValidation)
+	registerAPIRoutes(router.Group("/v2", middleware.APIVersionMiddleware(handlers.APIVersionV2)), openAPIValidation)
+	legacySunset, err := time.Parse("2006-01-02", os.Getenv("API_LEGACY_SUNSET"))
+	if err != nil {
+		legacySunset = legacyRoutesDeprecatedAt.AddDate(0, 6, 0) // Padrão: seis meses após a criação da /v1
+	}
+	registerAPIRoutes(router.Group("",
+		middleware.APIVersionMiddleware(handlers.APIVersionV1),
+		middleware.DeprecationMiddleware(legacyRoutesDeprecatedAt, legacySunset, "/v1"),
+	), openAPIValidation)
+
+	// API GraphQL: saldo, projeção e despesas em uma única requisição (protegida por JWT)
+	router.POST("/graphql", middleware.AuthMiddleware(), openAPIValidation, middleware.IdempotencyMiddleware(), handlers.GraphQLHandler)
+
+	// Toda rota registrada deve estar descrita na especificação OpenAPI (handlers/openapi_spec.go)
+	if problems := middleware.OpenAPIRouteMismatches(handlers.OpenAPISpec(), router.Routes()); len(problems) > 0 {
+		if contractMode {
+			log.Fatalf("OpenAPI specification is out of sync with the routes:\n%s", strings.Join(problems, "\n"))
+		}
+		for _, problem := range problems {
+			log.Printf("Warning: %s", problem)
+		}
+	}
+
+	// Iniciar o servidor gRPC para serviços internos (mesmas regras e mesma autenticação JWT)
+	grpcPort := os.Getenv("GRPC_PORT")
+	if grpcPort == "" {
+		grpcPort = "9090" // Porta gRPC padrão se não especificada
+	}
+	go func() {
+		log.Fatal(grpcserver.Serve(grpcPort))
+	}()
+
+	// Iniciar o servidor
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080" // Porta padrão se não especificada
+	}
+	fmt.Printf("Server started at port %s\n", port)
+	log.Fatal(router.Run(":" + port))
+}
+
+// legacyRoutesDeprecatedAt é a data em que as rotas sem prefixo de versão se tornaram obsoletas.
+var legacyRoutesDeprecatedAt = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
+// registerAPIRoutes registra as rotas da API no grupo de uma versão (ex: /v1). Os handlers são os
+// mesmos em todas as versões; o formato dos corpos é escolhido pela versão no contexto. validate
+// (a validação OpenAPI) roda depois do AuthMiddleware nas rotas protegidas.
+func registerAPIRoutes(api *gin.RouterGroup, validate gin.HandlerFunc) {
 	// Rotas de Autenticação
-	authRoutes := router.Group("/auth")
+	authRoutes := api.Group("/auth")
+	authRoutes.Use(validate)
 	{
 		authRoutes.POST("/request-code", handlers.RequestCodeHandler)
 		authRoutes.POST("/verify-code", handlers.VerifyCodeHandler)
 	}
 
 	// Rotas de Onboarding (protegidas por JWT)
-	onboardingRoutes := router.Group("/onboarding")
-	onboardingRoutes.Use(middleware.AuthMiddleware()) // Aplica o middleware de autenticação
+	onboardingRoutes := api.Group("/onboarding")
+	onboardingRoutes.Use(middleware.AuthMiddleware())        // Aplica o middleware de autenticação
+	onboardingRoutes.Use(validate)                           // Valida a requisição contra a especificação OpenAPI
+	onboardingRoutes.Use(middleware.IdempotencyMiddleware()) // Suporte ao cabeçalho Idempotency-Key nas rotas de escrita
 	{
+		onboardingRoutes.GET("/status", handlers.GetOnboardingStatusHandler)
+		onboardingRoutes.POST("/profile", handlers.SaveProfileHandler)
 		onboardingRoutes.POST("/income", handlers.SaveIncomeHandler)
 		onboardingRoutes.POST("/fixed-expenses", handlers.SaveFixedExpensesHandler)
+		onboardingRoutes.GET("/fixed-expense-templates", handlers.GetFixedExpenseTemplatesHandler)
+		onboardingRoutes.POST("/categories", handlers.SaveCategoriesHandler)
+		onboardingRoutes.POST("/budget", handlers.SaveFirstBudgetHandler)
+		onboardingRoutes.POST("/skip", handlers.SkipOnboardingStepHandler)
+		onboardingRoutes.POST("/back", handlers.OnboardingBackHandler)
+	}
+
+	// Rotas de Despesas Fixas individuais (protegidas por JWT)
+	// O onboarding continua usando POST /onboarding/fixed-expenses para o cadastro inicial em lote.
+	fixedExpenseRoutes := api.Group("/fixed-expenses")
+	fixedExpenseRoutes.Use(middleware.AuthMiddleware(), validate, middleware.IdempotencyMiddleware())
+	{
+		fixedExpenseRoutes.GET("", handlers.ListFixedExpensesHandler)
+		fixedExpenseRoutes.POST("", handlers.PostFixedExpenseHandler)
+		fixedExpenseRoutes.GET("/:id", handlers.GetFixedExpenseHandler)
+		fixedExpenseRoutes.PATCH("/:id", handlers.PatchFixedExpenseHandler)
+		fixedExpenseRoutes.DELETE("/:id", handlers.DeleteFixedExpenseHandler)
+	}
+
+	// Rotas de Metas de Economia (protegidas por JWT)
+	goalRoutes := api.Group("/goals")
+	goalRoutes.Use(middleware.AuthMiddleware(), validate, middleware.IdempotencyMiddleware())
+	{
+		goalRoutes.GET("", handlers.ListGoalsHandler)
+		goalRoutes.POST("", handlers.PostGoalHandler)
+		goalRoutes.GET("/:id", handlers.GetGoalHandler)
+		goalRoutes.DELETE("/:id", handlers.DeleteGoalHandler)
+		goalRoutes.POST("/:id/contributions", handlers.PostGoalContributionHandler)
+		goalRoutes.POST("/:id/withdrawals", handlers.PostGoalWithdrawalHandler)
+	}
+
+	// Rotas de Transações Recorrentes (protegidas por JWT)
+	recurringRoutes := api.Group("/recurring-transactions")
+	recurringRoutes.Use(middleware.AuthMiddleware(), validate, middleware.IdempotencyMiddleware())
+	{
+		recurringRoutes.GET("", handlers.ListRecurringTransactionsHandler)
+		recurringRoutes.POST("", handlers.PostRecurringTransactionHandler)
+		recurringRoutes.GET("/:id", handlers.GetRecurringTransactionHandler)
+		recurringRoutes.DELETE("/:id", handlers.DeleteRecurringTransactionHandler)
 	}
 
 	// Rotas de Despesas Variáveis (protegidas por JWT)
-	expenseRoutes := router.Group("/expenses")
-	expenseRoutes.Use(middleware.AuthMiddleware())
+	expenseRoutes := api.Group("/expenses")
+	expenseRoutes.Use(middleware.AuthMiddleware(), validate, middleware.IdempotencyMiddleware())
 	{
-		expenseRoutes.POST("", handlers.PostExpenseHandler)       // POST /expenses
-		expenseRoutes.DELETE("/:id", handlers.DeleteExpenseHandler) // DELETE /expenses/{id}
+		expenseRoutes.GET("", handlers.ListExpensesHandler)                // GET /expenses?from=&to=&category=&q=&sort=&cursor=
+		expenseRoutes.POST("", handlers.PostExpenseHandler)                // POST /expenses
+		expenseRoutes.POST("/batch", handlers.PostExpensesBatchHandler)    // POST /expenses/batch
+		expenseRoutes.GET("/trash", handlers.ListTrashHandler)             // GET /expenses/trash
+		expenseRoutes.DELETE("/trash", handlers.EmptyTrashHandler)         // DELETE /expenses/trash (esvazia a lixeira)
+		expenseRoutes.GET("/:id", handlers.GetExpenseHandler)              // GET /expenses/{id}
+		expenseRoutes.PUT("/:id", handlers.PutExpenseHandler)              // PUT /expenses/{id} (If-Match: "<versão>")
+		expenseRoutes.PATCH("/:id", handlers.PatchExpenseHandler)          // PATCH /expenses/{id} (If-Match: "<versão>")
+		expenseRoutes.DELETE("/:id", handlers.DeleteExpenseHandler)        // DELETE /expenses/{id} (envia para a lixeira)
+		expenseRoutes.POST("/:id/restore", handlers.RestoreExpenseHandler) // POST /expenses/{id}/restore
 	}
 
-	// Rota de Saldo e Projeção (protegida por JWT)
-	router.GET("/balance", middleware.AuthMiddleware(), handlers.GetBalanceHandler)
+	// Rotas de Domicílios compartilhados (protegidas por JWT)
+	householdRoutes := api.Group("/households")
+	householdRoutes.Use(middleware.AuthMiddleware(), validate, middleware.IdempotencyMiddleware())
+	{
+		householdRoutes.POST("", handlers.CreateHouseholdHandler)
+		householdRoutes.GET("", handlers.ListHouseholdsHandler)
+		householdRoutes.GET("/:id", handlers.GetHouseholdHandler)
+		householdRoutes.DELETE("/:id", handlers.DeleteHouseholdHandler)
+		householdRoutes.POST("/:id/invitations", handlers.InviteHouseholdMemberHandler)
+		householdRoutes.PATCH("/:id/members/:userId", handlers.UpdateHouseholdMemberHandler)
+		householdRoutes.DELETE("/:id/members/:userId", handlers.RemoveHouseholdMemberHandler)
+	}
 
-	// Iniciar o servidor
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080" // Porta padrão se não especificada
+	// Rotas de Convites recebidos pelo usuário (protegidas por JWT)
+	invitationRoutes := api.Group("/invitations")
+	invitationRoutes.Use(middleware.AuthMiddleware(), validate, middleware.IdempotencyMiddleware())
+	{
+		invitationRoutes.GET("", handlers.ListInvitationsHandler)
+		invitationRoutes.POST("/:id/accept", handlers.AcceptInvitationHandler)
+		invitationRoutes.DELETE("/:id", handlers.DeclineInvitationHandler)
 	}
-	fmt.Printf("Server started at port %s\n", port)
-	log.Fatal(router.Run(":" + port))
+
+	// Rotas de Sincronização para clientes offline (protegidas por JWT)
+	syncRoutes := api.Group("/sync")
+	syncRoutes.Use(middleware.AuthMiddleware(), validate, middleware.IdempotencyMiddleware())
+	{
+		syncRoutes.GET("", handlers.GetSyncHandler)   // GET /sync?since=<cursor>
+		syncRoutes.POST("", handlers.PostSyncHandler) // POST /sync (alterações feitas offline)
+	}
+
+	// Rotas de Webhooks do usuário (protegidas por JWT)
+	webhookRoutes := api.Group("/webhooks")
+	webhookRoutes.Use(middleware.AuthMiddleware(), validate, middleware.IdempotencyMiddleware())
+	{
+		webhookRoutes.POST("", handlers.CreateWebhookHandler)
+		webhookRoutes.GET("", handlers.ListWebhooksHandler)
+		webhookRoutes.GET("/:id", handlers.GetWebhookHandler)
+		webhookRoutes.DELETE("/:id", handlers.DeleteWebhookHandler)
+		webhookRoutes.GET("/:id/deliveries", handlers.ListWebhookDeliveriesHandler)
+		webhookRoutes.POST("/:id/deliveries/:deliveryId/redeliver", handlers.RedeliverWebhookHandler)
+	}
+
+	// Rota de Saldo e Projeção (protegida por JWT)
+	api.GET("/balance", middleware.AuthMiddleware(), validate, handlers.GetBalanceHandler)
+	api.GET("/balance/stream", middleware.AuthMiddleware(), validate, handlers.StreamBalanceHandler) // Server-Sent Events
+	api.GET("/balance/history", middleware.AuthMiddleware(), validate, handlers.GetBalanceHistoryHandler)
+	api.GET("/balance/settings", middleware.AuthMiddleware(), validate, handlers.GetBalanceSettingsHandler)
+	api.PUT("/balance/settings", middleware.AuthMiddleware(), validate, middleware.IdempotencyMiddleware(), handlers.PutBalanceSettingsHandler)
+
+	// Fluxo de caixa diário e previsão dos próximos meses (protegidos por JWT)
+	api.GET("/cashflow", middleware.AuthMiddleware(), validate, handlers.GetCashFlowHandler)
+	api.GET("/forecast", middleware.AuthMiddleware(), validate, handlers.GetForecastHandler)
 }
 
 // setEnvIfNotExists define uma variável de ambiente se ela ainda não estiver definida.
@@ -4,6 +4,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"personal-finance-app/backend/apierrors"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -12,65 +13,76 @@ import (
 
 var jwtKey = []byte(os.Getenv("JWT_SECRET"))
 
-// AuthMiddleware é um middleware para verificar o token JWT.
-func AuthMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Inicializa jwtKey se estiver vazia (fallback para desenvolvimento, como em auth_handlers)
-		if jwtKey == nil || len(jwtKey) == 0 {
-			secret := os.Getenv("JWT_SECRET")
-			if secret == "" {
-				log.Println("CRITICAL (AuthMiddleware): JWT_SECRET is not set. Using a default insecure key. THIS IS NOT SAFE FOR PRODUCTION.")
-				jwtKey = []byte("default_insecure_secret_key_for_testing_only_12345")
-			} else {
-				jwtKey = []byte(secret)
-			}
+// signingKey retorna a chave dos tokens JWT.
+func signingKey() []byte {
+	// Inicializa jwtKey se estiver vazia (fallback para desenvolvimento, como em auth_handlers)
+	if jwtKey == nil || len(jwtKey) == 0 {
+		secret := os.Getenv("JWT_SECRET")
+		if secret == "" {
+			log.Println("CRITICAL (AuthMiddleware): JWT_SECRET is not set. Using a default insecure key. THIS IS NOT SAFE FOR PRODUCTION.")
+			jwtKey = []byte("default_insecure_secret_key_for_testing_only_12345")
+		} else {
+			jwtKey = []byte(secret)
 		}
+	}
+	return jwtKey
+}
 
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
-			return
-		}
+// BearerToken extrai o token de um cabeçalho Authorization no formato "Bearer <token>".
+// O erro retornado é um *apierrors.Error (401) que pode ser exibido ao cliente.
+func BearerToken(authHeader string) (string, error) {
+	if authHeader == "" {
+		return "", apierrors.New(http.StatusUnauthorized, apierrors.Unauthenticated)
+	}
+	// O token geralmente vem no formato "Bearer <token>"
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+		return "", apierrors.New(http.StatusUnauthorized, apierrors.InvalidAuthHeader)
+	}
+	return parts[1], nil
+}
 
-		// O token geralmente vem no formato "Bearer <token>"
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header format must be Bearer {token}"})
-			return
+// ParseToken valida um token JWT e retorna suas claims; o ID do usuário está em Subject.
+// Usada pelo AuthMiddleware e pela API gRPC. O erro retornado é um *apierrors.Error (401) que pode
+// ser exibido ao cliente; o motivo detalhado da biblioteca JWT não é exposto.
+func ParseToken(tokenString string) (*jwt.RegisteredClaims, error) {
+	claims := &jwt.RegisteredClaims{}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		// Verifica o método de assinatura
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.NewValidationError("unexpected signing method", jwt.ValidationErrorSignatureInvalid)
 		}
-		tokenString := parts[1]
+		return signingKey(), nil
+	})
 
-		claims := &jwt.RegisteredClaims{}
+	if err != nil {
+		validationErr, ok := err.(*jwt.ValidationError)
+		if ok && validationErr.Errors&(jwt.ValidationErrorExpired|jwt.ValidationErrorNotValidYet) != 0 {
+			return nil, apierrors.New(http.StatusUnauthorized, apierrors.TokenExpired)
+		}
+		// Assinatura inválida, token malformado ou outro motivo
+		return nil, apierrors.New(http.StatusUnauthorized, apierrors.InvalidToken)
+	}
 
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			// Verifica o método de assinatura
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.NewValidationError("unexpected signing method", jwt.ValidationErrorSignatureInvalid)
-			}
-			return jwtKey, nil
-		})
+	if !token.Valid {
+		return nil, apierrors.New(http.StatusUnauthorized, apierrors.InvalidToken)
+	}
+	return claims, nil
+}
 
+// AuthMiddleware é um middleware para verificar o token JWT.
+func AuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString, err := BearerToken(c.GetHeader("Authorization"))
 		if err != nil {
-			if err == jwt.ErrSignatureInvalid {
-				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token signature"})
-				return
-			}
-			validationErr, ok := err.(*jwt.ValidationError)
-			if ok {
-				if validationErr.Errors&jwt.ValidationErrorMalformed != 0 {
-					c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Malformed token"})
-					return
-				} else if validationErr.Errors&(jwt.ValidationErrorExpired|jwt.ValidationErrorNotValidYet) != 0 {
-					c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token is expired or not valid yet"})
-					return
-				}
-			}
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token: " + err.Error()})
+			apierrors.AbortWithError(c, err)
 			return
 		}
 
-		if !token.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		claims, err := ParseToken(tokenString)
+		if err != nil {
+			apierrors.AbortWithError(c, err)
 			return
 		}
 
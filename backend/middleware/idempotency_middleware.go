@@ -0,0 +1,192 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"personal-finance-app/backend/apierrors"
+	"personal-finance-app/backend/database"
+	"personal-finance-app/backend/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// IdempotencyHeader é o cabeçalho com a chave escolhida pelo cliente para a requisição
+	IdempotencyHeader = "Idempotency-Key"
+
+	idempotencyTTL       = 24 * time.Hour // Tempo pelo qual a resposta fica guardada
+	idempotencyLockTTL   = time.Minute    // Após este tempo, uma requisição "em processamento" é considerada abandonada
+	maxIdempotencyKeyLen = 255
+)
+
+// responseRecorder copia o corpo da resposta enquanto ele é enviado ao cliente.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// isMutatingMethod indica se o método HTTP altera dados.
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// requestFingerprint identifica o conteúdo da requisição (método, caminho e corpo).
+func requestFingerprint(method, uri string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + uri + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// IdempotencyMiddleware torna idempotentes as rotas de escrita quando o cliente envia o
+// cabeçalho Idempotency-Key. A resposta da primeira requisição é guardada por usuário durante
+// 24 horas e repetida nas novas tentativas com a mesma chave. Reutilizar a chave com outra
+// requisição resulta em 422. Deve ser usado depois do AuthMiddleware.
+func IdempotencyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyHeader)
+		if key == "" || !isMutatingMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLen {
+			apierrors.Abort(c, http.StatusBadRequest, apierrors.IdempotencyKeyTooLong, maxIdempotencyKeyLen)
+			return
+		}
+
+		userID, err := strconv.ParseUint(c.GetString("userID"), 10, 32)
+		if err != nil {
+			apierrors.Abort(c, http.StatusUnauthorized, apierrors.Unauthenticated)
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			apierrors.Abort(c, http.StatusBadRequest, apierrors.InvalidRequestBody)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		fingerprint := requestFingerprint(c.Request.Method, c.Request.URL.RequestURI(), body)
+
+		record, acquired, err := acquireIdempotencyKey(uint(userID), key, fingerprint)
+		if err != nil {
+			log.Printf("Error checking idempotency key for user %d: %v", userID, err)
+			apierrors.Abort(c, http.StatusInternalServerError, apierrors.InternalError)
+			return
+		}
+
+		if !acquired {
+			switch {
+			case record.Fingerprint != fingerprint:
+				apierrors.Abort(c, http.StatusUnprocessableEntity, apierrors.IdempotencyKeyReused)
+			case record.StatusCode == 0:
+				apierrors.Abort(c, http.StatusConflict, apierrors.IdempotencyKeyInProgress)
+			default:
+				// Repete a resposta original
+				c.Header("Idempotent-Replayed", "true")
+				c.Data(record.StatusCode, record.ContentType, record.ResponseBody)
+				c.Abort()
+			}
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			// Erros do servidor não são guardados: o cliente pode tentar novamente com a mesma chave
+			if err := database.DB.Delete(record).Error; err != nil {
+				log.Printf("Error releasing idempotency key %d: %v", record.ID, err)
+			}
+			return
+		}
+		err = database.DB.Model(record).Updates(map[string]interface{}{
+			"status_code":   status,
+			"content_type":  recorder.Header().Get("Content-Type"),
+			"response_body": recorder.body.Bytes(),
+		}).Error
+		if err != nil {
+			log.Printf("Error storing idempotent response %d: %v", record.ID, err)
+		}
+	}
+}
+
+// acquireIdempotencyKey registra a chave como "em processamento". Se a chave já existir e ainda
+// for válida, retorna o registro existente com acquired=false. Chaves expiradas ou abandonadas
+// (em processamento há mais de idempotencyLockTTL) são reaproveitadas.
+func acquireIdempotencyKey(userID uint, key, fingerprint string) (record *models.IdempotencyKey, acquired bool, err error) {
+	now := time.Now()
+
+	// Limpeza preguiçosa das chaves expiradas do usuário
+	database.DB.Where("user_id = ? AND expires_at < ?", userID, now).Delete(&models.IdempotencyKey{})
+
+	record = &models.IdempotencyKey{
+		UserID:      userID,
+		Key:         key,
+		Fingerprint: fingerprint,
+		ExpiresAt:   now.Add(idempotencyTTL),
+	}
+	result := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if result.Error != nil {
+		return nil, false, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return record, true, nil
+	}
+
+	var existing models.IdempotencyKey
+	if err := database.DB.Where("user_id = ? AND key = ?", userID, key).First(&existing).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return acquireIdempotencyKey(userID, key, fingerprint) // Removida entre as duas consultas
+		}
+		return nil, false, err
+	}
+
+	abandoned := existing.StatusCode == 0 && existing.UpdatedAt.Before(now.Add(-idempotencyLockTTL))
+	if existing.ExpiresAt.Before(now) || abandoned {
+		takeover := database.DB.Model(&models.IdempotencyKey{}).
+			Where("id = ? AND updated_at = ?", existing.ID, existing.UpdatedAt).
+			Updates(map[string]interface{}{
+				"fingerprint":   fingerprint,
+				"status_code":   0,
+				"content_type":  "",
+				"response_body": nil,
+				"expires_at":    now.Add(idempotencyTTL),
+			})
+		if takeover.Error != nil {
+			return nil, false, takeover.Error
+		}
+		if takeover.RowsAffected == 1 {
+			existing.Fingerprint, existing.StatusCode = fingerprint, 0
+			return &existing, true, nil
+		}
+		// Outra requisição assumiu a chave primeiro
+		return acquireIdempotencyKey(userID, key, fingerprint)
+	}
+	return &existing, false, nil
+}
@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"net/http"
+	"personal-finance-app/backend/apierrors"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gin-gonic/gin"
+)
+
+// bufferedResponseWriter guarda o status e o corpo da resposta em vez de enviá-los, para que a
+// resposta possa ser validada (e substituída) antes de chegar ao cliente.
+type bufferedResponseWriter struct {
+	gin.ResponseWriter
+	status  int
+	written bool
+	body    bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) WriteHeader(status int) {
+	if !w.written {
+		w.status = status
+	}
+}
+
+func (w *bufferedResponseWriter) WriteHeaderNow() { w.written = true }
+
+func (w *bufferedResponseWriter) Write(data []byte) (int, error) {
+	w.written = true
+	return w.body.Write(data)
+}
+
+func (w *bufferedResponseWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedResponseWriter) Status() int   { return w.status }
+func (w *bufferedResponseWriter) Size() int     { return w.body.Len() }
+func (w *bufferedResponseWriter) Written() bool { return w.written }
+
+// openAPIRoutes indexa as operações da especificação por método e caminho no formato do Gin
+// (ex: "GET /expenses/:id").
+func openAPIRoutes(spec *openapi3.T) map[string]*routers.Route {
+	routes := make(map[string]*routers.Route)
+	for path, item := range spec.Paths.Map() {
+		ginPath := strings.NewReplacer("{", ":", "}", "").Replace(path)
+		for method, operation := range item.Operations() {
+			routes[method+" "+ginPath] = &routers.Route{Spec: spec, Path: path, PathItem: item, Method: method, Operation: operation}
+		}
+	}
+	return routes
+}
+
+// streamingOperation informa se a operação responde com Server-Sent Events. Essas respostas não
+// terminam e são enviadas aos poucos, por isso não passam pela validação de respostas.
+func streamingOperation(operation *openapi3.Operation) bool {
+	response := operation.Responses.Status(http.StatusOK)
+	return response != nil && response.Value != nil && response.Value.Content.Get("text/event-stream") != nil
+}
+
+// OpenAPIRouteMismatches compara as rotas registradas no Gin com as operações da especificação e
+// descreve as diferenças (rotas sem documentação e operações documentadas que não existem).
+func OpenAPIRouteMismatches(spec *openapi3.T, registered gin.RoutesInfo) []string {
+	documented := openAPIRoutes(spec)
+	var problems []string
+	for _, route := range registered {
+		key := route.Method + " " + route.Path
+		if _, known := documented[key]; !known {
+			problems = append(problems, key+" is not documented in the OpenAPI specification")
+		}
+		delete(documented, key)
+	}
+	for key := range documented {
+		problems = append(problems, key+" is documented in the OpenAPI specification but not registered")
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+// openAPIError converte um erro de validação da requisição no erro da API, com o motivo informado
+// pelo validador nos detalhes (ex: {"field": "value", "code": "schema", "message": "number must be more than 0"}).
+func openAPIError(err error) *apierrors.Error {
+	var requestErr *openapi3filter.RequestError
+	if !errors.As(err, &requestErr) {
+		return &apierrors.Error{Status: http.StatusBadRequest, Code: apierrors.InvalidRequest,
+			Details: []apierrors.FieldError{{Code: "schema", Message: err.Error()}}}
+	}
+
+	apiErr := &apierrors.Error{Status: http.StatusBadRequest, Code: apierrors.InvalidRequest}
+	detail := apierrors.FieldError{Code: "schema", Message: requestErr.Reason}
+	if requestErr.Parameter != nil {
+		apiErr.Code, apiErr.Args = apierrors.InvalidParameter, []interface{}{requestErr.Parameter.Name}
+		detail.Field = requestErr.Parameter.Name
+	} else if requestErr.RequestBody != nil {
+		apiErr.Code = apierrors.InvalidRequestBody
+	}
+
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		detail.Message = schemaErr.Reason
+		if pointer := schemaErr.JSONPointer(); len(pointer) > 0 && requestErr.Parameter == nil {
+			detail.Field = strings.Join(pointer, ".")
+		}
+	} else if requestErr.Err != nil {
+		detail.Message = requestErr.Err.Error()
+	}
+	if detail.Message != "" {
+		apiErr.Details = []apierrors.FieldError{detail}
+	}
+	return apiErr
+}
+
+// OpenAPIMiddleware valida as requisições contra a operação da especificação OpenAPI que
+// corresponde à rota encontrada pelo Gin (parâmetros de caminho, query, cabeçalhos e corpo),
+// respondendo 400 quando não correspondem. Nas rotas protegidas, deve vir depois do AuthMiddleware:
+// uma requisição sem autenticação recebe 401, e não 400, mesmo com o corpo inválido.
+//
+// No modo de contrato (contractMode), usado em testes de contrato, as respostas também são
+// validadas: status não documentado ou corpo fora do schema é registrado no log e a resposta é
+// substituída por 500, para que o teste falhe. Streams (text/event-stream) não são validados.
+func OpenAPIMiddleware(spec *openapi3.T, contractMode bool) gin.HandlerFunc {
+	routes := openAPIRoutes(spec)
+	requestOptions := &openapi3filter.Options{
+		AuthenticationFunc:  openapi3filter.NoopAuthenticationFunc,
+		SkipSettingDefaults: true, // O corpo chega ao handler como foi enviado
+	}
+	responseOptions := &openapi3filter.Options{IncludeResponseStatus: true}
+	openapi3.SchemaErrorDetailsDisabled = true // Erros sem o schema completo (respostas e log mais curtos)
+
+	return func(c *gin.Context) {
+		route, known := routes[c.Request.Method+" "+c.FullPath()]
+		if !known {
+			c.Next()
+			return
+		}
+
+		pathParams := make(map[string]string, len(c.Params))
+		for _, param := range c.Params {
+			pathParams[param.Key] = param.Value
+		}
+		input := &openapi3filter.RequestValidationInput{Request: c.Request, PathParams: pathParams, Route: route, Options: requestOptions}
+		if err := openapi3filter.ValidateRequest(c.Request.Context(), input); err != nil {
+			apierrors.AbortWithError(c, openAPIError(err))
+			return
+		}
+		if !contractMode || streamingOperation(route.Operation) {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		buffered := &bufferedResponseWriter{ResponseWriter: original, status: http.StatusOK}
+		c.Writer = buffered
+		c.Next()
+		c.Writer = original
+
+		responseInput := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 buffered.status,
+			Header:                 original.Header(),
+			Options:                responseOptions,
+		}
+		responseInput.SetBodyBytes(buffered.body.Bytes())
+		if err := openapi3filter.ValidateResponse(c.Request.Context(), responseInput); err != nil {
+			log.Printf("Contract violation in %s %s (status %d): %v", c.Request.Method, c.FullPath(), buffered.status, err)
+			original.Header().Del("ETag")
+			apierrors.Respond(c, http.StatusInternalServerError, apierrors.ContractViolation) // O motivo fica apenas no log
+			return
+		}
+
+		original.WriteHeader(buffered.status)
+		if buffered.body.Len() > 0 {
+			original.Write(buffered.body.Bytes())
+		} else {
+			original.WriteHeaderNow()
+		}
+	}
+}
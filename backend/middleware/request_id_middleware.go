@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader é o cabeçalho com o ID da requisição, enviado nas respostas e aceito do cliente
+// (ou de um proxy) para correlacionar logs.
+const RequestIDHeader = "X-Request-ID"
+
+const maxRequestIDLen = 128
+
+// validRequestID aceita IDs enviados pelo cliente com até 128 caracteres alfanuméricos, "-", "_" ou ".".
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// RequestIDMiddleware identifica cada requisição: usa o X-Request-ID recebido, se válido, ou gera
+// um novo. O ID é devolvido no cabeçalho X-Request-ID, incluído nos corpos de erro (requestId) e
+// fica no contexto ("requestID").
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			random := make([]byte, 16)
+			rand.Read(random)
+			id = hex.EncodeToString(random)
+		}
+		c.Set("requestID", id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
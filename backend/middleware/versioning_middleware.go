@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIVersionMiddleware registra no contexto a versão da API das rotas do grupo ("apiVersion"),
+// usada pelos handlers para escolher o formato dos corpos.
+func APIVersionMiddleware(version string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("apiVersion", version)
+		c.Next()
+	}
+}
+
+// DeprecationMiddleware marca as rotas do grupo como obsoletas: envia os cabeçalhos Deprecation
+// (RFC 9745, data em que a rota se tornou obsoleta), Sunset (RFC 8594, data a partir da qual a rota
+// pode deixar de existir) e Link com a rota equivalente na versão que a substitui
+// (successorPrefix + caminho da requisição, ex: /v1/expenses).
+func DeprecationMiddleware(deprecatedAt, sunset time.Time, successorPrefix string) gin.HandlerFunc {
+	deprecation := "@" + strconv.FormatInt(deprecatedAt.Unix(), 10)
+	sunsetHeader := sunset.UTC().Format(http.TimeFormat)
+	return func(c *gin.Context) {
+		c.Header("Deprecation", deprecation)
+		c.Header("Sunset", sunsetHeader)
+		c.Header("Link", "<"+successorPrefix+c.Request.URL.Path+`>; rel="successor-version"`)
+		c.Next()
+	}
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// BalanceSettings guarda as regras da saúde financeira escolhidas pelo usuário: os limites do
+// verde e do amarelo, a base do percentual (renda ou meta de economia) e o modelo de previsão dos
+// gastos. Sem registro, valem as regras padrão (finance.DefaultRules) e a média diária do mês.
+type BalanceSettings struct {
+	ID              uint    `gorm:"primaryKey"`
+	UserID          uint    `gorm:"uniqueIndex;not null"`
+	GreenThreshold  float64 `gorm:"not null"`                         // Acima deste percentual: verde
+	YellowThreshold float64 `gorm:"not null"`                         // A partir deste percentual: amarelo; abaixo: vermelho
+	HealthBasis     string  `gorm:"not null;default:'income'"`        // "income" ou "savings_target"
+	SavingsTarget   float64 `gorm:"not null;default:0"`               // Quanto o usuário quer que sobre no mês
+	ForecastModel   string  `gorm:"not null;default:'month_average'"` // "month_average", "weighted_average" ou "weekday_seasonality"
+	TrimOutliers    bool    `gorm:"not null;default:false"`           // Desconsidera compras atípicas na previsão
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Category representa uma categoria de despesa personalizada do usuário
+type Category struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"uniqueIndex:idx_category_user_name;not null"`
+	Name      string `gorm:"uniqueIndex:idx_category_user_name;not null"`
+	Version   uint   `gorm:"not null;default:1"` // Incrementada a cada alteração (controle de concorrência otimista)
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Budget representa o limite mensal de gastos do usuário em uma categoria
+type Budget struct {
+	ID        uint    `gorm:"primaryKey"`
+	UserID    uint    `gorm:"uniqueIndex:idx_budget_user_category;not null"`
+	Category  string  `gorm:"uniqueIndex:idx_budget_user_category;not null"` // Nome da categoria
+	Amount    float64 `gorm:"not null"`                                      // Limite mensal
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
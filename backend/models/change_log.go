@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Entidades sincronizadas pelo feed de alterações
+const (
+	SyncEntityIncome          = "income"
+	SyncEntityFixedExpense    = "fixed_expense"
+	SyncEntityVariableExpense = "variable_expense"
+	SyncEntityCategory        = "category"
+)
+
+// Operações registradas no feed de alterações
+const (
+	ChangeOperationUpsert = "upsert" // Registro criado ou alterado
+	ChangeOperationDelete = "delete" // Registro removido (tombstone), inclusive quando vai para a lixeira
+)
+
+// ChangeLog é uma entrada do feed de alterações usado na sincronização dos clientes offline.
+// As entradas são gravadas por triggers do banco (ver database.setupChangeTracking), na mesma
+// transação da alteração, e nunca pela aplicação.
+type ChangeLog struct {
+	Seq         uint64    `gorm:"primaryKey;autoIncrement;index:idx_change_log_tx_seq,priority:2"`
+	TxID        int64     `gorm:"not null;index:idx_change_log_tx_seq,priority:1"` // txid_current() da transação que fez a alteração
+	UserID      uint      `gorm:"index;not null"`                                  // Usuário dono do registro alterado
+	HouseholdID *uint     `gorm:"index"`                                           // Domicílio dono do registro (nil para registros pessoais)
+	Entity      string    `gorm:"not null"`                                        // Uma das constantes SyncEntity*
+	EntityID    uint      `gorm:"not null"`
+	Operation   string    `gorm:"not null"` // "upsert" ou "delete"
+	CreatedAt   time.Time `gorm:"not null"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// ChangeEntityGoal identifica os aportes, retiradas e remoções de metas nas notificações de alteração
+// do saldo. Metas não fazem parte do feed de sincronização (SyncEntity*).
+const ChangeEntityGoal = "goal"
+
+// Goal representa uma meta de economia do usuário ou de um domicílio
+// (ex: "Reserva de emergência", R$ 20.000 até dezembro)
+type Goal struct {
+	ID           uint      `gorm:"primaryKey"`
+	UserID       uint      `gorm:"index;not null"` // Usuário que criou a meta
+	HouseholdID  *uint     `gorm:"index"`          // Domicílio dono da meta (nil para metas pessoais)
+	Name         string    `gorm:"not null"`
+	TargetAmount float64   `gorm:"not null"`
+	Deadline     time.Time `gorm:"not null"` // Data até a qual o valor deve ser atingido
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+
+	Contributions []GoalContribution `gorm:"foreignKey:GoalID;constraint:OnDelete:CASCADE"`
+}
+
+// GoalContribution representa um aporte (valor positivo) ou uma retirada (valor negativo) de uma
+// meta. O valor aportado deixa de estar disponível e é descontado do saldo do mês em que foi feito.
+type GoalContribution struct {
+	ID        uint    `gorm:"primaryKey"`
+	GoalID    uint    `gorm:"index;not null"` // Chave estrangeira para Goal
+	UserID    uint    `gorm:"not null"`       // Usuário que fez o aporte ou a retirada
+	Amount    float64 `gorm:"not null"`
+	Note      string
+	CreatedAt time.Time `gorm:"index"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Papéis de um membro em um domicílio compartilhado
+const (
+	HouseholdRoleOwner  = "owner"  // Gerencia membros, convites e o próprio domicílio
+	HouseholdRoleEditor = "editor" // Cria, altera e remove despesas do domicílio
+	HouseholdRoleViewer = "viewer" // Apenas consulta despesas e saldo do domicílio
+)
+
+// Household representa um orçamento compartilhado entre vários usuários (ex: um casal)
+type Household struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"not null"`
+	OwnerID   uint   `gorm:"index;not null"` // Usuário que criou o domicílio
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	Members []HouseholdMember `gorm:"foreignKey:HouseholdID"`
+}
+
+// HouseholdMember representa o vínculo de um usuário com um domicílio e seu papel
+type HouseholdMember struct {
+	ID          uint   `gorm:"primaryKey"`
+	HouseholdID uint   `gorm:"uniqueIndex:idx_household_member;not null"`
+	UserID      uint   `gorm:"uniqueIndex:idx_household_member;index;not null"`
+	Role        string `gorm:"not null"` // "owner", "editor" ou "viewer"
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	User        User `json:"-"`
+}
+
+// HouseholdInvitation representa um convite por e-mail para participar de um domicílio
+type HouseholdInvitation struct {
+	ID          uint      `gorm:"primaryKey"`
+	HouseholdID uint      `gorm:"index;not null"`
+	Email       string    `gorm:"index;not null"`
+	Role        string    `gorm:"not null"` // Papel concedido ao aceitar o convite
+	InvitedByID uint      `gorm:"not null"`
+	ExpiresAt   time.Time `gorm:"not null"`
+	AcceptedAt  *time.Time
+	CreatedAt   time.Time
+	Household   Household `json:"-"`
+}
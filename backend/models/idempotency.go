@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// IdempotencyKey guarda a resposta de uma requisição de escrita enviada com o cabeçalho
+// Idempotency-Key, para que novas tentativas com a mesma chave recebam a mesma resposta
+type IdempotencyKey struct {
+	ID           uint   `gorm:"primaryKey"`
+	UserID       uint   `gorm:"uniqueIndex:idx_idempotency_user_key;not null"`
+	Key          string `gorm:"uniqueIndex:idx_idempotency_user_key;not null"`
+	Fingerprint  string `gorm:"not null"` // Hash do método, caminho e corpo da requisição original
+	StatusCode   int    `gorm:"not null"` // 0 enquanto a requisição original está em processamento
+	ContentType  string
+	ResponseBody []byte
+	ExpiresAt    time.Time `gorm:"index;not null"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
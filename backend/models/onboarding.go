@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Etapas do onboarding, na ordem em que são apresentadas ao usuário
+const (
+	OnboardingStepProfile       = "profile"
+	OnboardingStepIncome        = "income"
+	OnboardingStepFixedExpenses = "fixed_expenses"
+	OnboardingStepCategories    = "categories"
+	OnboardingStepFirstBudget   = "first_budget"
+)
+
+// OnboardingState guarda o progresso do usuário no onboarding, permitindo retomá-lo depois
+type OnboardingState struct {
+	ID               uint   `gorm:"primaryKey"`
+	UserID           uint   `gorm:"uniqueIndex;not null"`
+	HouseholdProfile string // Perfil do domicílio: "solo", "casal", "familia" ou "compartilhado"
+	CurrentStep      string `gorm:"not null"` // Etapa atual; vazia quando o onboarding foi concluído
+	CompletedSteps   string // Etapas concluídas, separadas por vírgula
+	SkippedSteps     string // Etapas puladas, separadas por vírgula
+	CompletedAt      *time.Time
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Tipos de transação recorrente
+const (
+	RecurringKindIncome  = "income"
+	RecurringKindExpense = "expense"
+)
+
+// ChangeEntityRecurring identifica as transações recorrentes nas notificações de alteração do saldo.
+// Transações recorrentes não fazem parte do feed de sincronização (SyncEntity*).
+const ChangeEntityRecurring = "recurring_transaction"
+
+// Periodicidades das transações recorrentes
+const (
+	CadenceWeekly  = "weekly"
+	CadenceMonthly = "monthly"
+	CadenceYearly  = "yearly"
+)
+
+// RecurringTransaction representa uma entrada ou saída que se repete com a periodicidade informada,
+// além da renda mensal e das despesas fixas (ex: IPVA todo janeiro, bônus anual, diarista semanal)
+type RecurringTransaction struct {
+	ID          uint       `gorm:"primaryKey"`
+	UserID      uint       `gorm:"index;not null"` // Usuário que cadastrou a transação
+	HouseholdID *uint      `gorm:"index"`          // Domicílio dono da transação (nil para transações pessoais)
+	Description string     `gorm:"not null"`
+	Kind        string     `gorm:"not null"` // "income" ou "expense"
+	Amount      float64    `gorm:"not null"` // Valor de cada ocorrência, sempre positivo
+	Cadence     string     `gorm:"not null"` // "weekly", "monthly" ou "yearly"
+	StartDate   time.Time  `gorm:"not null"` // Primeira ocorrência; as seguintes caem no mesmo dia da semana ou do mês
+	EndDate     *time.Time // Última data possível (inclusive); nil se não tiver fim
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
@@ -8,8 +8,8 @@ import (
 
 // User representa o modelo de usuário no banco de dados
 type User struct {
-	ID        uint           `gorm:"primaryKey"`
-	Email     string         `gorm:"uniqueIndex;not null"`
+	ID        uint   `gorm:"primaryKey"`
+	Email     string `gorm:"uniqueIndex;not null"`
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt gorm.DeletedAt `gorm:"index"`
@@ -30,32 +30,57 @@ type AuthCode struct {
 
 // Income representa a renda mensal do usuário
 type Income struct {
-	ID           uint    `gorm:"primaryKey"`
-	UserID       uint    `gorm:"index;not null"` // Chave estrangeira para User
+	ID            uint    `gorm:"primaryKey"`
+	UserID        uint    `gorm:"index;not null"` // Chave estrangeira para User
 	MonthlyIncome float64 `gorm:"not null"`
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	PayDay        int     `gorm:"not null;default:0"` // Dia do pagamento no mês (1-31); 0 se não informado
+	Version       uint    `gorm:"not null;default:1"` // Incrementada a cada alteração (controle de concorrência otimista)
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
 }
 
 // FixedExpense representa uma despesa fixa mensal do usuário
 type FixedExpense struct {
-	ID        uint    `gorm:"primaryKey"`
-	UserID    uint    `gorm:"index;not null"` // Chave estrangeira para User
-	Name      string  `gorm:"not null"`
-	Value     float64 `gorm:"not null"`
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID          uint    `gorm:"primaryKey"`
+	UserID      uint    `gorm:"index;not null"` // Chave estrangeira para User
+	HouseholdID *uint   `gorm:"index"`          // Domicílio dono da despesa (nil para despesas pessoais)
+	Name        string  `gorm:"not null"`
+	Value       float64 `gorm:"not null"`
+	DueDay      int     `gorm:"not null;default:0"`    // Dia de vencimento no mês (1-31); 0 se não informado
+	Category    string  `gorm:"not null;default:''"`   // Categoria opcional (ex: "moradia")
+	Active      bool    `gorm:"not null;default:true"` // Despesas inativas não entram no saldo
+	Version     uint    `gorm:"not null;default:1"`    // Incrementada a cada alteração (controle de concorrência otimista)
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
 }
 
 // VariableExpense representa uma despesa variável do usuário
 type VariableExpense struct {
-	ID        uint      `gorm:"primaryKey"`
-	UserID    uint      `gorm:"index;not null"` // Chave estrangeira para User
-	Value     float64   `gorm:"not null"`
-	Category  string    `gorm:"not null;index"` // Nome da categoria (simplificado por enquanto)
+	ID          uint    `gorm:"primaryKey"`
+	UserID      uint    `gorm:"index;uniqueIndex:idx_variable_expense_client_ref;not null"` // Usuário que registrou a despesa
+	HouseholdID *uint   `gorm:"index"`                                                      // Domicílio dono da despesa (nil para despesas pessoais)
+	Value       float64 `gorm:"not null"`
+	Category    string  `gorm:"not null;index"` // Nome da categoria (simplificado por enquanto)
 	Description string
-	Date      time.Time `gorm:"not null;index"`
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	User      User // Relacionamento (opcional, mas útil para GORM)
+	Date        time.Time `gorm:"not null;index"`
+	Version     uint      `gorm:"not null;default:1"`                          // Incrementada a cada alteração (controle de concorrência otimista)
+	ClientRef   *string   `gorm:"uniqueIndex:idx_variable_expense_client_ref"` // ID gerado pelo cliente (ex: app offline), evita duplicatas
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"` // Despesas na lixeira; removidas definitivamente após 30 dias
+	User        User           `json:"-"`     // Relacionamento (opcional, mas útil para GORM); não serializado
+
+	Splits []ExpenseSplit `gorm:"foreignKey:VariableExpenseID;constraint:OnDelete:CASCADE"` // Linhas de divisão por categoria (opcional)
+}
+
+// ExpenseSplit representa uma linha de uma despesa variável dividida entre várias categorias.
+// Quando uma despesa possui linhas, a soma dos valores das linhas é igual ao valor da despesa.
+type ExpenseSplit struct {
+	ID                uint    `gorm:"primaryKey"`
+	VariableExpenseID uint    `gorm:"index;not null"` // Chave estrangeira para VariableExpense
+	Category          string  `gorm:"not null;index"`
+	Value             float64 `gorm:"not null"`
+	Note              string
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
 }
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Eventos que podem ser assinados por webhooks
+const (
+	WebhookEventExpenseCreated      = "expense.created"               // Despesa variável registrada
+	WebhookEventExpenseDeleted      = "expense.deleted"               // Despesa variável enviada para a lixeira
+	WebhookEventHealthStatusChanged = "balance.health_status_changed" // Saúde financeira mudou (verde, amarelo, vermelho)
+	WebhookEventAlertDayChanged     = "balance.alert_day_changed"     // Dia projetado do alerta amarelo ou vermelho mudou
+)
+
+// Estados de uma entrega de webhook
+const (
+	WebhookDeliveryPending   = "pending"   // Aguardando a primeira tentativa ou uma nova tentativa
+	WebhookDeliverySucceeded = "succeeded" // O destino respondeu 2xx
+	WebhookDeliveryFailed    = "failed"    // Tentativas esgotadas (pode ser reenviada manualmente)
+)
+
+// Webhook é um endereço cadastrado pelo usuário para receber eventos das suas finanças (ex: planilhas
+// ou automação residencial). Com HouseholdID, recebe apenas os eventos do domicílio.
+type Webhook struct {
+	ID          uint   `gorm:"primaryKey"`
+	UserID      uint   `gorm:"index;not null"`
+	HouseholdID *uint  `gorm:"index"`
+	URL         string `gorm:"not null"`
+	Secret      string `gorm:"not null"` // Chave das assinaturas HMAC; exibida ao usuário apenas no cadastro
+	Events      string `gorm:"not null"` // Eventos assinados, separados por vírgula
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// EventList retorna os eventos assinados pelo webhook.
+func (w Webhook) EventList() []string {
+	return strings.Split(w.Events, ",")
+}
+
+// Subscribes informa se o webhook assina o evento.
+func (w Webhook) Subscribes(event string) bool {
+	for _, subscribed := range w.EventList() {
+		if subscribed == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery é uma entrega de evento a um webhook, com o resultado da última tentativa.
+// Forma o histórico de entregas exibido ao usuário.
+type WebhookDelivery struct {
+	ID             uint      `gorm:"primaryKey"`
+	WebhookID      uint      `gorm:"index;not null"`
+	EventID        string    `gorm:"index;not null"` // Mesmo ID em todas as entregas (e reenvios) do evento
+	Event          string    `gorm:"not null"`
+	Payload        []byte    `gorm:"not null"` // Corpo JSON enviado, idêntico em todas as tentativas
+	Status         string    `gorm:"index:idx_webhook_delivery_due,priority:1;not null"`
+	Attempts       int       `gorm:"not null;default:0"`
+	LastStatusCode int       // Status HTTP da última tentativa (0 se não houve resposta)
+	LastError      string    // Motivo da falha da última tentativa
+	NextAttemptAt  time.Time `gorm:"index:idx_webhook_delivery_due,priority:2;not null"`
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// BalanceWatch guarda a última saúde financeira e os dias de alerta observados de um escopo (usuário
+// ou domicílio), para detectar as mudanças notificadas aos webhooks.
+type BalanceWatch struct {
+	ID             uint   `gorm:"primaryKey"`
+	ScopeKey       string `gorm:"uniqueIndex;not null"` // "user:<id>" ou "household:<id>"
+	HealthStatus   string `gorm:"not null"`
+	YellowAlertDay string `gorm:"not null;default:''"`
+	RedAlertDay    string `gorm:"not null;default:''"`
+	UpdatedAt      time.Time
+}
@@ -7,7 +7,7 @@ module.exports = defineConfig({
       '/api': {
         target: 'http://localhost:8080', // URL do seu backend Go
         changeOrigin: true,
-        pathRewrite: { '^/api': '' }, // Remove /api do path antes de enviar para o backend
+        pathRewrite: { '^/api': '/v1' }, // Troca /api pela versão da API usada pelo frontend (/v1)
       },
     },
     port: 8081, // Porta em que o servidor de desenvolvimento do Vue vai rodar